@@ -22,6 +22,8 @@ THE SOFTWARE.
 package cmd
 
 import (
+	"context"
+	"errors"
 	_ "github.com/jedi-knights/ecnl/docs"
 	v1routes "github.com/jedi-knights/ecnl/pkg/routes/v1"
 	"github.com/labstack/echo/v4"
@@ -33,6 +35,8 @@ import (
 	"net/http"
 )
 
+var withScheduler bool
+
 // apiCmd represents the api command
 var apiCmd = &cobra.Command{
 	Use:   "api",
@@ -71,6 +75,16 @@ to quickly create a Cobra application.`,
 			log.Fatalf("Unknown environment: %s expected development|production", env)
 		}
 
+		if withScheduler {
+			if viper.GetString("storage.driver") == "bolt" {
+				log.Fatalf("The scheduler cannot run alongside the API on the bolt store, the API keeps the file locked")
+			}
+
+			e.Logger.Info("Starting scheduler")
+
+			startScheduler(cmd.Context())
+		}
+
 		e.Logger.Info("Starting server")
 
 		// e.Use(middleware.CORS())
@@ -94,10 +108,17 @@ to quickly create a Cobra application.`,
 		e.Pre(middleware.Logger())
 
 		v1 := e.Group("/api/v1")
+		handler := v1routes.NewHandler(repositories(cmd.Context()))
 
 		v1.GET("/health", v1routes.HandleHealthCheck)
 		v1.GET("/version", v1routes.HandleVersion)
-		v1.GET("/rpi/:division", v1routes.HandleGetRPIRankings)
+		v1.GET("/rpi/:division", handler.HandleGetRPIRankings)
+		v1.GET("/rpi/:division/movers", handler.HandleGetRPIMovers)
+		v1.GET("/matches/corrections", handler.HandleGetMatchCorrections)
+		v1.GET("/matches/:id/history", handler.HandleGetMatchHistory)
+		v1.GET("/runs", handler.HandleGetRuns)
+		v1.GET("/runs/:id", handler.HandleGetRun)
+		v1.GET("/teams/:id/rpi-history", handler.HandleGetTeamRPIHistory)
 
 		e.GET("/swagger/*", echoSwagger.WrapHandler)
 
@@ -119,7 +140,21 @@ to quickly create a Cobra application.`,
 		//} else {
 		//	e.Logger.Fatal(e.Start(":8080"))
 		//}
-		e.Logger.Fatal(e.Start(":8080"))
+		go func() {
+			if err := e.Start(":8080"); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				e.Logger.Fatal(err)
+			}
+		}()
+
+		// Stop on an interrupt once the requests in flight are done, the store is closed after the command.
+		<-cmd.Context().Done()
+
+		ctx, cancel := context.WithTimeout(context.Background(), closeTimeout)
+		defer cancel()
+
+		if err := e.Shutdown(ctx); err != nil {
+			e.Logger.Error(err)
+		}
 	},
 }
 
@@ -135,4 +170,6 @@ func init() {
 	// Cobra supports local flags which will only run when this command
 	// is called directly, e.g.:
 	// apiCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
+
+	apiCmd.Flags().BoolVar(&withScheduler, "scheduler", false, "run the scheduled jobs alongside the API")
 }
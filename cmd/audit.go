@@ -0,0 +1,161 @@
+/*
+Copyright © 2023 Omar Crosby <omar.crosby@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/jedi-knights/ecnl/pkg/audit"
+	"github.com/jedi-knights/ecnl/pkg/dal"
+	"github.com/jedi-knights/ecnl/pkg/models"
+	"github.com/spf13/viper"
+	"log"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	auditOutput  string
+	auditSamples int
+)
+
+// auditCmd represents the audit command
+var auditCmd = &cobra.Command{
+	Use:   "audit",
+	Short: "Checks the quality of the synced data",
+	Long: `Inspects the synced clubs, teams and matches and reports matches and teams
+that point at missing records, played matches without a score, game dates that
+cannot be parsed, duplicate match ids and teams named differently across matches.
+
+Every check has a threshold in the audit section of the config file, 0 unless set.
+The command exits with a non-zero code when a check finds more problems than its
+threshold allows, so it can gate RPI generation:
+
+	ecnl audit && ecnl rpigen --age G2009
+
+	audit:
+	  thresholds:
+	    missing-scores: 25
+`,
+	Run: func(cmd *cobra.Command, args []string) {
+		var err error
+
+		if auditOutput != "table" && auditOutput != "json" {
+			log.Fatalf("Unknown output: %s expected table|json", auditOutput)
+		}
+
+		ctx, cancel := context.WithTimeout(cmd.Context(), 5*time.Minute)
+		defer cancel()
+
+		repos := repositories(ctx)
+		auditor := audit.NewAuditor(time.Now(), auditSamples)
+
+		// The records are streamed, the clubs before the teams before the matches.
+		// Removed clubs and teams are still in their collections, so they are not orphans.
+		if err = repos.Clubs.WithRemoved().Each(ctx, dal.ListOptions{}, func(club models.Club) error {
+			auditor.Club(club)
+			return nil
+		}); err != nil {
+			log.Fatal(err)
+		}
+		if err = repos.Teams.WithRemoved().Each(ctx, dal.ListOptions{}, func(team models.Team) error {
+			auditor.Team(team)
+			return nil
+		}); err != nil {
+			log.Fatal(err)
+		}
+		if err = repos.Matches.Each(ctx, dal.ListOptions{}, func(match models.MatchEvent) error {
+			auditor.Match(match)
+			return nil
+		}); err != nil {
+			log.Fatal(err)
+		}
+
+		thresholds := make(map[string]int)
+		for _, check := range audit.Checks {
+			thresholds[check] = viper.GetInt("audit.thresholds." + check)
+		}
+
+		report := auditor.Report(thresholds)
+
+		if auditOutput == "json" {
+			encoder := json.NewEncoder(os.Stdout)
+			encoder.SetIndent("", "  ")
+
+			if err = encoder.Encode(report); err != nil {
+				log.Fatal(err)
+			}
+		} else {
+			printAudit(report)
+		}
+
+		if report.Failed() {
+			os.Exit(1)
+		}
+	},
+}
+
+// printAudit prints the report as a table of checks followed by the findings of every check.
+func printAudit(report *audit.Report) {
+	fmt.Printf("Audited %d clubs, %d teams and %d matches.\n\n", report.Clubs, report.Teams, report.Matches)
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+
+	_, _ = fmt.Fprintln(w, "CHECK\tCOUNT\tTHRESHOLD\tSTATUS")
+	for _, result := range report.Results {
+		status := "ok"
+		if result.Failed {
+			status = "FAILED"
+		}
+
+		_, _ = fmt.Fprintf(w, "%s\t%d\t%d\t%s\n", result.Check, result.Count, result.Threshold, status)
+	}
+
+	_ = w.Flush()
+
+	for _, result := range report.Results {
+		if len(result.Findings) == 0 {
+			continue
+		}
+
+		fmt.Printf("\n%s: %s\n", result.Check, result.Description)
+
+		for _, finding := range result.Findings {
+			fmt.Printf("\t%s: %s\n", finding.Subject, finding.Detail)
+		}
+
+		if result.Count > len(result.Findings) {
+			fmt.Printf("\t... and %d more\n", result.Count-len(result.Findings))
+		}
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(auditCmd)
+
+	auditCmd.Flags().StringVarP(&auditOutput, "output", "o", "table", "The output format, table or json")
+	auditCmd.Flags().IntVarP(&auditSamples, "samples", "s", 10, "The maximum number of findings listed per check, 0 lists them all")
+}
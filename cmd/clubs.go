@@ -24,7 +24,6 @@ package cmd
 import (
 	"fmt"
 	"github.com/jedi-knights/ecnl/pkg/models"
-	"github.com/jedi-knights/ecnl/pkg/services"
 	"github.com/spf13/cobra"
 )
 
@@ -44,9 +43,9 @@ to quickly create a Cobra application.`,
 			clubs []models.Club
 		)
 
-		service := services.NewTGSService()
+		service := newTGSService()
 
-		if clubs, err = service.ClubsByOrganizationId(orgId); err != nil {
+		if clubs, err = service.ClubsByOrganizationId(cmd.Context(), orgId); err != nil {
 			panic(err)
 		}
 
@@ -0,0 +1,89 @@
+/*
+Copyright © 2023 Omar Crosby <omar.crosby@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"fmt"
+	"github.com/jedi-knights/ecnl/pkg"
+	"github.com/jedi-knights/ecnl/pkg/controllers"
+	"github.com/jedi-knights/ecnl/pkg/models"
+	"log"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	correctionsSince   string
+	correctionsLimit   int
+	correctionsMatchId int
+)
+
+// correctionsCmd represents the corrections command
+var correctionsCmd = &cobra.Command{
+	Use:   "corrections",
+	Short: "Lists recent match corrections",
+	Long: `When sync finds that a match it already stored changed upstream, typically
+because a score was reported late or corrected, it records the change in the
+match_history collection. This command lists those changes, the most recent first.
+
+	ecnl corrections --since 72h
+	ecnl corrections --match 9001
+`,
+	Run: func(cmd *cobra.Command, args []string) {
+		var (
+			err         error
+			since       time.Time
+			corrections []models.MatchHistory
+		)
+
+		controller := controllers.NewMatchHistory(repositories(cmd.Context()).MatchHistory)
+
+		if correctionsMatchId != 0 {
+			corrections, err = controller.ByMatchId(cmd.Context(), correctionsMatchId)
+		} else {
+			if since, err = pkg.ParseSince(correctionsSince); err != nil {
+				log.Fatal(err)
+			}
+
+			corrections, err = controller.Recent(cmd.Context(), since, correctionsLimit)
+		}
+
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		fmt.Printf("There are a total of %d corrections.\n", len(corrections))
+
+		for _, correction := range corrections {
+			fmt.Printf("\t%s\n", correction.String())
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(correctionsCmd)
+
+	correctionsCmd.Flags().StringVarP(&correctionsSince, "since", "s", "168h", "only list corrections since a duration, a date or an RFC3339 time")
+	correctionsCmd.Flags().IntVarP(&correctionsLimit, "limit", "l", 50, "the maximum number of corrections, 0 lists all of them")
+	correctionsCmd.Flags().IntVarP(&correctionsMatchId, "match", "m", 0, "only list the corrections of this match")
+}
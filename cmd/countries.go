@@ -24,7 +24,6 @@ package cmd
 import (
 	"fmt"
 	"github.com/jedi-knights/ecnl/pkg/models"
-	"github.com/jedi-knights/ecnl/pkg/services"
 	"github.com/spf13/cobra"
 )
 
@@ -44,7 +43,7 @@ to quickly create a Cobra application.`,
 			countries []models.Country
 		)
 
-		if countries, err = services.NewTGSService().Countries(); err != nil {
+		if countries, err = newTGSService().Countries(cmd.Context()); err != nil {
 			panic(err)
 		}
 
@@ -24,7 +24,6 @@ package cmd
 import (
 	"fmt"
 	"github.com/jedi-knights/ecnl/pkg/models"
-	"github.com/jedi-knights/ecnl/pkg/services"
 	"github.com/spf13/cobra"
 	"os"
 )
@@ -45,7 +44,7 @@ to quickly create a Cobra application.`,
 			events []models.Event
 		)
 
-		service := services.NewTGSService()
+		service := newTGSService()
 
 		if orgId > 0 {
 			if orgName != "" {
@@ -54,17 +53,17 @@ to quickly create a Cobra application.`,
 			}
 
 			fmt.Printf("Getting events for orgId %d ...\n", orgId)
-			if events, err = service.EventsByOrgId(orgId); err != nil {
+			if events, err = service.EventsByOrgId(cmd.Context(), orgId); err != nil {
 				panic(err)
 			}
 		} else if orgName != "" {
 			fmt.Printf("Getting events for orgName '%s' ...\n", orgName)
-			if events, err = service.EventsByOrgName(orgName); err != nil {
+			if events, err = service.EventsByOrgName(cmd.Context(), orgName); err != nil {
 				panic(err)
 			}
 		} else {
 			fmt.Printf("Getting events for all organizations ...\n")
-			if events, err = service.Events(); err != nil {
+			if events, err = service.Events(cmd.Context()); err != nil {
 				panic(err)
 			}
 		}
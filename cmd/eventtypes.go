@@ -24,7 +24,6 @@ package cmd
 import (
 	"fmt"
 	"github.com/jedi-knights/ecnl/pkg/models"
-	"github.com/jedi-knights/ecnl/pkg/services"
 	"github.com/spf13/cobra"
 )
 
@@ -44,7 +43,7 @@ to quickly create a Cobra application.`,
 			eventTypes []models.EventType
 		)
 
-		if eventTypes, err = services.NewTGSService().EventTypes(); err != nil {
+		if eventTypes, err = newTGSService().EventTypes(cmd.Context()); err != nil {
 			panic(err)
 		}
 
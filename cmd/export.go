@@ -0,0 +1,99 @@
+/*
+Copyright © 2023 Omar Crosby <omar.crosby@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"context"
+	"github.com/jedi-knights/ecnl/pkg/snapshot"
+	"log"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var exportPath string
+
+// exportCmd represents the export command
+var exportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Exports the local database to a snapshot archive",
+	Long: `Writes the organizations, clubs, events, teams, matches and rpi_events
+collections to a gzipped tar archive with one NDJSON file per collection and a
+manifest holding the schema version, the document counts and the export time.
+
+A snapshot bootstraps a development database without a full crawl of TGS and
+archives the data at the end of a season:
+
+	ecnl export --out snapshot.tar.gz
+	ecnl import snapshot.tar.gz
+`,
+	Run: func(cmd *cobra.Command, args []string) {
+		var (
+			err      error
+			file     *os.File
+			manifest *snapshot.Manifest
+		)
+
+		ctx, cancel := context.WithTimeout(cmd.Context(), 30*time.Minute)
+		defer cancel()
+
+		if file, err = os.Create(exportPath); err != nil {
+			log.Fatal(err)
+		}
+
+		if manifest, err = snapshot.Export(ctx, file, snapshotCollections(repositories(ctx))); err != nil {
+			_ = file.Close()
+			_ = os.Remove(exportPath)
+			log.Fatal(err)
+		}
+
+		if err = file.Close(); err != nil {
+			log.Fatal(err)
+		}
+
+		logManifest("Exported", exportPath, manifest)
+	},
+}
+
+// logManifest logs the counts of a snapshot.
+func logManifest(action, path string, manifest *snapshot.Manifest) {
+	names := make([]string, 0, len(manifest.Counts))
+	for name := range manifest.Counts {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	log.Printf("%s snapshot '%s' of %s with schema version %d", action, path, manifest.ExportedAt.Format(time.RFC3339), manifest.SchemaVersion)
+
+	for _, name := range names {
+		log.Printf("\t%s: %d", name, manifest.Counts[name])
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(exportCmd)
+
+	exportCmd.Flags().StringVarP(&exportPath, "out", "o", "snapshot.tar.gz", "The path of the snapshot archive")
+}
@@ -0,0 +1,76 @@
+/*
+Copyright © 2023 Omar Crosby <omar.crosby@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"context"
+	"github.com/jedi-knights/ecnl/pkg/snapshot"
+	"log"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// importCmd represents the import command
+var importCmd = &cobra.Command{
+	Use:   "import <snapshot.tar.gz>",
+	Short: "Imports a snapshot archive into the local database",
+	Long: `Restores the collections of a snapshot written by the export command.
+Documents are upserted on their keys, so importing into a database that already
+holds data updates it and importing the same snapshot twice changes nothing.
+A snapshot of a newer schema version is refused. The rpi_events of a version 1
+snapshot, from before RPI snapshots, are not part of any snapshot: they show up
+in the RPI history of their teams but not in the rankings, rpigen --cutoff
+regenerates those.
+`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		var (
+			err      error
+			file     *os.File
+			manifest *snapshot.Manifest
+		)
+
+		ctx, cancel := context.WithTimeout(cmd.Context(), 30*time.Minute)
+		defer cancel()
+
+		if file, err = os.Open(args[0]); err != nil {
+			log.Fatal(err)
+		}
+		defer file.Close()
+
+		if manifest, err = snapshot.Import(ctx, file, snapshotCollections(repositories(ctx))); err != nil {
+			log.Fatal(err)
+		}
+
+		logManifest("Imported", args[0], manifest)
+
+		if manifest.SchemaVersion < 2 {
+			log.Printf("The snapshot has schema version %d, its rpi_events are not part of any RPI snapshot", manifest.SchemaVersion)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(importCmd)
+}
@@ -0,0 +1,95 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"github.com/jedi-knights/ecnl/pkg/dal"
+	"github.com/jedi-knights/ecnl/pkg/models"
+	"github.com/jedi-knights/ecnl/pkg/services"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"log"
+	"strings"
+	"time"
+)
+
+// maxRunErrors caps the errors stored with a run, a failing upstream can produce thousands.
+const maxRunErrors = 100
+
+// tgsCalls counts the requests sent to total global sports by the service created with newTGSService.
+var tgsCalls *services.CallCounter
+
+// runLedger records a run of a command in the runs collection.
+type runLedger struct {
+	dao dal.RunDAOer
+	run models.Run
+}
+
+// startRun records the start of a run of cmd with the flags that were set on the command line.
+// Failing to record the run is logged but does not stop the command.
+func startRun(ctx context.Context, cmd *cobra.Command, args []string) *runLedger {
+	params := make(map[string]string)
+
+	cmd.Flags().Visit(func(flag *pflag.Flag) {
+		params[flag.Name] = flag.Value.String()
+	})
+
+	if len(args) > 0 {
+		params["args"] = strings.Join(args, " ")
+	}
+
+	ledger := &runLedger{
+		dao: repositories(ctx).Runs,
+		run: models.Run{
+			Id:        primitive.NewObjectID().Hex(),
+			Command:   cmd.Name(),
+			Params:    params,
+			Status:    models.RunRunning,
+			StartedAt: time.Now(),
+		},
+	}
+
+	if err := ledger.dao.Index(ctx); err != nil {
+		log.Printf("error indexing the runs collection: %v", err)
+	}
+
+	ledger.save(ctx)
+
+	return ledger
+}
+
+// finish records the outcome of the run, it failed when there are errors.
+func (l *runLedger) finish(ctx context.Context, entities map[string]models.EntityCounts, errs []error) {
+	l.run.FinishedAt = time.Now()
+	l.run.Entities = entities
+	l.run.Status = models.RunSucceeded
+
+	if tgsCalls != nil {
+		l.run.HttpCalls = tgsCalls.Calls()
+	}
+
+	if len(errs) > 0 {
+		l.run.Status = models.RunFailed
+	}
+
+	for i, err := range errs {
+		if i == maxRunErrors {
+			l.run.Errors = append(l.run.Errors, fmt.Sprintf("... and %d more errors", len(errs)-maxRunErrors))
+			break
+		}
+
+		l.run.Errors = append(l.run.Errors, err.Error())
+	}
+
+	l.save(ctx)
+
+	log.Printf("Recorded run %s", l.run.Id)
+}
+
+// save records the run even when ctx was cancelled, an interrupted command is recorded as well.
+func (l *runLedger) save(ctx context.Context) {
+	if err := l.dao.Save(context.WithoutCancel(ctx), l.run); err != nil {
+		log.Printf("error recording run %s: %v", l.run.Id, err)
+	}
+}
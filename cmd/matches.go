@@ -0,0 +1,89 @@
+/*
+Copyright © 2023 Omar Crosby <omar.crosby@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"fmt"
+	"github.com/jedi-knights/ecnl/pkg/controllers"
+	"github.com/jedi-knights/ecnl/pkg/dal"
+	"github.com/jedi-knights/ecnl/pkg/models"
+	"log"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	matchesDivision string
+	matchesFlight   string
+	matchesSort     []string
+	matchesOffset   int
+	matchesLimit    int
+)
+
+// matchesCmd represents the matches command
+var matchesCmd = &cobra.Command{
+	Use:   "matches",
+	Short: "Lists the stored matches a page at a time",
+	Long: `Lists the matches sync stored, ordered by game date unless --sort names the
+fields to order by. A leading - orders a field descending.
+
+	ecnl matches --division G2009 --flight ECNL --limit 20
+	ecnl matches --division G2009 --sort -gamedate --offset 20
+`,
+	Run: func(cmd *cobra.Command, args []string) {
+		var (
+			err  error
+			page dal.Page[models.MatchEvent]
+		)
+
+		controller := controllers.NewMatches(repositories(cmd.Context()).Matches)
+
+		if page, err = controller.List(cmd.Context(), matchesDivision, matchesFlight, matchesSort, matchesOffset, matchesLimit); err != nil {
+			log.Fatal(err)
+		}
+
+		if len(page.Items) == 0 {
+			fmt.Printf("There are no matches past %d of a total of %d matches.\n", page.Offset, page.Total)
+			return
+		}
+
+		fmt.Printf("Matches %d to %d of a total of %d.\n", page.Offset+1, page.Offset+len(page.Items), page.Total)
+
+		for _, match := range page.Items {
+			fmt.Printf("\t%d %s (%s %s) %d-%d\n", match.MatchId, match.String(), match.Flight, match.Division, match.HomeTeamScore, match.AwayTeamScore)
+		}
+
+		if page.HasMore() {
+			fmt.Printf("Use --offset %d for the next page.\n", page.Offset+len(page.Items))
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(matchesCmd)
+
+	matchesCmd.Flags().StringVarP(&matchesDivision, "division", "d", "", "only list the matches of this division (e.g. G2009)")
+	matchesCmd.Flags().StringVarP(&matchesFlight, "flight", "f", "", "only list the matches of this flight (e.g. ECNL)")
+	matchesCmd.Flags().StringSliceVarP(&matchesSort, "sort", "s", nil, "the fields to order by, a leading - orders descending")
+	matchesCmd.Flags().IntVarP(&matchesOffset, "offset", "o", 0, "the number of matches to skip")
+	matchesCmd.Flags().IntVarP(&matchesLimit, "limit", "l", 50, "the maximum number of matches, 0 lists all of them")
+}
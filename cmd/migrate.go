@@ -0,0 +1,164 @@
+/*
+Copyright © 2023 Omar Crosby <omar.crosby@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"github.com/jedi-knights/ecnl/pkg/migrations"
+	"github.com/spf13/viper"
+	"log"
+	"os"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	migrateTo    int
+	migrateSteps int
+)
+
+// migrateCmd represents the migrate command
+var migrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "Migrates the schema of the mongo database",
+	Long: `Applies and reverts the versioned migrations of the mongo database, e.g. renamed
+fields and new indexes. The applied migrations are recorded in the
+schema_migrations collection.
+
+	ecnl migrate status
+	ecnl migrate up
+	ecnl migrate down --steps 1
+`,
+}
+
+// migrateUpCmd represents the migrate up command
+var migrateUpCmd = &cobra.Command{
+	Use:   "up",
+	Short: "Applies the pending migrations",
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx := cmd.Context()
+
+		applied, err := migrator(ctx).Up(ctx, migrateTo)
+
+		for _, migration := range applied {
+			fmt.Printf("Applied %s\n", migration)
+		}
+
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		if len(applied) == 0 {
+			fmt.Println("The database is up to date.")
+		}
+	},
+}
+
+// migrateDownCmd represents the migrate down command
+var migrateDownCmd = &cobra.Command{
+	Use:   "down",
+	Short: "Reverts the most recent migrations",
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx := cmd.Context()
+
+		reverted, err := migrator(ctx).Down(ctx, migrateSteps)
+
+		for _, migration := range reverted {
+			fmt.Printf("Reverted %s\n", migration)
+		}
+
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		if len(reverted) == 0 {
+			fmt.Println("There are no migrations to revert.")
+		}
+	},
+}
+
+// migrateStatusCmd represents the migrate status command
+var migrateStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Lists the migrations and whether they are applied",
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx := cmd.Context()
+
+		statuses, err := migrator(ctx).Status(ctx)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+
+		_, _ = fmt.Fprintln(w, "VERSION\tNAME\tAPPLIED")
+		for _, status := range statuses {
+			applied := "pending"
+
+			switch {
+			case status.Unknown:
+				applied = status.AppliedAt.Format("2006-01-02 15:04:05") + " (unknown to this version)"
+			case status.Applied():
+				applied = status.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+
+			_, _ = fmt.Fprintf(w, "%d\t%s\t%s\n", status.Version, status.Name, applied)
+		}
+
+		_ = w.Flush()
+	},
+}
+
+// migrator creates the migrator of the mongo database, migrations do not apply to the other stores.
+func migrator(ctx context.Context) *migrations.Migrator {
+	if driver := viper.GetString("storage.driver"); driver != "mongo" {
+		log.Fatalf("Migrations apply to the mongo store only, not %s", driver)
+	}
+
+	// Connecting through the repositories closes the client when the command finishes.
+	repositories(ctx)
+	db := mongoStore.Database()
+
+	ledger := migrations.NewMongoLedger(db.Collection(migrations.Collection))
+	if err := ledger.Index(ctx); err != nil {
+		log.Fatal(err)
+	}
+
+	m, err := migrations.NewMigrator(db, ledger, migrations.All)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	return m
+}
+
+func init() {
+	rootCmd.AddCommand(migrateCmd)
+	migrateCmd.AddCommand(migrateUpCmd)
+	migrateCmd.AddCommand(migrateDownCmd)
+	migrateCmd.AddCommand(migrateStatusCmd)
+
+	migrateUpCmd.Flags().IntVar(&migrateTo, "to", 0, "Only apply the migrations up to this version, 0 applies them all")
+	migrateDownCmd.Flags().IntVarP(&migrateSteps, "steps", "n", 1, "The number of migrations to revert")
+}
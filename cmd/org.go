@@ -24,7 +24,6 @@ package cmd
 import (
 	"fmt"
 	"github.com/jedi-knights/ecnl/pkg/models"
-	"github.com/jedi-knights/ecnl/pkg/services"
 	"github.com/spf13/cobra"
 )
 
@@ -49,7 +48,7 @@ to quickly create a Cobra application.`,
 
 		if id > 0 {
 			// Get the organization by Id and display it
-			if org, err = services.NewTGSService().OrganizationById(id); err != nil {
+			if org, err = newTGSService().OrganizationById(cmd.Context(), id); err != nil {
 				panic(err)
 			}
 
@@ -57,7 +56,7 @@ to quickly create a Cobra application.`,
 		} else {
 			if name != "" {
 				// Get the organization by name and display it
-				if org, err = services.NewTGSService().OrganizationByName(name); err != nil {
+				if org, err = newTGSService().OrganizationByName(cmd.Context(), name); err != nil {
 					panic(err)
 				}
 
@@ -24,7 +24,6 @@ package cmd
 import (
 	"fmt"
 	"github.com/jedi-knights/ecnl/pkg/models"
-	"github.com/jedi-knights/ecnl/pkg/services"
 	"github.com/spf13/cobra"
 )
 
@@ -44,9 +43,9 @@ to quickly create a Cobra application.`,
 			divisions []models.OrganiationDivision
 		)
 
-		service := services.NewTGSService()
+		service := newTGSService()
 
-		if divisions, err = service.OrganizationDivisionsByOrgId(orgId, eventId); err != nil {
+		if divisions, err = service.OrganizationDivisionsByOrgId(cmd.Context(), orgId, eventId); err != nil {
 			panic(err)
 		}
 
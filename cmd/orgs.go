@@ -24,7 +24,6 @@ package cmd
 import (
 	"fmt"
 	"github.com/jedi-knights/ecnl/pkg/models"
-	"github.com/jedi-knights/ecnl/pkg/services"
 
 	"github.com/spf13/cobra"
 )
@@ -47,9 +46,9 @@ to quickly create a Cobra application.`,
 			organizations []models.Organization
 		)
 
-		service := services.NewTGSService()
+		service := newTGSService()
 
-		if organizations, err = service.Organizations(ecnlOnly); err != nil {
+		if organizations, err = service.Organizations(cmd.Context(), ecnlOnly); err != nil {
 			panic(err)
 		}
 
@@ -22,9 +22,13 @@ THE SOFTWARE.
 package cmd
 
 import (
+	"context"
 	"log"
 	"os"
+	"os/signal"
+	"syscall"
 
+	"github.com/jedi-knights/ecnl/pkg/dal/bolt"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
@@ -51,6 +55,9 @@ to quickly create a Cobra application.`,
 	// Uncomment the following line if your bare application
 	// has an action associated with it:
 	// Run: func(cmd *cobra.Command, args []string) { },
+	PersistentPostRun: func(cmd *cobra.Command, args []string) {
+		closeRepositories()
+	},
 }
 
 // Execute adds all child commands to the root command and sets flags appropriately.
@@ -58,7 +65,12 @@ to quickly create a Cobra application.`,
 func Execute() {
 	var err error
 
-	if err = rootCmd.Execute(); err != nil {
+	// Cancel in flight requests when the process is interrupted.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if err = rootCmd.ExecuteContext(ctx); err != nil {
+		stop()
 		os.Exit(1)
 	}
 }
@@ -66,11 +78,20 @@ func Execute() {
 func init() {
 	cobra.OnInitialize(initConfig)
 
+	viper.SetDefault("storage.path", bolt.DefaultPath)
+
 	// Here you will define your flags and configuration settings.
 	// Cobra supports persistent flags, which, if defined here,
 	// will be global for your application.
 
 	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is $HOME/.ecnl.yaml)")
+	rootCmd.PersistentFlags().StringVar(&recordPath, "record", "", "record TGS responses to the given cassette file")
+	rootCmd.PersistentFlags().StringVar(&replayPath, "replay", "", "replay TGS responses from the given cassette file")
+	rootCmd.MarkFlagsMutuallyExclusive("record", "replay")
+	rootCmd.PersistentFlags().String("store", "mongo", "the storage driver, mongo, bolt or memory")
+	rootCmd.PersistentFlags().StringVar(&seedPath, "seed", "", "import a snapshot into the memory store on start")
+
+	_ = viper.BindPFlag("storage.driver", rootCmd.PersistentFlags().Lookup("store"))
 
 	// Cobra also supports local flags, which will only run
 	// when this action is called directly.
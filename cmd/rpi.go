@@ -23,11 +23,19 @@ package cmd
 
 import (
 	"fmt"
+	"github.com/jedi-knights/ecnl/pkg"
 	"github.com/jedi-knights/ecnl/pkg/controllers"
 	"github.com/jedi-knights/ecnl/pkg/models"
 	"github.com/spf13/cobra"
 	"log"
 	"os"
+	"text/tabwriter"
+	"time"
+)
+
+var (
+	rpiDiffFrom string
+	rpiDiffTo   string
 )
 
 // rpiCmd represents the rpi command
@@ -47,9 +55,10 @@ to quickly create a Cobra application.`,
 			data []models.RPIRankingData
 		)
 
-		ctrl = controllers.NewRPI()
+		repos := repositories(cmd.Context())
+		ctrl = controllers.NewRPI(repos.Matches, repos.RPISnapshots, repos.RPIEvents)
 
-		if data, err = ctrl.GenerateRankings(ageGroup); err != nil {
+		if data, err = ctrl.GenerateRankings(cmd.Context(), ageGroup); err != nil {
 			log.Printf("Error generating rankings: %s\n", err)
 			os.Exit(1)
 		}
@@ -61,8 +70,67 @@ to quickly create a Cobra application.`,
 	},
 }
 
+// rpiDiffCmd represents the rpi diff command
+var rpiDiffCmd = &cobra.Command{
+	Use:   "diff",
+	Short: "Compares the stored RPI rankings of an age group at two points in time",
+	Long: `Compares the RPI snapshot of the age group that was current at --from with the one
+current at --to, listing how far each team moved along with the teams that entered
+and dropped out of the rankings.
+
+Without --to the latest snapshot is compared, without --from the snapshot before it.
+
+	ecnl rpi diff --age G2009
+	ecnl rpi diff --age G2009 --from 2023-10-01 --to 2023-11-01
+`,
+	Run: func(cmd *cobra.Command, args []string) {
+		var (
+			err      error
+			from, to time.Time
+			movers   *models.RPIMovers
+		)
+
+		if from, err = pkg.ParseAsOf(rpiDiffFrom); err != nil {
+			log.Fatal(err)
+		}
+
+		if to, err = pkg.ParseAsOf(rpiDiffTo); err != nil {
+			log.Fatal(err)
+		}
+
+		repos := repositories(cmd.Context())
+		ctrl := controllers.NewRPI(repos.Matches, repos.RPISnapshots, repos.RPIEvents)
+
+		if movers, err = ctrl.Movers(cmd.Context(), ageGroup, from, to); err != nil {
+			log.Fatalf("Error comparing rankings: %s\n", err)
+		}
+
+		fmt.Printf("RPI movers for %s from %s (%s) to %s (%s)\n", ageGroup,
+			movers.From.Id, movers.From.MatchCutoff.Format(time.DateOnly), movers.To.Id, movers.To.MatchCutoff.Format(time.DateOnly))
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+
+		_, _ = fmt.Fprintln(w, "RANK\tFROM\tMOVE\tTEAM\tRPI\tCHANGE")
+
+		for _, move := range movers.Moves {
+			_, _ = fmt.Fprintf(w, "%d\t%d\t%+d\t%s\t%f\t%+f\n", move.ToRanking, move.FromRanking, move.RankDelta(), move.TeamName, move.ToRPI, move.RPIDelta())
+		}
+
+		for _, move := range movers.Entries {
+			_, _ = fmt.Fprintf(w, "%d\t-\tnew\t%s\t%f\t-\n", move.ToRanking, move.TeamName, move.ToRPI)
+		}
+
+		for _, move := range movers.DropOuts {
+			_, _ = fmt.Fprintf(w, "-\t%d\tout\t%s\t%f\t-\n", move.FromRanking, move.TeamName, move.FromRPI)
+		}
+
+		_ = w.Flush()
+	},
+}
+
 func init() {
 	rootCmd.AddCommand(rpiCmd)
+	rpiCmd.AddCommand(rpiDiffCmd)
 
 	// Here you will define your flags and configuration settings.
 
@@ -76,4 +144,7 @@ func init() {
 
 	rpiCmd.PersistentFlags().StringVarP(&ageGroup, "age", "a", "", "Age group (e.g. G2009)")
 	_ = rpiCmd.MarkPersistentFlagRequired("age")
+
+	rpiDiffCmd.Flags().StringVarP(&rpiDiffFrom, "from", "f", "", "Compare from the snapshot current then, a duration before now (e.g. 168h), a date or an RFC3339 time (default the snapshot before --to)")
+	rpiDiffCmd.Flags().StringVarP(&rpiDiffTo, "to", "t", "", "Compare to the snapshot current then, a duration before now (e.g. 72h), a date or an RFC3339 time (default the latest)")
 }
@@ -6,14 +6,16 @@ package cmd
 import (
 	"context"
 	"fmt"
+	"github.com/jedi-knights/ecnl/pkg"
 	"github.com/jedi-knights/ecnl/pkg/controllers"
-	"github.com/jedi-knights/ecnl/pkg/dal"
 	"github.com/jedi-knights/ecnl/pkg/models"
 	"github.com/spf13/cobra"
 	"log"
 	"time"
 )
 
+var rpigenCutoff string
+
 // rpigenCmd represents the rpigen command
 var rpigenCmd = &cobra.Command{
 	Use:   "rpigen",
@@ -26,56 +28,62 @@ end up polluting the database with record after record when all it needs to do i
 read.
 
 This means from time to time this command will have to be run in order to get updated
-data into the database.`,
+data into the database.
+
+Each run stores the rankings as a snapshot of the age group. Running it again before the
+matches change reuses the last snapshot, and --cutoff ranks the matches played by an
+earlier date to fill in the rankings as they stood then. A snapshot is only reused for
+a cutoff when it was current at that cutoff.`,
 	Run: func(cmd *cobra.Command, args []string) {
 		var (
-			err  error
-			ctrl *controllers.RPI
-			data []models.RPIRankingData
-			age  string
+			err      error
+			created  bool
+			age      string
+			cutoff   time.Time
+			snapshot *models.RPISnapshot
+			rows     []models.RPIEvent
 		)
 
-		ctrl = controllers.NewRPI()
-
 		if age, err = cmd.Flags().GetString("age"); err != nil {
 			log.Fatalf("Unable to retrieve the age parameter: %v\n", err)
 		}
 
-		if data, err = ctrl.GenerateRankings(age); err != nil {
-			log.Fatalf("Error generating rankings: %s\n", err)
+		if cutoff, err = pkg.ParseAsOf(rpigenCutoff); err != nil {
+			log.Fatal(err)
 		}
 
-		ctx, _ := context.WithTimeout(context.Background(), 1*time.Minute)
+		ctx, cancel := context.WithTimeout(cmd.Context(), 1*time.Minute)
+		defer cancel()
+
+		repos := repositories(ctx)
+		ctrl := controllers.NewRPI(repos.Matches, repos.RPISnapshots, repos.RPIEvents)
 
-		// get the client
-		client := dal.MustGetClient(ctx)
+		ledger := startRun(ctx, cmd, args)
 
-		// get the database
-		database := client.Database("ecnl")
+		if snapshot, created, err = ctrl.Generate(ctx, age, cutoff, ledger.run.Id); err != nil {
+			ledger.finish(ctx, nil, []error{err})
+			log.Fatalf("Error generating rankings: %s\n", err)
+		}
 
-		rpiEventsCollection := database.Collection("rpi_events")
-		rpiEventDAO := dal.NewRPIEventDAO(ctx, rpiEventsCollection)
+		if rows, err = repos.RPIEvents.GetBySnapshotId(ctx, snapshot.Id); err != nil {
+			ledger.finish(ctx, nil, []error{err})
+			log.Fatalf("Error reading rankings: %s\n", err)
+		}
 
-		currentTime := time.Now()
+		counts := models.EntityCounts{Fetched: len(rows), Unchanged: len(rows)}
 
-		fmt.Printf("RPI Rankings for %s %s\n", orgName, ageGroup)
-		for _, d := range data {
-			// Attempt to append the RPI ranking
-			var event = models.RPIEvent{
-				Timestamp: currentTime,
-				TeamId:    d.TeamId,
-				TeamName:  d.TeamName,
-				Ranking:   d.Ranking,
-				Value:     d.RPI,
-			}
+		if created {
+			counts = models.EntityCounts{Fetched: len(rows), Inserted: len(rows)}
+			fmt.Printf("Saved RPI snapshot %s\n", snapshot)
+		} else {
+			fmt.Printf("The matches are unchanged since RPI snapshot %s\n", snapshot)
+		}
 
-			if err = rpiEventDAO.Create(event); err != nil {
-				log.Println(err)
-			} else {
-				formattedTime := currentTime.Format("January 2, 2006 3:04 PM MST")
-				fmt.Println("Saved " + formattedTime + " " + d.String())
-			}
+		for _, row := range rows {
+			fmt.Printf("#%d: '%s' (%f)\n", row.Ranking, row.TeamName, row.Value)
 		}
+
+		ledger.finish(ctx, map[string]models.EntityCounts{"rpi": counts}, nil)
 	},
 }
 
@@ -93,5 +101,6 @@ func init() {
 	// rpigenCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
 
 	rpigenCmd.PersistentFlags().StringP("age", "a", "", "Age group (e.g. G2009)")
+	rpigenCmd.Flags().StringVarP(&rpigenCutoff, "cutoff", "c", "", "Only rank the matches played by then, a duration before now (e.g. 72h), a date or an RFC3339 time (default now)")
 	_ = rpigenCmd.MarkPersistentFlagRequired("age")
 }
@@ -0,0 +1,105 @@
+/*
+Copyright © 2023 Omar Crosby <omar.crosby@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/jedi-knights/ecnl/pkg/controllers"
+	"github.com/jedi-knights/ecnl/pkg/models"
+	"log"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	runsLimit   int
+	runsCommand string
+)
+
+// runsCmd represents the runs command
+var runsCmd = &cobra.Command{
+	Use:   "runs",
+	Short: "Inspects the ledger of sync and rpigen runs",
+	Long: `Every sync and rpigen records a run in the runs collection with its parameters,
+how long it took, what it fetched and wrote for each entity, the number of
+calls it made to the TGS backend and the errors it ran into.
+
+	ecnl runs list --command sync
+	ecnl runs show <id>
+`,
+}
+
+// runsListCmd represents the runs list command
+var runsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "Lists the most recent runs",
+	Run: func(cmd *cobra.Command, args []string) {
+		var (
+			err  error
+			runs []models.Run
+		)
+
+		if runs, err = controllers.NewRuns(repositories(cmd.Context()).Runs).Recent(cmd.Context(), runsCommand, runsLimit); err != nil {
+			log.Fatal(err)
+		}
+
+		fmt.Printf("There are a total of %d runs.\n", len(runs))
+
+		for _, run := range runs {
+			fmt.Printf("\t%s\n", run.String())
+		}
+	},
+}
+
+// runsShowCmd represents the runs show command
+var runsShowCmd = &cobra.Command{
+	Use:   "show <id>",
+	Short: "Shows the details of a run",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		var (
+			err  error
+			run  *models.Run
+			data []byte
+		)
+
+		if run, err = controllers.NewRuns(repositories(cmd.Context()).Runs).ById(cmd.Context(), args[0]); err != nil {
+			log.Fatal(err)
+		}
+
+		if data, err = json.MarshalIndent(run, "", "  "); err != nil {
+			log.Fatal(err)
+		}
+
+		fmt.Println(string(data))
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(runsCmd)
+	runsCmd.AddCommand(runsListCmd)
+	runsCmd.AddCommand(runsShowCmd)
+
+	runsListCmd.Flags().IntVarP(&runsLimit, "limit", "l", 20, "The maximum number of runs")
+	runsListCmd.Flags().StringVarP(&runsCommand, "command", "c", "", "Only list the runs of this command (e.g. sync)")
+}
@@ -0,0 +1,287 @@
+/*
+Copyright © 2023 Omar Crosby <omar.crosby@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"github.com/jedi-knights/ecnl/pkg/dal"
+	"github.com/jedi-knights/ecnl/pkg/dal/memory"
+	"github.com/jedi-knights/ecnl/pkg/models"
+	"github.com/jedi-knights/ecnl/pkg/scheduler"
+	"github.com/spf13/viper"
+	"log"
+	"os"
+	"os/exec"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// scheduledJob is a job in the scheduler section of the config file.
+type scheduledJob struct {
+	Name     string
+	Schedule string
+	// Commands are ecnl command lines run one after the other, e.g. "rpigen --age G2009".
+	// Arguments are split the way a shell splits them, so one with a space is quoted, e.g. sync --org "ECNL Girls".
+	Commands []string
+}
+
+// schedulerCmd represents the scheduler command
+var schedulerCmd = &cobra.Command{
+	Use:   "scheduler",
+	Short: "Runs sync and RPI generation on a schedule",
+	Long: `Runs the jobs of the scheduler section of the config file on their cron
+schedules until interrupted. Every job is a list of ecnl command lines, whose
+arguments are quoted as in a shell when they contain spaces:
+
+	scheduler:
+	  jitter: 5m
+	  lease:
+	    ttl: 10m
+	  jobs:
+	    - name: sync
+	      schedule: "0 6 * * 1"
+	      commands:
+	        - sync --since last
+	        - sync --org "ECNL Girls" --only matches
+	    - name: rpigen
+	      schedule: "0 8 * * 1"
+	      commands:
+	        - rpigen --age G2009
+	        - rpigen --age G2010
+
+A random delay of up to the jitter is added to every run. A job holds a lease in
+the leases collection while it runs, so when several schedulers share a database
+only one of them runs it. Use "ecnl scheduler run <job>" to run a job right away
+and "ecnl api --scheduler" to run the scheduler alongside the API.
+
+Every command runs in a child ecnl process on the same store, so the scheduler
+needs a store the processes share and does not run on the memory store.
+`,
+	Run: func(cmd *cobra.Command, args []string) {
+		s, err := newScheduler(cmd.Context())
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		log.Printf("Scheduling %s", strings.Join(s.Names(), ", "))
+
+		s.Run(cmd.Context())
+	},
+}
+
+// schedulerListCmd represents the scheduler list command
+var schedulerListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "Lists the scheduled jobs and the leases held on them",
+	Run: func(cmd *cobra.Command, args []string) {
+		var (
+			err    error
+			jobs   []scheduledJob
+			leases []models.Lease
+		)
+
+		ctx, cancel := context.WithTimeout(cmd.Context(), 1*time.Minute)
+		defer cancel()
+
+		if jobs, err = scheduledJobs(); err != nil {
+			log.Fatal(err)
+		}
+
+		s, err := newScheduler(ctx)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		if leases, err = schedulerLeases(ctx).GetAll(ctx); err != nil {
+			log.Fatal(err)
+		}
+
+		fmt.Printf("There are a total of %d scheduled jobs.\n", len(jobs))
+
+		for _, job := range jobs {
+			next, _ := s.Next(job.Name, time.Now())
+
+			fmt.Printf("\t%s: '%s' next at %s runs %s\n", job.Name, job.Schedule, next.Format(time.RFC3339), strings.Join(job.Commands, "; "))
+		}
+
+		for _, lease := range leases {
+			if lease.ExpiresAt.After(time.Now()) {
+				fmt.Printf("Job '%s' is running on %s, its lease expires at %s\n", lease.Name, lease.Holder, lease.ExpiresAt.Format(time.RFC3339))
+			}
+		}
+	},
+}
+
+// schedulerRunCmd represents the scheduler run command
+var schedulerRunCmd = &cobra.Command{
+	Use:   "run <job>",
+	Short: "Runs a scheduled job right away",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		s, err := newScheduler(cmd.Context())
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		if err = s.RunNow(cmd.Context(), args[0]); err != nil {
+			log.Fatal(err)
+		}
+	},
+}
+
+// newScheduler creates a scheduler for the jobs in the config file, leasing them in the leases collection.
+// Every command of a job runs in a child process, which with the memory store would start from an empty store
+// of its own and throw away what it stored, so the memory store is refused.
+func newScheduler(ctx context.Context) (*scheduler.Scheduler, error) {
+	var (
+		err  error
+		jobs []scheduledJob
+	)
+
+	if viper.GetString("storage.driver") == "memory" {
+		return nil, fmt.Errorf("the scheduler cannot run on the memory store, the jobs run in processes of their own that do not share it")
+	}
+
+	defaults := scheduler.DefaultOptions()
+
+	viper.SetDefault("scheduler.jitter", defaults.Jitter)
+	viper.SetDefault("scheduler.lease.ttl", defaults.LeaseTTL)
+
+	if jobs, err = scheduledJobs(); err != nil {
+		return nil, err
+	}
+
+	leases := schedulerLeases(ctx)
+	if err = leases.Index(ctx); err != nil {
+		return nil, err
+	}
+
+	hostname, _ := os.Hostname()
+
+	opts := scheduler.Options{
+		Jitter:   viper.GetDuration("scheduler.jitter"),
+		LeaseTTL: viper.GetDuration("scheduler.lease.ttl"),
+		Holder:   fmt.Sprintf("%s:%d", hostname, os.Getpid()),
+	}
+
+	schedulerJobs := make([]scheduler.Job, 0, len(jobs))
+	for _, job := range jobs {
+		run, err := runCommands(job.Commands)
+		if err != nil {
+			return nil, fmt.Errorf("error reading the commands of job '%s': %w", job.Name, err)
+		}
+
+		schedulerJobs = append(schedulerJobs, scheduler.Job{Name: job.Name, Schedule: job.Schedule, Run: run})
+	}
+
+	return scheduler.New(schedulerJobs, leases, opts)
+}
+
+// schedulerLeases returns where the jobs are leased. A bolt file is locked by the process that opens it,
+// so with the bolt store the scheduler keeps its leases in memory and leaves the file to the jobs it runs.
+func schedulerLeases(ctx context.Context) dal.LeaseDAOer {
+	if viper.GetString("storage.driver") == "bolt" {
+		return memory.NewLeaseDAO()
+	}
+
+	return repositories(ctx).Leases
+}
+
+// startScheduler runs the scheduler in the background until ctx is done.
+func startScheduler(ctx context.Context) {
+	s, err := newScheduler(ctx)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	go s.Run(ctx)
+}
+
+func scheduledJobs() ([]scheduledJob, error) {
+	var jobs []scheduledJob
+
+	if err := viper.UnmarshalKey("scheduler.jobs", &jobs); err != nil {
+		return nil, fmt.Errorf("error reading the scheduled jobs: %w", err)
+	}
+
+	return jobs, nil
+}
+
+// runCommands runs every command line in a child ecnl process with the same config file and store,
+// so a command that exits on failure does not take the scheduler down with it.
+// Every command runs even when one fails, the job fails when any of them did.
+// The command lines are split into arguments up front, so a malformed one is reported before the job is scheduled.
+func runCommands(commands []string) (func(ctx context.Context) error, error) {
+	lines := make([][]string, 0, len(commands))
+
+	for _, command := range commands {
+		args, err := scheduler.SplitArgs(command)
+		if err != nil {
+			return nil, err
+		}
+
+		lines = append(lines, args)
+	}
+
+	return func(ctx context.Context) error {
+		var failed []string
+
+		executable, err := os.Executable()
+		if err != nil {
+			return err
+		}
+
+		for i, command := range commands {
+			args := slices.Clone(lines[i])
+			if file := viper.ConfigFileUsed(); file != "" {
+				args = append(args, "--config", file)
+			}
+
+			args = append(args, "--store", viper.GetString("storage.driver"))
+
+			child := exec.CommandContext(ctx, executable, args...)
+			child.Stdout = os.Stdout
+			child.Stderr = os.Stderr
+
+			if err = child.Run(); err != nil {
+				log.Printf("'%s' failed: %v", command, err)
+				failed = append(failed, command)
+			}
+		}
+
+		if len(failed) > 0 {
+			return fmt.Errorf("%d of %d commands failed: %s", len(failed), len(commands), strings.Join(failed, "; "))
+		}
+
+		return nil
+	}, nil
+}
+
+func init() {
+	rootCmd.AddCommand(schedulerCmd)
+	schedulerCmd.AddCommand(schedulerListCmd)
+	schedulerCmd.AddCommand(schedulerRunCmd)
+}
@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"github.com/jedi-knights/ecnl/pkg"
+	"github.com/jedi-knights/ecnl/pkg/cache"
+	"github.com/jedi-knights/ecnl/pkg/services"
+	"github.com/spf13/viper"
+	"log"
+	"net/http"
+	"time"
+)
+
+var (
+	recordPath string
+	replayPath string
+)
+
+// newTGSService creates a total global sports service configured from the tgs section of the config file.
+func newTGSService() *services.GlobalService {
+	var (
+		err       error
+		transport http.RoundTripper
+	)
+
+	defaults := services.DefaultTransportOptions()
+
+	viper.SetDefault("tgs.url", pkg.TgsPrefix)
+	viper.SetDefault("tgs.timeout", defaults.Timeout)
+	viper.SetDefault("tgs.retries", defaults.MaxRetries)
+	viper.SetDefault("tgs.backoff.min", defaults.MinBackoff)
+	viper.SetDefault("tgs.backoff.max", defaults.MaxBackoff)
+	viper.SetDefault("tgs.rate.limit", defaults.RateLimit)
+	viper.SetDefault("tgs.rate.burst", defaults.Burst)
+	viper.SetDefault("tgs.cache.size", 4096)
+	viper.SetDefault("tgs.cache.ttl", 1*time.Hour)
+
+	transport = services.NewTransport(http.DefaultTransport, services.TransportOptions{
+		Timeout:    viper.GetDuration("tgs.timeout"),
+		MaxRetries: viper.GetInt("tgs.retries"),
+		MinBackoff: viper.GetDuration("tgs.backoff.min"),
+		MaxBackoff: viper.GetDuration("tgs.backoff.max"),
+		RateLimit:  viper.GetFloat64("tgs.rate.limit"),
+		Burst:      viper.GetInt("tgs.rate.burst"),
+	})
+
+	mode, path := cassetteMode()
+
+	switch mode {
+	case "":
+	case "record":
+		log.Printf("Recording TGS responses to '%s'", path)
+
+		if transport, err = services.NewRecorder(transport, path); err != nil {
+			log.Fatal(err)
+		}
+	case "replay":
+		log.Printf("Replaying TGS responses from '%s'", path)
+
+		if transport, err = services.NewReplayer(path); err != nil {
+			log.Fatal(err)
+		}
+	default:
+		log.Fatalf("Unknown cassette mode: %s expected record|replay", mode)
+	}
+
+	tgsCalls = services.NewCallCounter(transport)
+
+	svc := services.NewTGSServiceWithClient(&http.Client{Transport: tgsCalls})
+	svc.BaseUrl = viper.GetString("tgs.url")
+	svc.Cache = cache.NewMemory(viper.GetInt("tgs.cache.size"), viper.GetDuration("tgs.cache.ttl"))
+
+	return svc
+}
+
+// cassetteMode returns the cassette mode and path, giving the --record and --replay flags precedence over the config file.
+func cassetteMode() (string, string) {
+	if recordPath != "" {
+		return "record", recordPath
+	}
+
+	if replayPath != "" {
+		return "replay", replayPath
+	}
+
+	return viper.GetString("tgs.cassette.mode"), viper.GetString("tgs.cassette.path")
+}
@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"context"
+	"github.com/jedi-knights/ecnl/pkg/dal"
+	"github.com/jedi-knights/ecnl/pkg/models"
+	"github.com/jedi-knights/ecnl/pkg/snapshot"
+)
+
+// eacher streams the documents of a DAO.
+type eacher[T any] interface {
+	Each(ctx context.Context, opts dal.ListOptions, fn func(T) error) error
+}
+
+// everything streams all the documents of dao.
+func everything[T any](dao eacher[T]) func(ctx context.Context, fn func(T) error) error {
+	return func(ctx context.Context, fn func(T) error) error {
+		return dao.Each(ctx, dal.ListOptions{}, fn)
+	}
+}
+
+// snapshotCollections returns the collections in a snapshot, read and written through their DAOs.
+// Removed clubs, events and teams are part of it and matches are restored without recording corrections.
+func snapshotCollections(repos *dal.Repositories) []snapshot.Collection {
+	orgDAO := repos.Organizations
+	clubDAO := repos.Clubs.WithRemoved()
+	eventDAO := repos.Events.WithRemoved()
+	teamDAO := repos.Teams.WithRemoved()
+	matchEventDAO := repos.Matches.WithoutHistory()
+	rpiEventDAO := repos.RPIEvents
+	rpiSnapshotDAO := repos.RPISnapshots
+
+	// The team DAO reads values but syncs pointers.
+	eachTeam := func(ctx context.Context, fn func(*models.Team) error) error {
+		return teamDAO.Each(ctx, dal.ListOptions{}, func(team models.Team) error {
+			return fn(&team)
+		})
+	}
+
+	return []snapshot.Collection{
+		snapshot.NewCollection[models.Organization]("organizations", everything[models.Organization](orgDAO), orgDAO.SyncAll),
+		snapshot.NewCollection[models.Club]("clubs", everything[models.Club](clubDAO), clubDAO.SyncAll),
+		snapshot.NewCollection[models.Event]("events", everything[models.Event](eventDAO), eventDAO.SyncAll),
+		snapshot.NewCollection[*models.Team]("teams", eachTeam, teamDAO.SyncAll),
+		snapshot.NewCollection[models.MatchEvent]("matches", everything[models.MatchEvent](matchEventDAO), matchEventDAO.SyncAll),
+		snapshot.NewCollection[models.RPIEvent]("rpi_events", everything[models.RPIEvent](rpiEventDAO), rpiEventDAO.SyncAll),
+		snapshot.NewCollection[models.RPISnapshot]("rpi_snapshots", everything[models.RPISnapshot](rpiSnapshotDAO), rpiSnapshotDAO.SyncAll),
+	}
+}
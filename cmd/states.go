@@ -24,7 +24,6 @@ package cmd
 import (
 	"fmt"
 	"github.com/jedi-knights/ecnl/pkg/models"
-	"github.com/jedi-knights/ecnl/pkg/services"
 	"github.com/spf13/cobra"
 )
 
@@ -44,7 +43,7 @@ to quickly create a Cobra application.`,
 			states []models.State
 		)
 
-		if states, err = services.NewTGSService().States(); err != nil {
+		if states, err = newTGSService().States(cmd.Context()); err != nil {
 			panic(err)
 		}
 
@@ -0,0 +1,98 @@
+package cmd
+
+import (
+	"context"
+	"github.com/jedi-knights/ecnl/pkg/dal"
+	"github.com/jedi-knights/ecnl/pkg/dal/bolt"
+	"github.com/jedi-knights/ecnl/pkg/dal/memory"
+	"github.com/jedi-knights/ecnl/pkg/snapshot"
+	"github.com/spf13/viper"
+	"log"
+	"os"
+	"sync"
+	"time"
+)
+
+// closeTimeout bounds the wait for the mongo operations in flight on shutdown.
+const closeTimeout = 10 * time.Second
+
+var (
+	seedPath string
+
+	repos      *dal.Repositories
+	reposOnce  sync.Once
+	mongoStore *dal.Store
+	boltDB     *bolt.DB
+)
+
+// repositories returns the repositories of the storage driver in the config, mongo, bolt or memory.
+// They are created on the first call and shared by the rest of the command.
+// The mongo store connects to mongo.uri and uses the database named by mongo.database.
+// The bolt store is the file at storage.path. The memory store starts empty unless --seed names
+// a snapshot to import into it.
+func repositories(ctx context.Context) *dal.Repositories {
+	reposOnce.Do(func() {
+		var err error
+
+		switch driver := viper.GetString("storage.driver"); driver {
+		case "mongo":
+			if mongoStore, err = dal.NewStoreFromConfig(ctx); err != nil {
+				log.Fatal(err)
+			}
+
+			repos = mongoStore.Repositories()
+		case "bolt":
+			if repos, boltDB, err = bolt.NewRepositories(viper.GetString("storage.path")); err != nil {
+				log.Fatal(err)
+			}
+		case "memory":
+			repos = memory.NewRepositories()
+
+			if seedPath != "" {
+				seed(ctx, repos, seedPath)
+			}
+		default:
+			log.Fatalf("Unknown storage driver: %s expected mongo|bolt|memory", driver)
+		}
+	})
+
+	return repos
+}
+
+// closeRepositories disconnects from mongo or releases the bolt file, if the command opened either.
+func closeRepositories() {
+	if mongoStore != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), closeTimeout)
+		defer cancel()
+
+		if err := mongoStore.Close(ctx); err != nil {
+			log.Printf("error closing the store: %v", err)
+		}
+	}
+
+	if boltDB != nil {
+		if err := boltDB.Close(); err != nil {
+			log.Printf("error closing the store: %v", err)
+		}
+	}
+}
+
+// seed imports a snapshot into the repositories.
+func seed(ctx context.Context, repos *dal.Repositories, path string) {
+	var (
+		err      error
+		file     *os.File
+		manifest *snapshot.Manifest
+	)
+
+	if file, err = os.Open(path); err != nil {
+		log.Fatal(err)
+	}
+	defer file.Close()
+
+	if manifest, err = snapshot.Import(ctx, file, snapshotCollections(repos)); err != nil {
+		log.Fatal(err)
+	}
+
+	logManifest("Seeded", path, manifest)
+}
@@ -23,16 +23,26 @@ package cmd
 
 import (
 	"context"
+	"fmt"
+	"github.com/jedi-knights/ecnl/pkg"
+	"github.com/jedi-knights/ecnl/pkg/crawler"
 	"github.com/jedi-knights/ecnl/pkg/dal"
 	"github.com/jedi-knights/ecnl/pkg/models"
 	"github.com/jedi-knights/ecnl/pkg/services"
-	"go.mongodb.org/mongo-driver/mongo"
+	"github.com/spf13/viper"
 	"log"
+	"strings"
 	"time"
 
 	"github.com/spf13/cobra"
 )
 
+var (
+	syncResume bool
+	syncSince  string
+	syncScope  crawler.Scope
+)
+
 // syncCmd represents the sync command
 var syncCmd = &cobra.Command{
 	Use:   "sync",
@@ -40,142 +50,143 @@ var syncCmd = &cobra.Command{
 	Long: `Heavy calculations like RPI generation require a lot of back and forth
 with the ECNL backend.  This command will sync the local database with the ECNL backend
 so that the RPI computation can occur more rapidly.
+
+Independent requests are made in parallel by a bounded pool of workers.
+Failures are collected and reported at the end instead of stopping the sync.
+
+Progress is recorded in the sync_state collection. When a sync does not finish
+--resume continues it without repeating the units it completed. --since only
+stores the teams of events and the matches of clubs that changed since the
+given time, the others are fetched to tell but not written again unless their
+last sync stored only part of them. It accepts a duration (72h), a date
+(2023-10-01), an RFC3339 time or "last" for the start of the last sync.
+
+Clubs, events and teams that are no longer found upstream are kept but marked
+with a removedAt time, clubs without an event for the current season included.
+This only happens after a complete sync, one without errors, skipped units or
+a narrowed scope.
+
+The scope of a sync can be narrowed with --org, --event and --club, which take
+ids or names, --age (e.g. G2009), --ecnl-only and --only=orgs,clubs,events,teams,matches.
+For example, to refresh the matches of ECNL Girls:
+
+	ecnl sync --org "ECNL Girls" --only matches
 `,
 	Run: func(cmd *cobra.Command, args []string) {
 		var (
-			err           error
-			client        *mongo.Client
-			clubs         []models.Club
-			events        []models.Event
-			organizations []models.Organization
+			err    error
+			since  time.Time
+			result *crawler.Result
 		)
 
-		ctx, _ := context.WithTimeout(context.Background(), 1*time.Hour)
-
-		// get the client
-		client = dal.MustGetClient(ctx)
-
-		// get the database
-		database := client.Database("ecnl")
-
-		// create collections
-		orgsCollection := database.Collection("organizations")
-		clubsCollection := database.Collection("clubs")
-		eventsCollection := database.Collection("events")
-		matchesCollection := database.Collection("matches")
-		teamsCollection := database.Collection("teams")
+		ctx, cancel := context.WithTimeout(cmd.Context(), 1*time.Hour)
+		defer cancel()
 
-		// create data access objects
-		orgDAO := dal.NewOrganizationDAO(ctx, orgsCollection)
-		clubDAO := dal.NewClubDAO(ctx, clubsCollection)
-		eventDAO := dal.NewEventDAO(ctx, eventsCollection)
-		matchEventDAO := dal.NewMatchEventDAO(ctx, matchesCollection)
-		teamDAO := dal.NewTeamDAO(ctx, teamsCollection)
+		repos := repositories(ctx)
 
 		// Index Reference
 		// https://www.mongodb.com/docs/drivers/go/current/fundamentals/indexes/
 
 		// index the collections
-		if err = orgDAO.Index(); err != nil {
+		if err = repos.Index(ctx); err != nil {
 			log.Fatal(err)
 		}
-		if err = clubDAO.Index(); err != nil {
-			log.Fatal(err)
-		}
-		if err = teamDAO.Index(); err != nil {
-			log.Fatal(err)
-		}
-		if err = eventDAO.Index(); err != nil {
+
+		if since, err = parseSince(ctx, syncSince, repos.SyncState); err != nil {
 			log.Fatal(err)
 		}
-		if err = teamDAO.Index(); err != nil {
+
+		if err = syncScope.Validate(); err != nil {
 			log.Fatal(err)
 		}
 
 		// create a new total global sports service
-		svc := services.NewTGSService()
-
-		// sync the organizations
-		if organizations, err = svc.Organizations(false); err != nil {
-			log.Fatal(err)
-		}
-		if err = orgDAO.SyncAll(organizations); err != nil {
-			log.Fatal(err)
+		svc := newTGSService()
+
+		store := crawler.Store{
+			Organizations: repos.Organizations,
+			Clubs:         repos.Clubs,
+			Events:        repos.Events,
+			Teams:         repos.Teams,
+			Matches:       repos.Matches,
+			State:         repos.SyncState,
 		}
 
-		// sync the clubs, events, and matches
-		for _, org := range organizations {
-			if clubs, err = svc.ClubsByOrganization(org); err != nil {
-				log.Fatal(err)
-			}
-			if err = clubDAO.SyncAll(clubs); err != nil {
-				log.Fatal(err)
-			}
+		c := crawler.New(svc, store, crawler.Options{
+			Workers: viper.GetInt("sync.workers"),
+			Resume:  syncResume,
+			Since:   since,
+			Scope:   syncScope,
+		})
 
-			if events, err = svc.EventsByOrganization(org); err != nil {
-				log.Fatal(err)
-			}
-			if err = eventDAO.SyncAll(events); err != nil {
-				log.Fatal(err)
-			}
+		ledger := startRun(ctx, cmd, args)
 
-			log.Printf("Done syncing events for organization '%s'", org.Name)
+		start := time.Now()
+		result, err = c.Run(ctx)
 
-			for _, event := range events {
-				var teams []*models.Team
+		errs := result.Errors
+		if err != nil && len(errs) == 0 {
+			errs = []error{err}
+		}
 
-				if teams, err = svc.TeamsByEvent(event); err != nil {
-					log.Fatal(err)
-				}
+		ledger.finish(ctx, result.Entities, errs)
 
-				if err = teamDAO.SyncAll(teams); err != nil {
-					log.Fatal(err)
-				}
-			}
+		for _, entity := range crawler.Entities {
+			counts := result.Count(entity)
+
+			log.Printf("Synced %s: fetched %d, inserted %d, updated %d, left %d unchanged, marked %d removed and skipped %d units",
+				entity, counts.Fetched, counts.Inserted, counts.Updated, counts.Unchanged, counts.Removed, counts.Skipped)
+		}
 
-			log.Printf("Done syncing teams for organization '%s'", org.Name)
+		log.Printf("Sync run %s took %s", result.RunId, time.Since(start).Round(time.Second))
 
-			for _, club := range clubs {
-				var event *models.Event
-				var data []models.MatchEvent
+		if err != nil {
+			for _, e := range errs {
+				log.Println(e)
+			}
 
-				// Some clubs aren't associated with an event.
-				// I think this means they are no longer part of the ECNL, so in this case
-				// it's safe to continue on because they don't have any match data anyway for
-				// the current season.
-				if club.EventId == 0 {
-					continue
-				}
+			log.Fatalf("sync finished with %d errors, run it again with --resume to continue", len(errs))
+		}
+	},
+}
 
-				if event, err = svc.EventById(club.EventId); err != nil {
-					log.Fatal(err)
-				}
+// parseSince parses the value of the --since flag, which in addition to what pkg.ParseSince accepts
+// can be "last" for the start of the last sync.
+func parseSince(ctx context.Context, value string, states dal.SyncStateDAOer) (time.Time, error) {
+	var (
+		err error
+		run *models.SyncState
+	)
 
-				log.Printf("Syncing match results for club '%s' and event '%s' ...", club.Name, event.Name)
-				if data, err = svc.MatchEventsByClubNameAndEventName(club.Name, event.Name); err != nil {
-					log.Fatal(err)
-				}
+	if value != "last" {
+		return pkg.ParseSince(value)
+	}
 
-				if err = matchEventDAO.SyncAll(data); err != nil {
-					log.Fatal(err)
-				}
-			}
-		}
+	if run, err = states.GetByKey(ctx, models.SyncRunKey); err != nil {
+		return time.Time{}, fmt.Errorf("error getting the last sync run: %w", err)
+	}
 
-		// 3. The teams
-	},
+	return run.StartedAt, nil
 }
 
 func init() {
 	rootCmd.AddCommand(syncCmd)
 
-	// Here you will define your flags and configuration settings.
+	viper.SetDefault("sync.workers", crawler.DefaultOptions().Workers)
+
+	syncCmd.Flags().IntP("workers", "w", crawler.DefaultOptions().Workers, "the maximum number of parallel requests")
+	syncCmd.Flags().Float64("rate", services.DefaultTransportOptions().RateLimit, "the maximum number of requests per second, 0 disables the limit")
+
+	syncCmd.Flags().BoolVar(&syncResume, "resume", false, "continue the last sync if it did not finish")
+	syncCmd.Flags().StringVar(&syncSince, "since", "", "only store events and clubs that changed since a duration, date, RFC3339 time or last")
 
-	// Cobra supports Persistent Flags which will work for this command
-	// and all subcommands, e.g.:
-	// syncCmd.PersistentFlags().String("foo", "", "A help for foo")
+	syncCmd.Flags().StringSliceVar(&syncScope.Organizations, "org", nil, "only sync the organizations with these ids or names")
+	syncCmd.Flags().StringSliceVar(&syncScope.Events, "event", nil, "only sync the events with these ids or names")
+	syncCmd.Flags().StringSliceVar(&syncScope.Clubs, "club", nil, "only sync the clubs with these ids or names")
+	syncCmd.Flags().StringSliceVar(&syncScope.AgeGroups, "age", nil, "only sync the teams and matches of these age groups (e.g. G2009)")
+	syncCmd.Flags().StringSliceVar(&syncScope.Only, "only", nil, "only sync these entity types: "+strings.Join(crawler.Entities, ","))
+	syncCmd.Flags().BoolVar(&syncScope.ECNLOnly, "ecnl-only", false, "only sync ECNL organizations")
 
-	// Cobra supports local flags which will only run when this command
-	// is called directly, e.g.:
-	// syncCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
+	_ = viper.BindPFlag("sync.workers", syncCmd.Flags().Lookup("workers"))
+	_ = viper.BindPFlag("tgs.rate.limit", syncCmd.Flags().Lookup("rate"))
 }
@@ -0,0 +1,71 @@
+/*
+Copyright © 2023 Omar Crosby <omar.crosby@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"fmt"
+	"github.com/jedi-knights/ecnl/pkg/stub"
+	"github.com/spf13/cobra"
+	"log"
+	"net/http"
+)
+
+var (
+	stubDir  string
+	stubPort int
+)
+
+// tgsStubCmd represents the tgs-stub command
+var tgsStubCmd = &cobra.Command{
+	Use:   "tgs-stub",
+	Short: "Serves a local stand-in for the TGS API from fixture files",
+	Long: `The tgs-stub command serves the /api/Association, /api/Event, /api/Club and /api/Mobile
+endpoints of totalglobalsports.com from a directory of JSON fixtures.
+
+A request for /api/Event/get-org-event-by-eventID/2776 is answered with the file
+<dir>/api/Event/get-org-event-by-eventID/2776.json.  Point tgs.url in the config file
+at the stub to run sync, rpi and the api completely offline.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		addr := fmt.Sprintf(":%d", stubPort)
+
+		log.Printf("Serving TGS fixtures from '%s' on http://localhost%s", stubDir, addr)
+		log.Printf("Set tgs.url to http://localhost%s to use the stub", addr)
+
+		server := &http.Server{Addr: addr, Handler: stub.NewServer(stubDir)}
+
+		go func() {
+			<-cmd.Context().Done()
+			_ = server.Close()
+		}()
+
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Fatal(err)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(tgsStubCmd)
+
+	tgsStubCmd.Flags().StringVarP(&stubDir, "dir", "d", "testdata/tgs", "Directory containing the fixture files")
+	tgsStubCmd.Flags().IntVarP(&stubPort, "port", "p", 8090, "Port to listen on")
+}
@@ -42,9 +42,136 @@ const docTemplate = `{
                 }
             }
         },
+        "/v1/matches/corrections": {
+            "get": {
+                "description": "Lists the changes found in matches that were synced again, such as late or corrected scores",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Matches"
+                ],
+                "summary": "Lists recent match corrections",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "default": "168h",
+                        "description": "A duration (e.g. 72h), a date or an RFC3339 time",
+                        "name": "since",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 100,
+                        "description": "The maximum number of corrections",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/responses.MatchHistoryResponse"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/matches/{id}/history": {
+            "get": {
+                "description": "Lists the changes found in a match each time it was synced again",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Matches"
+                ],
+                "summary": "Lists the corrections of a match",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Match ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/responses.MatchHistoryResponse"
+                            }
+                        }
+                    }
+                }
+            }
+        },
         "/v1/rpi/{division}": {
             "get": {
-                "description": "Calculates the RPI rankings for all teams",
+                "description": "Gets the RPI rankings of the latest snapshot generated by rpigen, or of the snapshot that was current at asOf",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "RPI"
+                ],
+                "summary": "Gets the stored RPI rankings of an age group",
+                "parameters": [
+                    {
+                        "enum": [
+                            "G2006/2005",
+                            "G2008",
+                            "G2009",
+                            "G2010",
+                            "G2011",
+                            "B2006/2005",
+                            "B2008",
+                            "B2009",
+                            "B2010",
+                            "B2011"
+                        ],
+                        "type": "string",
+                        "description": "Division",
+                        "name": "division",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "A duration before now (e.g. 72h), a date or an RFC3339 time",
+                        "name": "asOf",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/responses.RPISnapshotResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/rpi/{division}/movers": {
+            "get": {
+                "description": "Lists how the rankings moved between the snapshot current at from and the one current at to, with the teams that entered and dropped out.\nWithout to the latest snapshot is compared, without from the snapshot before it.",
                 "consumes": [
                     "application/json"
                 ],
@@ -54,7 +181,7 @@ const docTemplate = `{
                 "tags": [
                     "RPI"
                 ],
-                "summary": "Examines the schedule and calculates the RPI rankings for all teams",
+                "summary": "Compares the stored RPI rankings of an age group at two points in time",
                 "parameters": [
                     {
                         "enum": [
@@ -74,6 +201,123 @@ const docTemplate = `{
                         "name": "division",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "A duration before now (e.g. 168h), a date or an RFC3339 time",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "A duration before now (e.g. 72h), a date or an RFC3339 time",
+                        "name": "to",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/responses.RPIMoversResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/runs": {
+            "get": {
+                "description": "Lists the runs of the commands that load data, such as sync and rpigen, the most recent first",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Runs"
+                ],
+                "summary": "Lists recent runs",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Only list the runs of this command (e.g. sync)",
+                        "name": "command",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "The maximum number of runs",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/responses.RunResponse"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/runs/{id}": {
+            "get": {
+                "description": "Gets a run with its parameters, counts and errors",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Runs"
+                ],
+                "summary": "Gets a run",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Run ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/responses.RunResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/teams/{id}/rpi-history": {
+            "get": {
+                "description": "Lists the ranking and RPI of a team in each stored snapshot it is ranked in, the oldest first.\nA team ranked under several names in a snapshot has a point for each name, flagged as shared. Id 0, shared by unknown teams, is rejected.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Teams"
+                ],
+                "summary": "Lists the RPI rankings of a team over time",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Team ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
@@ -82,7 +326,7 @@ const docTemplate = `{
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/models.RPIRankingData"
+                                "$ref": "#/definitions/responses.RPIHistoryPointResponse"
                             }
                         }
                     }
@@ -114,7 +358,171 @@ const docTemplate = `{
         }
     },
     "definitions": {
-        "models.RPIRankingData": {
+        "responses.EntityCountsResponse": {
+            "type": "object",
+            "properties": {
+                "fetched": {
+                    "type": "integer"
+                },
+                "inserted": {
+                    "type": "integer"
+                },
+                "removed": {
+                    "type": "integer"
+                },
+                "skipped": {
+                    "type": "integer"
+                },
+                "unchanged": {
+                    "type": "integer"
+                },
+                "updated": {
+                    "type": "integer"
+                }
+            }
+        },
+        "responses.FieldChangeResponse": {
+            "type": "object",
+            "properties": {
+                "field": {
+                    "type": "string"
+                },
+                "new": {
+                    "type": "string"
+                },
+                "old": {
+                    "type": "string"
+                }
+            }
+        },
+        "responses.HealthCheckResponse": {
+            "type": "object",
+            "properties": {
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "responses.MatchHistoryResponse": {
+            "type": "object",
+            "properties": {
+                "awayTeam": {
+                    "type": "string"
+                },
+                "changedAt": {
+                    "type": "string"
+                },
+                "changes": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/responses.FieldChangeResponse"
+                    }
+                },
+                "division": {
+                    "type": "string"
+                },
+                "eventName": {
+                    "type": "string"
+                },
+                "homeTeam": {
+                    "type": "string"
+                },
+                "matchId": {
+                    "type": "integer"
+                }
+            }
+        },
+        "responses.RPIHistoryPointResponse": {
+            "type": "object",
+            "properties": {
+                "ageGroup": {
+                    "type": "string"
+                },
+                "computedAt": {
+                    "type": "string"
+                },
+                "matchCutoff": {
+                    "type": "string"
+                },
+                "ranking": {
+                    "type": "integer"
+                },
+                "rpi": {
+                    "type": "number"
+                },
+                "shared": {
+                    "type": "boolean"
+                },
+                "snapshotId": {
+                    "type": "string"
+                },
+                "teamName": {
+                    "type": "string"
+                }
+            }
+        },
+        "responses.RPIMoveResponse": {
+            "type": "object",
+            "properties": {
+                "fromRanking": {
+                    "type": "integer"
+                },
+                "fromRpi": {
+                    "type": "number"
+                },
+                "rankDelta": {
+                    "type": "integer"
+                },
+                "rpiDelta": {
+                    "type": "number"
+                },
+                "teamId": {
+                    "type": "integer"
+                },
+                "teamName": {
+                    "type": "string"
+                },
+                "toRanking": {
+                    "type": "integer"
+                },
+                "toRpi": {
+                    "type": "number"
+                }
+            }
+        },
+        "responses.RPIMoversResponse": {
+            "type": "object",
+            "properties": {
+                "ageGroup": {
+                    "type": "string"
+                },
+                "dropOuts": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/responses.RPIMoveResponse"
+                    }
+                },
+                "entries": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/responses.RPIMoveResponse"
+                    }
+                },
+                "from": {
+                    "$ref": "#/definitions/responses.RPISnapshotSummaryResponse"
+                },
+                "moves": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/responses.RPIMoveResponse"
+                    }
+                },
+                "to": {
+                    "$ref": "#/definitions/responses.RPISnapshotSummaryResponse"
+                }
+            }
+        },
+        "responses.RPIRankingResponse": {
             "type": "object",
             "properties": {
                 "ranking": {
@@ -131,10 +539,92 @@ const docTemplate = `{
                 }
             }
         },
-        "responses.HealthCheckResponse": {
+        "responses.RPISnapshotResponse": {
             "type": "object",
             "properties": {
-                "message": {
+                "ageGroup": {
+                    "type": "string"
+                },
+                "computedAt": {
+                    "type": "string"
+                },
+                "flight": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "matchCount": {
+                    "type": "integer"
+                },
+                "matchCutoff": {
+                    "type": "string"
+                },
+                "rankings": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/responses.RPIRankingResponse"
+                    }
+                },
+                "runId": {
+                    "type": "string"
+                }
+            }
+        },
+        "responses.RPISnapshotSummaryResponse": {
+            "type": "object",
+            "properties": {
+                "computedAt": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "matchCount": {
+                    "type": "integer"
+                },
+                "matchCutoff": {
+                    "type": "string"
+                }
+            }
+        },
+        "responses.RunResponse": {
+            "type": "object",
+            "properties": {
+                "command": {
+                    "type": "string"
+                },
+                "entities": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/responses.EntityCountsResponse"
+                    }
+                },
+                "errors": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "finishedAt": {
+                    "type": "string"
+                },
+                "httpCalls": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "params": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "startedAt": {
+                    "type": "string"
+                },
+                "status": {
                     "type": "string"
                 }
             }
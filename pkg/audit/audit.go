@@ -0,0 +1,239 @@
+package audit
+
+import (
+	"fmt"
+	"github.com/jedi-knights/ecnl/pkg/models"
+	"sort"
+	"strings"
+	"time"
+)
+
+// The names of the checks, they are also the keys of the thresholds.
+const (
+	CheckOrphanMatchTeams      = "orphan-match-teams"
+	CheckOrphanTeamClubs       = "orphan-team-clubs"
+	CheckMissingScores         = "missing-scores"
+	CheckBadGameDates          = "bad-game-dates"
+	CheckDuplicateMatchIds     = "duplicate-match-ids"
+	CheckInconsistentTeamNames = "inconsistent-team-names"
+)
+
+// Checks lists every check in the order they are reported.
+var Checks = []string{
+	CheckOrphanMatchTeams,
+	CheckOrphanTeamClubs,
+	CheckMissingScores,
+	CheckBadGameDates,
+	CheckDuplicateMatchIds,
+	CheckInconsistentTeamNames,
+}
+
+var descriptions = map[string]string{
+	CheckOrphanMatchTeams:      "matches whose home or away team is not in the teams collection",
+	CheckOrphanTeamClubs:       "teams whose club is not in the clubs collection",
+	CheckMissingScores:         "played matches without a goal, a 0-0 draw cannot be told apart from a score that was never reported",
+	CheckBadGameDates:          "matches whose game date cannot be parsed",
+	CheckDuplicateMatchIds:     "match ids stored more than once",
+	CheckInconsistentTeamNames: "team ids that appear under different names across matches",
+}
+
+// Finding is a single problem found by a check.
+type Finding struct {
+	Subject string `json:"subject"`
+	Detail  string `json:"detail"`
+}
+
+// Result is the outcome of a check.
+type Result struct {
+	Check       string    `json:"check"`
+	Description string    `json:"description"`
+	Count       int       `json:"count"`
+	Threshold   int       `json:"threshold"`
+	Failed      bool      `json:"failed"`
+	Findings    []Finding `json:"findings,omitempty"`
+}
+
+// Report is the outcome of an audit.
+type Report struct {
+	AuditedAt time.Time `json:"auditedAt"`
+	Clubs     int       `json:"clubs"`
+	Teams     int       `json:"teams"`
+	Matches   int       `json:"matches"`
+	Results   []Result  `json:"results"`
+}
+
+// Failed returns true when any check found more problems than its threshold allows.
+func (r *Report) Failed() bool {
+	for _, result := range r.Results {
+		if result.Failed {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Auditor runs the checks on records fed to it one at a time, so the data never has to be held in memory.
+// The clubs go first, then the teams and then the matches, the checks of a record look up the ones before it.
+type Auditor struct {
+	now     time.Time
+	samples int
+	report  Report
+	counts  map[string]int
+	found   map[string][]Finding
+	clubs   map[int]bool
+	teams   map[int]bool
+	matches map[int]int
+	names   map[int]map[string]bool
+}
+
+// NewAuditor creates an auditor for which matches scheduled before now count as played.
+// It keeps up to samples findings per check, all of them when samples is 0, and counts the rest.
+func NewAuditor(now time.Time, samples int) *Auditor {
+	return &Auditor{
+		now:     now,
+		samples: samples,
+		report:  Report{AuditedAt: now},
+		counts:  make(map[string]int),
+		found:   make(map[string][]Finding),
+		clubs:   make(map[int]bool),
+		teams:   make(map[int]bool),
+		matches: make(map[int]int),
+		names:   make(map[int]map[string]bool),
+	}
+}
+
+// Club audits a club.
+func (a *Auditor) Club(club models.Club) {
+	a.report.Clubs++
+	a.clubs[club.ClubId] = true
+}
+
+// Team audits a team, after all the clubs.
+func (a *Auditor) Team(team models.Team) {
+	a.report.Teams++
+	a.teams[team.Id] = true
+
+	if !a.clubs[team.ClubId] {
+		a.find(CheckOrphanTeamClubs, Finding{
+			Subject: fmt.Sprintf("team %d", team.Id),
+			Detail:  fmt.Sprintf("club %d of '%s' is unknown", team.ClubId, team.Name),
+		})
+	}
+}
+
+// Match audits a match, after all the teams.
+func (a *Auditor) Match(match models.MatchEvent) {
+	a.report.Matches++
+	a.matches[match.MatchId]++
+
+	for _, side := range []struct {
+		name string
+		id   int
+		team string
+	}{{"home", match.HomeTeamId, match.HomeTeamName}, {"away", match.AwayTeamId, match.AwayTeamName}} {
+		if !a.teams[side.id] {
+			a.find(CheckOrphanMatchTeams, Finding{
+				Subject: matchSubject(match),
+				Detail:  fmt.Sprintf("%s team %d '%s' is unknown", side.name, side.id, side.team),
+			})
+		}
+
+		if a.names[side.id] == nil {
+			a.names[side.id] = make(map[string]bool)
+		}
+
+		a.names[side.id][side.team] = true
+	}
+
+	// Like the RPI cutoffs, the game date is in local time.
+	playedAt, err := match.PlayedAt()
+	if err != nil {
+		a.find(CheckBadGameDates, Finding{
+			Subject: matchSubject(match),
+			Detail:  fmt.Sprintf("game date '%s' is not of the form %s", match.GameDate, models.GameDateLayout),
+		})
+
+		return
+	}
+
+	if playedAt.Before(a.now) && match.HomeTeamScore == 0 && match.AwayTeamScore == 0 {
+		a.find(CheckMissingScores, Finding{
+			Subject: matchSubject(match),
+			Detail:  fmt.Sprintf("%s played on %s has no goals", match.String(), match.GameDate),
+		})
+	}
+}
+
+// Report finishes the checks that need every match and reports them all,
+// a check fails when it finds more problems than its threshold. Checks without a threshold allow none.
+// It is called once, after the last match.
+func (a *Auditor) Report(thresholds map[string]int) *Report {
+	for _, id := range sortedKeys(a.matches) {
+		if a.matches[id] > 1 {
+			a.find(CheckDuplicateMatchIds, Finding{
+				Subject: fmt.Sprintf("match %d", id),
+				Detail:  fmt.Sprintf("stored %d times", a.matches[id]),
+			})
+		}
+	}
+
+	for _, id := range sortedKeys(a.names) {
+		if len(a.names[id]) < 2 {
+			continue
+		}
+
+		var distinct []string
+		for name := range a.names[id] {
+			distinct = append(distinct, name)
+		}
+
+		sort.Strings(distinct)
+
+		a.find(CheckInconsistentTeamNames, Finding{
+			Subject: fmt.Sprintf("team %d", id),
+			Detail:  "named '" + strings.Join(distinct, "', '") + "'",
+		})
+	}
+
+	report := a.report
+
+	for _, check := range Checks {
+		result := Result{
+			Check:       check,
+			Description: descriptions[check],
+			Count:       a.counts[check],
+			Threshold:   thresholds[check],
+			Findings:    a.found[check],
+		}
+
+		result.Failed = result.Count > result.Threshold
+
+		report.Results = append(report.Results, result)
+	}
+
+	return &report
+}
+
+func (a *Auditor) find(check string, finding Finding) {
+	a.counts[check]++
+
+	if a.samples == 0 || len(a.found[check]) < a.samples {
+		a.found[check] = append(a.found[check], finding)
+	}
+}
+
+func matchSubject(match models.MatchEvent) string {
+	return fmt.Sprintf("match %d", match.MatchId)
+}
+
+func sortedKeys[V any](m map[int]V) []int {
+	keys := make([]int, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+
+	sort.Ints(keys)
+
+	return keys
+}
@@ -0,0 +1,160 @@
+package audit_test
+
+import (
+	"github.com/jedi-knights/ecnl/pkg/audit"
+	"github.com/jedi-knights/ecnl/pkg/models"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"time"
+)
+
+var _ = Describe("Audit", func() {
+	var (
+		now     time.Time
+		clubs   []models.Club
+		teams   []models.Team
+		matches []models.MatchEvent
+	)
+
+	run := func(thresholds map[string]int) *audit.Report {
+		auditor := audit.NewAuditor(now, 0)
+
+		for _, club := range clubs {
+			auditor.Club(club)
+		}
+
+		for _, team := range teams {
+			auditor.Team(team)
+		}
+
+		for _, match := range matches {
+			auditor.Match(match)
+		}
+
+		return auditor.Report(thresholds)
+	}
+
+	result := func(report *audit.Report, check string) audit.Result {
+		for _, r := range report.Results {
+			if r.Check == check {
+				return r
+			}
+		}
+
+		Fail("no result for " + check)
+
+		return audit.Result{}
+	}
+
+	BeforeEach(func() {
+		now = time.Date(2023, 10, 15, 0, 0, 0, 0, time.UTC)
+		clubs = []models.Club{{ClubId: 18}, {ClubId: 100}}
+		teams = []models.Team{{Id: 1001, ClubId: 18}, {Id: 1002, ClubId: 100}}
+		matches = []models.MatchEvent{
+			{MatchId: 9001, GameDate: "2023-10-07T10:00:00", HomeTeamId: 1001, HomeTeamName: "Alabama FC", HomeTeamScore: 2, AwayTeamId: 1002, AwayTeamName: "Concorde Fire", AwayTeamScore: 1},
+			{MatchId: 9002, GameDate: "2023-10-21T10:00:00", HomeTeamId: 1002, HomeTeamName: "Concorde Fire", AwayTeamId: 1001, AwayTeamName: "Alabama FC"},
+		}
+	})
+
+	It("should pass clean data", func() {
+		// Act
+		report := run(nil)
+
+		// Assert
+		Expect(report.Failed()).To(BeFalse())
+		Expect(report.Results).To(HaveLen(len(audit.Checks)))
+		Expect(report.Matches).To(Equal(2))
+	})
+
+	It("should find matches and teams that point at missing records", func() {
+		// Arrange
+		teams = append(teams, models.Team{Id: 1003, ClubId: 7})
+		matches[1].AwayTeamId = 1004
+
+		// Act
+		report := run(nil)
+
+		// Assert
+		Expect(result(report, audit.CheckOrphanMatchTeams).Findings).To(ConsistOf(audit.Finding{Subject: "match 9002", Detail: "away team 1004 'Alabama FC' is unknown"}))
+		Expect(result(report, audit.CheckOrphanTeamClubs).Count).To(Equal(1))
+		Expect(report.Failed()).To(BeTrue())
+	})
+
+	It("should find played matches without a score and unparseable dates", func() {
+		// Arrange
+		matches[1].GameDate = "2023-10-08T10:00:00"
+		matches = append(matches, models.MatchEvent{MatchId: 9003, GameDate: "TBD", HomeTeamId: 1001, AwayTeamId: 1002})
+
+		// Act
+		report := run(nil)
+
+		// Assert
+		Expect(result(report, audit.CheckMissingScores).Findings).To(HaveLen(1))
+		Expect(result(report, audit.CheckMissingScores).Findings[0].Subject).To(Equal("match 9002"))
+		Expect(result(report, audit.CheckBadGameDates).Findings[0].Subject).To(Equal("match 9003"))
+	})
+
+	It("should find duplicate match ids and teams with many names", func() {
+		// Arrange
+		duplicate := matches[0]
+		duplicate.HomeTeamName = "Alabama FC ECNL G09"
+		matches = append(matches, duplicate)
+
+		// Act
+		report := run(nil)
+
+		// Assert
+		Expect(result(report, audit.CheckDuplicateMatchIds).Findings).To(ConsistOf(audit.Finding{Subject: "match 9001", Detail: "stored 2 times"}))
+		Expect(result(report, audit.CheckInconsistentTeamNames).Findings).To(ConsistOf(audit.Finding{Subject: "team 1001", Detail: "named 'Alabama FC', 'Alabama FC ECNL G09'"}))
+	})
+
+	It("should tell whether a match was played by its game date in local time", func() {
+		// Arrange
+		local := time.Local
+		time.Local = time.FixedZone("AEST", 10*60*60)
+		DeferCleanup(func() { time.Local = local })
+
+		// Kick-off at 10:00 local time is midnight UTC, before now, read as UTC it would be after now.
+		now = time.Date(2023, 10, 7, 5, 0, 0, 0, time.UTC)
+		matches[0].HomeTeamScore, matches[0].AwayTeamScore = 0, 0
+
+		// Act
+		report := run(nil)
+
+		// Assert
+		Expect(result(report, audit.CheckMissingScores).Findings).To(ConsistOf(HaveField("Subject", "match 9001")))
+	})
+
+	It("should only fail the checks that exceed their threshold", func() {
+		// Arrange
+		matches[1].GameDate = "2023-10-08T10:00:00"
+
+		// Act
+		report := run(map[string]int{audit.CheckMissingScores: 1})
+
+		// Assert
+		Expect(result(report, audit.CheckMissingScores).Count).To(Equal(1))
+		Expect(result(report, audit.CheckMissingScores).Failed).To(BeFalse())
+		Expect(report.Failed()).To(BeFalse())
+	})
+
+	It("should only keep samples of the findings of a streamed audit but count them all", func() {
+		// Arrange
+		auditor := audit.NewAuditor(now, 1)
+		auditor.Club(models.Club{ClubId: 18})
+		auditor.Team(models.Team{Id: 1001, ClubId: 18})
+
+		// Act
+		for id := 9001; id <= 9003; id++ {
+			auditor.Match(models.MatchEvent{MatchId: id, GameDate: "2023-10-07T10:00:00", HomeTeamId: 1001, AwayTeamId: 1002})
+		}
+
+		report := auditor.Report(nil)
+
+		// Assert
+		Expect(report.Matches).To(Equal(3))
+		Expect(result(report, audit.CheckOrphanMatchTeams).Count).To(Equal(3))
+		Expect(result(report, audit.CheckOrphanMatchTeams).Findings).To(HaveLen(1))
+		Expect(result(report, audit.CheckMissingScores).Count).To(Equal(3))
+	})
+})
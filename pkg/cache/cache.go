@@ -0,0 +1,42 @@
+package cache
+
+// Cacher is the interface implemented by the caches used to avoid repeated upstream requests.
+// Implementations must be safe for use by multiple goroutines.
+type Cacher interface {
+	// Get returns the value stored under key and whether it was found.
+	Get(key string) (any, bool)
+	// Set stores the value under key.
+	Set(key string, value any)
+	// Delete removes the value stored under key.
+	Delete(key string)
+	// Purge removes every value from the cache.
+	Purge()
+}
+
+// Get returns the value stored under key if it is present and of type T.
+// A nil cache always misses.
+func Get[T any](c Cacher, key string) (T, bool) {
+	var zero T
+
+	if c == nil {
+		return zero, false
+	}
+
+	value, ok := c.Get(key)
+	if !ok {
+		return zero, false
+	}
+
+	typed, ok := value.(T)
+
+	return typed, ok
+}
+
+// Set stores the value under key. Setting a value on a nil cache does nothing.
+func Set(c Cacher, key string, value any) {
+	if c == nil {
+		return
+	}
+
+	c.Set(key, value)
+}
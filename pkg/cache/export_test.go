@@ -0,0 +1,8 @@
+package cache
+
+import "time"
+
+// SetClock replaces the clock used by the memory cache so expiry can be tested deterministically.
+func SetClock(m *Memory, now func() time.Time) {
+	m.now = now
+}
@@ -0,0 +1,120 @@
+package cache
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// entry is an item held by the memory cache.
+type entry struct {
+	key     string
+	value   any
+	expires time.Time
+}
+
+// Memory is an in-memory cache that expires entries after a time to live and
+// evicts the least recently used entry once it holds more than its capacity.
+type Memory struct {
+	mu       sync.Mutex
+	capacity int
+	ttl      time.Duration
+	items    map[string]*list.Element
+	order    *list.List
+	now      func() time.Time
+}
+
+// NewMemory creates a memory cache.
+// A capacity of zero or less means the cache is unbounded and a ttl of zero or less means entries never expire.
+func NewMemory(capacity int, ttl time.Duration) *Memory {
+	return &Memory{
+		capacity: capacity,
+		ttl:      ttl,
+		items:    make(map[string]*list.Element),
+		order:    list.New(),
+		now:      time.Now,
+	}
+}
+
+// Get returns the value stored under key and whether it was found.
+func (m *Memory) Get(key string) (any, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	element, ok := m.items[key]
+	if !ok {
+		return nil, false
+	}
+
+	item := element.Value.(*entry)
+	if m.expired(item) {
+		m.remove(element)
+		return nil, false
+	}
+
+	m.order.MoveToFront(element)
+
+	return item.value, true
+}
+
+// Set stores the value under key, evicting the least recently used entry if the cache is full.
+func (m *Memory) Set(key string, value any) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var expires time.Time
+	if m.ttl > 0 {
+		expires = m.now().Add(m.ttl)
+	}
+
+	if element, ok := m.items[key]; ok {
+		item := element.Value.(*entry)
+		item.value = value
+		item.expires = expires
+		m.order.MoveToFront(element)
+
+		return
+	}
+
+	m.items[key] = m.order.PushFront(&entry{key: key, value: value, expires: expires})
+
+	for m.capacity > 0 && m.order.Len() > m.capacity {
+		m.remove(m.order.Back())
+	}
+}
+
+// Delete removes the value stored under key.
+func (m *Memory) Delete(key string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if element, ok := m.items[key]; ok {
+		m.remove(element)
+	}
+}
+
+// Purge removes every value from the cache.
+func (m *Memory) Purge() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.items = make(map[string]*list.Element)
+	m.order.Init()
+}
+
+// Len returns the number of entries held by the cache, including entries that have expired but not yet been evicted.
+func (m *Memory) Len() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.order.Len()
+}
+
+func (m *Memory) expired(item *entry) bool {
+	return !item.expires.IsZero() && m.now().After(item.expires)
+}
+
+func (m *Memory) remove(element *list.Element) {
+	m.order.Remove(element)
+	delete(m.items, element.Value.(*entry).key)
+}
@@ -0,0 +1,123 @@
+package cache_test
+
+import (
+	"fmt"
+	"github.com/jedi-knights/ecnl/pkg/cache"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"sync"
+	"time"
+)
+
+var _ = Describe("Memory", func() {
+	var (
+		now time.Time
+		mem *cache.Memory
+	)
+
+	BeforeEach(func() {
+		now = time.Date(2023, 9, 9, 12, 0, 0, 0, time.UTC)
+		mem = cache.NewMemory(2, time.Minute)
+		cache.SetClock(mem, func() time.Time { return now })
+	})
+
+	It("should return stored values", func() {
+		// Act
+		mem.Set("a", 1)
+
+		// Assert
+		value, ok := cache.Get[int](mem, "a")
+		Expect(ok).To(BeTrue())
+		Expect(value).To(Equal(1))
+	})
+
+	It("should miss when the stored value has a different type", func() {
+		// Act
+		mem.Set("a", 1)
+
+		// Assert
+		_, ok := cache.Get[string](mem, "a")
+		Expect(ok).To(BeFalse())
+	})
+
+	It("should expire entries after the ttl", func() {
+		// Arrange
+		mem.Set("a", 1)
+
+		// Act
+		now = now.Add(2 * time.Minute)
+
+		// Assert
+		_, ok := mem.Get("a")
+		Expect(ok).To(BeFalse())
+		Expect(mem.Len()).To(Equal(0))
+	})
+
+	It("should evict the least recently used entry", func() {
+		// Arrange
+		mem.Set("a", 1)
+		mem.Set("b", 2)
+		_, _ = mem.Get("a")
+
+		// Act
+		mem.Set("c", 3)
+
+		// Assert
+		_, ok := mem.Get("b")
+		Expect(ok).To(BeFalse())
+		_, ok = mem.Get("a")
+		Expect(ok).To(BeTrue())
+		_, ok = mem.Get("c")
+		Expect(ok).To(BeTrue())
+	})
+
+	It("should support explicit invalidation", func() {
+		// Arrange
+		mem.Set("a", 1)
+		mem.Set("b", 2)
+
+		// Act
+		mem.Delete("a")
+
+		// Assert
+		_, ok := mem.Get("a")
+		Expect(ok).To(BeFalse())
+		Expect(mem.Len()).To(Equal(1))
+
+		mem.Purge()
+		Expect(mem.Len()).To(Equal(0))
+	})
+
+	It("should be safe for concurrent use", func() {
+		// Arrange
+		var wg sync.WaitGroup
+		unbounded := cache.NewMemory(0, 0)
+
+		// Act
+		for i := 0; i < 50; i++ {
+			wg.Add(1)
+
+			go func(i int) {
+				defer wg.Done()
+
+				key := fmt.Sprintf("key-%d", i%10)
+				unbounded.Set(key, i)
+				_, _ = unbounded.Get(key)
+			}(i)
+		}
+
+		wg.Wait()
+
+		// Assert
+		Expect(unbounded.Len()).To(Equal(10))
+	})
+
+	It("should treat a nil cache as always empty", func() {
+		// Act
+		cache.Set(nil, "a", 1)
+
+		// Assert
+		_, ok := cache.Get[int](nil, "a")
+		Expect(ok).To(BeFalse())
+	})
+})
@@ -13,9 +13,10 @@ const targetUrl = "https://raw.githubusercontent.com/ocrosby/soccer-data/main/or
 
 var _ = Describe("ClubTranslate", Ordered, func() {
 	var (
-		ctx   context.Context
-		cli   *http.Client
-		trans *pkg.ClubTranslate
+		ctx    context.Context
+		cancel context.CancelFunc
+		cli    *http.Client
+		trans  *pkg.ClubTranslate
 	)
 
 	BeforeAll(func() {
@@ -24,7 +25,7 @@ var _ = Describe("ClubTranslate", Ordered, func() {
 		)
 
 		cli = &http.Client{}
-		ctx, _ = context.WithTimeout(context.Background(), 5*time.Second)
+		ctx, cancel = context.WithTimeout(context.Background(), 5*time.Second)
 		trans, err = pkg.NewClubTranslateFromUrl(targetUrl, ctx, cli)
 
 		Expect(err).NotTo(HaveOccurred())
@@ -32,6 +33,8 @@ var _ = Describe("ClubTranslate", Ordered, func() {
 	})
 
 	AfterAll(func() {
+		cancel()
+
 		trans = nil
 		ctx = nil
 		cli = nil
@@ -0,0 +1,31 @@
+package controllers
+
+import (
+	"context"
+	"github.com/jedi-knights/ecnl/pkg/dal"
+	"github.com/jedi-knights/ecnl/pkg/models"
+	"time"
+)
+
+type MatchHistorier interface {
+	Recent(ctx context.Context, since time.Time, limit int) ([]models.MatchHistory, error)
+	ByMatchId(ctx context.Context, id int) ([]models.MatchHistory, error)
+}
+
+type MatchHistory struct {
+	dao dal.MatchHistoryDAOer
+}
+
+func NewMatchHistory(dao dal.MatchHistoryDAOer) *MatchHistory {
+	return &MatchHistory{dao: dao}
+}
+
+// Recent returns the match corrections recorded at or after since, the most recent first.
+func (h *MatchHistory) Recent(ctx context.Context, since time.Time, limit int) ([]models.MatchHistory, error) {
+	return h.dao.GetRecent(ctx, since, limit)
+}
+
+// ByMatchId returns the corrections recorded for a match, the most recent first.
+func (h *MatchHistory) ByMatchId(ctx context.Context, id int) ([]models.MatchHistory, error) {
+	return h.dao.GetByMatchId(ctx, id)
+}
@@ -0,0 +1,39 @@
+package controllers
+
+import (
+	"context"
+	"github.com/jedi-knights/ecnl/pkg/dal"
+	"github.com/jedi-knights/ecnl/pkg/models"
+)
+
+type Matcher interface {
+	List(ctx context.Context, division, flight string, sort []string, offset, limit int) (dal.Page[models.MatchEvent], error)
+}
+
+type Matches struct {
+	dao dal.MatchEventDAOer
+}
+
+func NewMatches(dao dal.MatchEventDAOer) *Matches {
+	return &Matches{dao: dao}
+}
+
+// List returns a page of the stored matches, only those of division and flight unless they are empty.
+// The matches are ordered by the sort fields, by game date when there are none.
+func (m *Matches) List(ctx context.Context, division, flight string, sort []string, offset, limit int) (dal.Page[models.MatchEvent], error) {
+	opts := dal.ListOptions{Filter: map[string]any{}, Sort: sort, Offset: offset, Limit: limit}
+
+	if division != "" {
+		opts.Filter["division"] = division
+	}
+
+	if flight != "" {
+		opts.Filter["flight"] = flight
+	}
+
+	if len(opts.Sort) == 0 {
+		opts.Sort = []string{"gamedate", "matchid"}
+	}
+
+	return m.dao.List(ctx, opts)
+}
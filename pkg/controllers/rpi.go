@@ -2,11 +2,12 @@ package controllers
 
 import (
 	"context"
+	"fmt"
+	"github.com/jedi-knights/ecnl/pkg"
 	"github.com/jedi-knights/ecnl/pkg/dal"
 	"github.com/jedi-knights/ecnl/pkg/models"
 	"github.com/jedi-knights/rpi/pkg/match"
 	"github.com/jedi-knights/rpi/pkg/schedule"
-	"go.mongodb.org/mongo-driver/mongo"
 	"log"
 	"slices"
 	"sort"
@@ -14,48 +15,55 @@ import (
 )
 
 type RIPer interface {
-	GetRanking()
+	GenerateRankings(ctx context.Context, ageGroup string) ([]models.RPIRankingData, error)
+	Generate(ctx context.Context, ageGroup string, cutoff time.Time, runId string) (*models.RPISnapshot, bool, error)
+	Rankings(ctx context.Context, ageGroup string, asOf time.Time) (*models.RPISnapshot, []models.RPIEvent, error)
+	Movers(ctx context.Context, ageGroup string, from, to time.Time) (*models.RPIMovers, error)
+	History(ctx context.Context, teamId int) ([]models.RPIHistoryPoint, error)
 }
 
-type RPI struct{}
+type RPI struct {
+	matches   dal.MatchEventDAOer
+	snapshots dal.RPISnapshotDAOer
+	rows      dal.RPIEventDAOer
+}
 
-func NewRPI() *RPI {
-	return &RPI{}
+// NewRPI creates an RPI controller that ranks the matches it reads from matches
+// and keeps the snapshots of the rankings in snapshots, their rows in rows.
+func NewRPI(matches dal.MatchEventDAOer, snapshots dal.RPISnapshotDAOer, rows dal.RPIEventDAOer) *RPI {
+	return &RPI{matches: matches, snapshots: snapshots, rows: rows}
 }
 
-func (r *RPI) GenerateRankings(ageGroup string) ([]models.RPIRankingData, error) {
+func (r *RPI) GenerateRankings(ctx context.Context, ageGroup string) ([]models.RPIRankingData, error) {
 	var (
-		err         error
-		client      *mongo.Client
-		ctx         context.Context
-		rpi         float64
-		teamNames   []string
-		matches     []models.MatchEvent
-		rpiSchedule *schedule.Schedule
-		data        []models.RPIRankingData
+		err     error
+		matches []models.MatchEvent
 	)
 
 	log.Printf("processing age group %s\n", ageGroup)
 
-	ctx, _ = context.WithTimeout(context.Background(), 1*time.Minute)
-
-	// get the client
-	client = dal.MustGetClient(ctx)
-
-	// get the database
-	database := client.Database("ecnl")
-
-	// create collection
-	matchesCollection := database.Collection("matches")
-
-	// create data access objects
-	matchDAO := dal.NewMatchEventDAO(ctx, matchesCollection)
-
 	// This should return with the latest matches for the ECNL
-	if matches, err = matchDAO.GetECNLByAgeGroup(ageGroup); err != nil {
+	if matches, err = r.matches.GetECNLByAgeGroup(ctx, ageGroup); err != nil {
 		return nil, err
 	}
 
+	if len(matches) == 0 {
+		return nil, fmt.Errorf("matches for age group '%s': %w", ageGroup, pkg.ErrNotFound)
+	}
+
+	return rank(matches)
+}
+
+// rank computes the RPI of the teams of the matches and ranks them, the highest RPI first.
+func rank(matches []models.MatchEvent) ([]models.RPIRankingData, error) {
+	var (
+		err         error
+		rpi         float64
+		teamNames   []string
+		rpiSchedule *schedule.Schedule
+		data        []models.RPIRankingData
+	)
+
 	// convert the matches to the scheule for RPI computation
 	rpiSchedule = schedule.NewSchedule()
 
@@ -111,7 +119,7 @@ func (r *RPI) GenerateRankings(ageGroup string) ([]models.RPIRankingData, error)
 
 		// calculate the RPI for the team
 		if rpi, err = rpiSchedule.CalculateRPI(teamName); err != nil {
-			panic(err)
+			return nil, fmt.Errorf("error calculating the rpi for '%s': %w", teamName, err)
 		}
 
 		// create the RPI ranking data struct
@@ -0,0 +1,172 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/jedi-knights/ecnl/pkg"
+	"github.com/jedi-knights/ecnl/pkg/models"
+	"sort"
+	"time"
+)
+
+// Movers compares the snapshot of the age group that was current at from with the one current at to.
+// A zero to compares the latest snapshot and a zero from the snapshot before the one of to.
+func (r *RPI) Movers(ctx context.Context, ageGroup string, from, to time.Time) (*models.RPIMovers, error) {
+	var (
+		err              error
+		before, after    *models.RPISnapshot
+		fromRows, toRows []models.RPIEvent
+	)
+
+	if after, err = r.snapshots.GetAsOf(ctx, ageGroup, to); err != nil {
+		return nil, err
+	}
+
+	if from.IsZero() {
+		from = after.MatchCutoff.Add(-time.Nanosecond)
+	}
+
+	if before, err = r.snapshots.GetAsOf(ctx, ageGroup, from); err != nil {
+		return nil, err
+	}
+
+	if fromRows, err = r.rows.GetBySnapshotId(ctx, before.Id); err != nil {
+		return nil, err
+	}
+
+	if toRows, err = r.rows.GetBySnapshotId(ctx, after.Id); err != nil {
+		return nil, err
+	}
+
+	movers := compare(fromRows, toRows)
+	movers.From, movers.To = *before, *after
+
+	return &movers, nil
+}
+
+// History returns the rankings of a team in the snapshots it is ranked in, the oldest first.
+// Unknown teams share id 0, so their history fails with pkg.ErrInvalid.
+// A team id ranked under several names in a snapshot has a point for each of them, flagged as shared.
+func (r *RPI) History(ctx context.Context, teamId int) ([]models.RPIHistoryPoint, error) {
+	var (
+		err      error
+		rows     []models.RPIEvent
+		points   []models.RPIHistoryPoint
+		rankings []string
+	)
+
+	if teamId <= 0 {
+		return nil, fmt.Errorf("rpi history of team %d, unknown teams share id 0: %w", teamId, pkg.ErrInvalid)
+	}
+
+	if rows, err = r.rows.GetByTeamId(ctx, teamId); err != nil {
+		return nil, err
+	}
+
+	snapshots := make(map[string]*models.RPISnapshot)
+
+	for _, row := range rows {
+		// Rows generated before there were snapshots only have their timestamp.
+		point := models.RPIHistoryPoint{ComputedAt: row.Timestamp, MatchCutoff: row.Timestamp, TeamName: row.TeamName, Ranking: row.Ranking, RPI: row.Value}
+
+		if row.SnapshotId != "" {
+			snapshot, ok := snapshots[row.SnapshotId]
+
+			if !ok {
+				if snapshot, err = r.snapshots.GetById(ctx, row.SnapshotId); err != nil && !errors.Is(err, pkg.ErrNotFound) {
+					return nil, err
+				}
+
+				snapshots[row.SnapshotId] = snapshot
+			}
+
+			// The rows of a snapshot whose generation was interrupted are left out.
+			if snapshot == nil {
+				continue
+			}
+
+			point.SnapshotId = snapshot.Id
+			point.AgeGroup = snapshot.AgeGroup
+			point.ComputedAt = snapshot.ComputedAt
+			point.MatchCutoff = snapshot.MatchCutoff
+		}
+
+		points = append(points, point)
+		rankings = append(rankings, rankingOf(row))
+	}
+
+	if len(points) == 0 {
+		return nil, fmt.Errorf("rpi history of team %d: %w", teamId, pkg.ErrNotFound)
+	}
+
+	counts := make(map[string]int, len(rankings))
+	for _, ranking := range rankings {
+		counts[ranking]++
+	}
+
+	for i := range points {
+		points[i].Shared = counts[rankings[i]] > 1
+	}
+
+	sort.SliceStable(points, func(i, j int) bool {
+		if !points[i].MatchCutoff.Equal(points[j].MatchCutoff) {
+			return points[i].MatchCutoff.Before(points[j].MatchCutoff)
+		}
+
+		if !points[i].ComputedAt.Equal(points[j].ComputedAt) {
+			return points[i].ComputedAt.Before(points[j].ComputedAt)
+		}
+
+		return points[i].Ranking < points[j].Ranking
+	})
+
+	return points, nil
+}
+
+// rankingOf identifies the ranking a row is part of, its snapshot or, for rows from before there were snapshots, its timestamp.
+func rankingOf(row models.RPIEvent) string {
+	if row.SnapshotId != "" {
+		return row.SnapshotId
+	}
+
+	return row.Timestamp.UTC().Format(time.RFC3339Nano)
+}
+
+// compare matches the rows of two snapshots on their team name, the key of the rows, as unknown teams share id 0.
+// The moves and entries are ordered by their ranking in the later snapshot, the drop-outs by the one in the earlier.
+func compare(fromRows, toRows []models.RPIEvent) models.RPIMovers {
+	var movers models.RPIMovers
+
+	earlier := make(map[string]models.RPIEvent, len(fromRows))
+	for _, row := range fromRows {
+		earlier[row.TeamName] = row
+	}
+
+	later := make(map[string]bool, len(toRows))
+
+	for _, row := range toRows {
+		later[row.TeamName] = true
+
+		move := models.RPIMove{TeamId: row.TeamId, TeamName: row.TeamName, ToRanking: row.Ranking, ToRPI: row.Value}
+
+		if previous, ok := earlier[row.TeamName]; ok {
+			move.FromRanking, move.FromRPI = previous.Ranking, previous.Value
+			movers.Moves = append(movers.Moves, move)
+		} else {
+			movers.Entries = append(movers.Entries, move)
+		}
+	}
+
+	for _, row := range fromRows {
+		if !later[row.TeamName] {
+			movers.DropOuts = append(movers.DropOuts, models.RPIMove{TeamId: row.TeamId, TeamName: row.TeamName, FromRanking: row.Ranking, FromRPI: row.Value})
+		}
+	}
+
+	sort.SliceStable(movers.Moves, func(i, j int) bool { return movers.Moves[i].ToRanking < movers.Moves[j].ToRanking })
+	sort.SliceStable(movers.Entries, func(i, j int) bool { return movers.Entries[i].ToRanking < movers.Entries[j].ToRanking })
+	sort.SliceStable(movers.DropOuts, func(i, j int) bool { return movers.DropOuts[i].FromRanking < movers.DropOuts[j].FromRanking })
+
+	return movers
+}
@@ -0,0 +1,159 @@
+package controllers
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/jedi-knights/ecnl/pkg"
+	"github.com/jedi-knights/ecnl/pkg/models"
+	"log"
+	"sort"
+	"time"
+)
+
+// rpiFlight is the flight the RPI rankings are computed for.
+const rpiFlight = "ECNL"
+
+// Generate ranks the teams of the age group from the ECNL matches played up to cutoff, now when it is zero,
+// and stores the rankings as a snapshot of the run with runId.
+// When the snapshot current at cutoff was generated from the same matches it is returned instead,
+// so is the snapshot stored before for the same matches and cutoff. Created is only true for a new one.
+func (r *RPI) Generate(ctx context.Context, ageGroup string, cutoff time.Time, runId string) (*models.RPISnapshot, bool, error) {
+	var (
+		err      error
+		matches  []models.MatchEvent
+		data     []models.RPIRankingData
+		existing *models.RPISnapshot
+	)
+
+	computedAt := time.Now()
+	if cutoff.IsZero() {
+		cutoff = computedAt
+	}
+
+	if matches, err = r.matches.GetECNLByAgeGroup(ctx, ageGroup); err != nil {
+		return nil, false, err
+	}
+
+	if matches = playedBy(matches, cutoff); len(matches) == 0 {
+		return nil, false, fmt.Errorf("matches for age group '%s' played by %s: %w", ageGroup, cutoff.Format(time.RFC3339), pkg.ErrNotFound)
+	}
+
+	fingerprint := fingerprintOf(matches)
+
+	// An as of query for the cutoff already finds rankings of the same matches.
+	if existing, err = r.snapshots.GetAsOf(ctx, ageGroup, cutoff); err == nil && existing.Fingerprint == fingerprint {
+		log.Printf("the matches of age group %s are unchanged since rpi snapshot %s", ageGroup, existing.Id)
+		return existing, false, nil
+	} else if err != nil && !errors.Is(err, pkg.ErrNotFound) {
+		return nil, false, err
+	}
+
+	id := snapshotId(ageGroup, fingerprint, cutoff)
+
+	if existing, err = r.snapshots.GetById(ctx, id); err == nil {
+		log.Printf("rpi snapshot %s of age group %s was already generated", id, ageGroup)
+		return existing, false, nil
+	} else if !errors.Is(err, pkg.ErrNotFound) {
+		return nil, false, err
+	}
+
+	if data, err = rank(matches); err != nil {
+		return nil, false, err
+	}
+
+	snapshot := models.RPISnapshot{
+		Id:          id,
+		AgeGroup:    ageGroup,
+		Flight:      rpiFlight,
+		ComputedAt:  computedAt.UTC(),
+		MatchCutoff: cutoff.UTC(),
+		MatchCount:  len(matches),
+		Fingerprint: fingerprint,
+		RunId:       runId,
+	}
+
+	rows := make([]models.RPIEvent, 0, len(data))
+	for _, d := range data {
+		row := models.NewRPIEvent(snapshot.ComputedAt, d)
+		row.SnapshotId = id
+
+		rows = append(rows, *row)
+	}
+
+	// The rows an interrupted generation left behind are replaced and the header is stored last,
+	// a snapshot is only read once it is complete.
+	if _, err = r.rows.DeleteBySnapshotId(ctx, id); err != nil {
+		return nil, false, fmt.Errorf("error clearing the rows of rpi snapshot %s: %w", id, err)
+	}
+
+	if err = r.rows.CreateAll(ctx, rows); err != nil {
+		return nil, false, fmt.Errorf("error storing the rows of rpi snapshot %s: %w", id, err)
+	}
+
+	if err = r.snapshots.Create(ctx, snapshot); err != nil {
+		return nil, false, fmt.Errorf("error storing rpi snapshot %s: %w", id, err)
+	}
+
+	return &snapshot, true, nil
+}
+
+// Rankings returns the stored snapshot of the age group as it stood at asOf, the latest one when it is zero,
+// along with its rows ordered by ranking.
+func (r *RPI) Rankings(ctx context.Context, ageGroup string, asOf time.Time) (*models.RPISnapshot, []models.RPIEvent, error) {
+	var (
+		err      error
+		snapshot *models.RPISnapshot
+		rows     []models.RPIEvent
+	)
+
+	if snapshot, err = r.snapshots.GetAsOf(ctx, ageGroup, asOf); err != nil {
+		return nil, nil, err
+	}
+
+	if rows, err = r.rows.GetBySnapshotId(ctx, snapshot.Id); err != nil {
+		return nil, nil, err
+	}
+
+	return snapshot, rows, nil
+}
+
+// playedBy returns the matches played at or before cutoff ordered by their id.
+// Matches without a readable game date are kept.
+func playedBy(matches []models.MatchEvent, cutoff time.Time) []models.MatchEvent {
+	var played []models.MatchEvent
+
+	for _, m := range matches {
+		if at, err := m.PlayedAt(); err == nil && at.After(cutoff) {
+			continue
+		}
+
+		played = append(played, m)
+	}
+
+	sort.Slice(played, func(i, j int) bool {
+		return played[i].MatchId < played[j].MatchId
+	})
+
+	return played
+}
+
+// snapshotId derives the id of a snapshot from its matches and its cutoff.
+// The cutoff is part of it because a snapshot of the same matches with a later cutoff
+// is not found by the as of queries for an earlier one.
+func snapshotId(ageGroup, fingerprint string, cutoff time.Time) string {
+	sum := sha256.Sum256([]byte(fingerprint + "@" + cutoff.UTC().Format(time.RFC3339Nano)))
+
+	return fmt.Sprintf("%s-%s", ageGroup, hex.EncodeToString(sum[:])[:16])
+}
+
+// fingerprintOf hashes the matches, it only changes when they do.
+func fingerprintOf(matches []models.MatchEvent) string {
+	data, _ := json.Marshal(matches)
+	sum := sha256.Sum256(data)
+
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,373 @@
+package controllers_test
+
+import (
+	"context"
+	"github.com/jedi-knights/ecnl/pkg"
+	"github.com/jedi-knights/ecnl/pkg/controllers"
+	"github.com/jedi-knights/ecnl/pkg/dal"
+	"github.com/jedi-knights/ecnl/pkg/dal/memory"
+	"github.com/jedi-knights/ecnl/pkg/models"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"time"
+)
+
+var _ = Describe("RPI", func() {
+	ctx := context.Background()
+	var (
+		repos *dal.Repositories
+		rpi   *controllers.RPI
+	)
+
+	match := func(id int, home string, homeScore int, away string, awayScore int) models.MatchEvent {
+		return models.MatchEvent{
+			MatchId:       id,
+			GameDate:      "2023-09-09T12:00:00",
+			HomeTeamId:    id * 10,
+			HomeTeamName:  home,
+			HomeTeamScore: homeScore,
+			AwayTeamId:    id*10 + 1,
+			AwayTeamName:  away,
+			AwayTeamScore: awayScore,
+			Flight:        "ECNL",
+			Division:      "G2009",
+		}
+	}
+
+	BeforeEach(func() {
+		repos = memory.NewRepositories()
+		rpi = controllers.NewRPI(repos.Matches, repos.RPISnapshots, repos.RPIEvents)
+	})
+
+	It("should rank the teams of the age group", func() {
+		// Arrange
+		_, err := repos.Matches.SyncAll(ctx, []models.MatchEvent{
+			match(1, "Alpha", 3, "Bravo", 0),
+			match(2, "Bravo", 1, "Charlie", 0),
+			match(3, "Alpha", 2, "Charlie", 0),
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		// Act
+		data, err := rpi.GenerateRankings(ctx, "G2009")
+
+		// Assert
+		Expect(err).NotTo(HaveOccurred())
+		Expect(data).To(HaveLen(3))
+		Expect(data[0].TeamName).To(Equal("Alpha"))
+		Expect(data[0].Ranking).To(Equal(1))
+		Expect(data[2].TeamName).To(Equal("Charlie"))
+	})
+
+	It("should return ErrNotFound when the age group has no ECNL matches", func() {
+		// Arrange
+		regional := match(1, "Alpha", 3, "Bravo", 0)
+		regional.Flight = "ECNL RL"
+
+		_, err := repos.Matches.SyncAll(ctx, []models.MatchEvent{regional})
+		Expect(err).NotTo(HaveOccurred())
+
+		// Act
+		_, err = rpi.GenerateRankings(ctx, "G2009")
+
+		// Assert
+		Expect(err).To(MatchError(pkg.ErrNotFound))
+	})
+
+	Describe("Generate", func() {
+		BeforeEach(func() {
+			late := match(3, "Alpha", 2, "Charlie", 0)
+			late.GameDate = "2023-10-14T12:00:00"
+
+			_, err := repos.Matches.SyncAll(ctx, []models.MatchEvent{
+				match(1, "Alpha", 3, "Bravo", 0),
+				match(2, "Bravo", 1, "Charlie", 0),
+				late,
+			})
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("should store the rankings as a snapshot of the run", func() {
+			// Act
+			snapshot, created, err := rpi.Generate(ctx, "G2009", time.Time{}, "run-1")
+
+			// Assert
+			Expect(err).NotTo(HaveOccurred())
+			Expect(created).To(BeTrue())
+			Expect(snapshot.MatchCount).To(Equal(3))
+			Expect(snapshot.RunId).To(Equal("run-1"))
+
+			rows, err := repos.RPIEvents.GetBySnapshotId(ctx, snapshot.Id)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(rows).To(HaveLen(3))
+			Expect(rows[0].Ranking).To(Equal(1))
+			Expect(rows[0].TeamName).To(Equal("Alpha"))
+		})
+
+		It("should reuse the snapshot while the matches are unchanged", func() {
+			// Arrange
+			first, _, err := rpi.Generate(ctx, "G2009", time.Time{}, "run-1")
+			Expect(err).NotTo(HaveOccurred())
+
+			// Act
+			second, created, err := rpi.Generate(ctx, "G2009", time.Time{}, "run-2")
+
+			// Assert
+			Expect(err).NotTo(HaveOccurred())
+			Expect(created).To(BeFalse())
+			Expect(second.Id).To(Equal(first.Id))
+			Expect(second.RunId).To(Equal("run-1"))
+
+			events, err := repos.RPIEvents.GetAll(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(events).To(HaveLen(3))
+		})
+
+		It("should store a snapshot for an earlier cutoff of the same matches", func() {
+			// Arrange
+			latest, _, err := rpi.Generate(ctx, "G2009", time.Time{}, "run-1")
+			Expect(err).NotTo(HaveOccurred())
+			cutoff := time.Date(2023, 10, 20, 0, 0, 0, 0, time.UTC)
+
+			// Act
+			backfilled, created, err := rpi.Generate(ctx, "G2009", cutoff, "run-2")
+
+			// Assert
+			Expect(err).NotTo(HaveOccurred())
+			Expect(created).To(BeTrue())
+			Expect(backfilled.Id).NotTo(Equal(latest.Id))
+			Expect(backfilled.MatchCutoff).To(Equal(cutoff))
+			Expect(backfilled.Fingerprint).To(Equal(latest.Fingerprint))
+
+			asOf, _, err := rpi.Rankings(ctx, "G2009", cutoff.Add(24*time.Hour))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(asOf.Id).To(Equal(backfilled.Id))
+		})
+
+		It("should reuse the snapshot of the same matches and cutoff", func() {
+			// Arrange
+			cutoff := time.Date(2023, 10, 20, 0, 0, 0, 0, time.UTC)
+			first, _, err := rpi.Generate(ctx, "G2009", cutoff, "run-1")
+			Expect(err).NotTo(HaveOccurred())
+
+			// Act
+			second, created, err := rpi.Generate(ctx, "G2009", cutoff, "run-2")
+
+			// Assert
+			Expect(err).NotTo(HaveOccurred())
+			Expect(created).To(BeFalse())
+			Expect(second.Id).To(Equal(first.Id))
+		})
+
+		It("should store a row for every team name, also when names share a team id", func() {
+			// Arrange
+			// Neither team is known, both resolve to id 0.
+			unknown := match(4, "Alpha ECNL", 1, "Delta", 1)
+			unknown.HomeTeamId, unknown.AwayTeamId = 0, 0
+
+			_, err := repos.Matches.SyncAll(ctx, []models.MatchEvent{unknown})
+			Expect(err).NotTo(HaveOccurred())
+
+			// Act
+			snapshot, created, err := rpi.Generate(ctx, "G2009", time.Time{}, "run-1")
+
+			// Assert
+			Expect(err).NotTo(HaveOccurred())
+			Expect(created).To(BeTrue())
+
+			rows, err := repos.RPIEvents.GetBySnapshotId(ctx, snapshot.Id)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(rows).To(HaveLen(5))
+			Expect(rows).To(ContainElement(And(HaveField("TeamName", "Alpha ECNL"), HaveField("TeamId", 0))))
+			Expect(rows).To(ContainElement(And(HaveField("TeamName", "Delta"), HaveField("TeamId", 0))))
+		})
+
+		It("should leave out the matches played after the cutoff", func() {
+			// Act
+			snapshot, _, err := rpi.Generate(ctx, "G2009", time.Date(2023, 10, 1, 0, 0, 0, 0, time.Local), "run-1")
+
+			// Assert
+			Expect(err).NotTo(HaveOccurred())
+			Expect(snapshot.MatchCount).To(Equal(2))
+		})
+	})
+
+	Describe("Rankings", func() {
+		It("should return the snapshot that was current at asOf", func() {
+			// Arrange
+			late := match(3, "Alpha", 2, "Charlie", 0)
+			late.GameDate = "2023-10-14T12:00:00"
+
+			_, err := repos.Matches.SyncAll(ctx, []models.MatchEvent{match(1, "Alpha", 3, "Bravo", 0), match(2, "Bravo", 1, "Charlie", 0), late})
+			Expect(err).NotTo(HaveOccurred())
+
+			october, _, err := rpi.Generate(ctx, "G2009", time.Date(2023, 10, 1, 0, 0, 0, 0, time.UTC), "run-1")
+			Expect(err).NotTo(HaveOccurred())
+			_, _, err = rpi.Generate(ctx, "G2009", time.Date(2023, 10, 15, 0, 0, 0, 0, time.UTC), "run-2")
+			Expect(err).NotTo(HaveOccurred())
+
+			// Act
+			snapshot, rows, err := rpi.Rankings(ctx, "G2009", time.Date(2023, 10, 7, 0, 0, 0, 0, time.UTC))
+
+			// Assert
+			Expect(err).NotTo(HaveOccurred())
+			Expect(snapshot.Id).To(Equal(october.Id))
+			Expect(rows).To(HaveLen(3))
+		})
+
+		It("should return ErrNotFound before the first snapshot", func() {
+			// Act
+			_, _, err := rpi.Rankings(ctx, "G2009", time.Date(2023, 10, 7, 0, 0, 0, 0, time.UTC))
+
+			// Assert
+			Expect(err).To(MatchError(pkg.ErrNotFound))
+		})
+	})
+
+	Describe("Snapshots", func() {
+		// stored stores a snapshot of G2009 with the matches played by cutoff and its rows.
+		stored := func(id string, cutoff time.Time, rows ...models.RPIEvent) {
+			Expect(repos.RPISnapshots.Create(ctx, models.RPISnapshot{Id: id, AgeGroup: "G2009", ComputedAt: cutoff, MatchCutoff: cutoff})).To(Succeed())
+
+			for i := range rows {
+				rows[i].SnapshotId, rows[i].Timestamp = id, cutoff
+			}
+
+			Expect(repos.RPIEvents.CreateAll(ctx, rows)).To(Succeed())
+		}
+
+		row := func(ranking, teamId int, teamName string, value float64) models.RPIEvent {
+			return models.RPIEvent{Ranking: ranking, TeamId: teamId, TeamName: teamName, Value: value}
+		}
+
+		BeforeEach(func() {
+			stored("G2009-september", time.Date(2023, 9, 30, 0, 0, 0, 0, time.UTC),
+				row(1, 1, "Alpha", 0.6), row(2, 2, "Bravo", 0.5), row(3, 3, "Charlie", 0.4))
+			stored("G2009-october", time.Date(2023, 10, 31, 0, 0, 0, 0, time.UTC),
+				row(1, 2, "Bravo", 0.65), row(2, 4, "Delta", 0.55), row(3, 1, "Alpha", 0.45))
+		})
+
+		Describe("Movers", func() {
+			It("should compare the latest snapshot with the one before it", func() {
+				// Act
+				movers, err := rpi.Movers(ctx, "G2009", time.Time{}, time.Time{})
+
+				// Assert
+				Expect(err).NotTo(HaveOccurred())
+				Expect(movers.From.Id).To(Equal("G2009-september"))
+				Expect(movers.To.Id).To(Equal("G2009-october"))
+
+				Expect(movers.Moves).To(HaveLen(2))
+				Expect(movers.Moves[0].TeamName).To(Equal("Bravo"))
+				Expect(movers.Moves[0].RankDelta()).To(Equal(1))
+				Expect(movers.Moves[0].RPIDelta()).To(BeNumerically("~", 0.15, 1e-9))
+				Expect(movers.Moves[1].TeamName).To(Equal("Alpha"))
+				Expect(movers.Moves[1].RankDelta()).To(Equal(-2))
+
+				Expect(movers.Entries).To(HaveLen(1))
+				Expect(movers.Entries[0].TeamName).To(Equal("Delta"))
+				Expect(movers.DropOuts).To(HaveLen(1))
+				Expect(movers.DropOuts[0].TeamName).To(Equal("Charlie"))
+				Expect(movers.DropOuts[0].FromRanking).To(Equal(3))
+			})
+
+			It("should tell apart the teams that share an id", func() {
+				// Arrange
+				stored("G2009-november", time.Date(2023, 11, 30, 0, 0, 0, 0, time.UTC),
+					row(1, 2, "Bravo", 0.7), row(2, 0, "Echo", 0.6), row(3, 0, "Foxtrot", 0.5))
+
+				// Act
+				movers, err := rpi.Movers(ctx, "G2009", time.Time{}, time.Time{})
+
+				// Assert
+				Expect(err).NotTo(HaveOccurred())
+				Expect(movers.Moves).To(HaveLen(1))
+				Expect(movers.Entries).To(HaveLen(2))
+				Expect(movers.DropOuts).To(HaveLen(2))
+			})
+
+			It("should compare the snapshots that were current at from and to", func() {
+				// Act
+				movers, err := rpi.Movers(ctx, "G2009", time.Date(2023, 10, 7, 0, 0, 0, 0, time.UTC), time.Date(2023, 10, 7, 0, 0, 0, 0, time.UTC))
+
+				// Assert
+				Expect(err).NotTo(HaveOccurred())
+				Expect(movers.From.Id).To(Equal("G2009-september"))
+				Expect(movers.To.Id).To(Equal("G2009-september"))
+				Expect(movers.Moves).To(HaveLen(3))
+				Expect(movers.Entries).To(BeEmpty())
+				Expect(movers.DropOuts).To(BeEmpty())
+			})
+
+			It("should return ErrNotFound without a snapshot before the first", func() {
+				// Act
+				_, err := rpi.Movers(ctx, "G2009", time.Time{}, time.Date(2023, 10, 7, 0, 0, 0, 0, time.UTC))
+
+				// Assert
+				Expect(err).To(MatchError(pkg.ErrNotFound))
+			})
+		})
+
+		Describe("History", func() {
+			It("should return the rankings of the team in each snapshot, the oldest first", func() {
+				// Act
+				history, err := rpi.History(ctx, 1)
+
+				// Assert
+				Expect(err).NotTo(HaveOccurred())
+				Expect(history).To(HaveLen(2))
+				Expect(history[0].SnapshotId).To(Equal("G2009-september"))
+				Expect(history[0].Ranking).To(Equal(1))
+				Expect(history[1].SnapshotId).To(Equal("G2009-october"))
+				Expect(history[1].Ranking).To(Equal(3))
+				Expect(history[1].AgeGroup).To(Equal("G2009"))
+			})
+
+			It("should leave out the rows of an incomplete snapshot", func() {
+				// Arrange
+				orphan := row(1, 1, "Alpha", 0.7)
+				orphan.SnapshotId, orphan.Timestamp = "G2009-interrupted", time.Date(2023, 11, 30, 0, 0, 0, 0, time.UTC)
+				Expect(repos.RPIEvents.CreateAll(ctx, []models.RPIEvent{orphan})).To(Succeed())
+
+				// Act
+				history, err := rpi.History(ctx, 1)
+
+				// Assert
+				Expect(err).NotTo(HaveOccurred())
+				Expect(history).To(HaveLen(2))
+			})
+
+			It("should flag the rankings of a team id under several names in a snapshot", func() {
+				// Arrange
+				stored("G2009-november", time.Date(2023, 11, 30, 0, 0, 0, 0, time.UTC),
+					row(1, 1, "Alpha", 0.7), row(2, 1, "Alpha ECNL", 0.6), row(3, 2, "Bravo", 0.5))
+
+				// Act
+				history, err := rpi.History(ctx, 1)
+
+				// Assert
+				Expect(err).NotTo(HaveOccurred())
+				Expect(history).To(HaveLen(4))
+				Expect(history[:2]).To(HaveEach(HaveField("Shared", false)))
+				Expect(history[2]).To(And(HaveField("TeamName", "Alpha"), HaveField("Shared", true)))
+				Expect(history[3]).To(And(HaveField("TeamName", "Alpha ECNL"), HaveField("Shared", true)))
+			})
+
+			It("should reject the id shared by unknown teams", func() {
+				// Act
+				_, err := rpi.History(ctx, 0)
+
+				// Assert
+				Expect(err).To(MatchError(pkg.ErrInvalid))
+			})
+
+			It("should return ErrNotFound for a team that was never ranked", func() {
+				// Act
+				_, err := rpi.History(ctx, 42)
+
+				// Assert
+				Expect(err).To(MatchError(pkg.ErrNotFound))
+			})
+		})
+	})
+})
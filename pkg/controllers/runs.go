@@ -0,0 +1,30 @@
+package controllers
+
+import (
+	"context"
+	"github.com/jedi-knights/ecnl/pkg/dal"
+	"github.com/jedi-knights/ecnl/pkg/models"
+)
+
+type Runner interface {
+	Recent(ctx context.Context, command string, limit int) ([]models.Run, error)
+	ById(ctx context.Context, id string) (*models.Run, error)
+}
+
+type Runs struct {
+	dao dal.RunDAOer
+}
+
+func NewRuns(dao dal.RunDAOer) *Runs {
+	return &Runs{dao: dao}
+}
+
+// Recent returns the most recent runs first, only those of command unless it is empty.
+func (r *Runs) Recent(ctx context.Context, command string, limit int) ([]models.Run, error) {
+	return r.dao.GetRecent(ctx, command, limit)
+}
+
+// ById returns a run.
+func (r *Runs) ById(ctx context.Context, id string) (*models.Run, error) {
+	return r.dao.GetById(ctx, id)
+}
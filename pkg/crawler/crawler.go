@@ -0,0 +1,618 @@
+package crawler
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/jedi-knights/ecnl/pkg"
+	"github.com/jedi-knights/ecnl/pkg/dal"
+	"github.com/jedi-knights/ecnl/pkg/models"
+	"log"
+	"slices"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Sourcer is the subset of the TGS service the crawler reads from.
+type Sourcer interface {
+	Organizations(ctx context.Context, ecnlOnly bool) ([]models.Organization, error)
+	ClubsByOrganization(ctx context.Context, org models.Organization) ([]models.Club, error)
+	EventById(ctx context.Context, eventId int) (*models.Event, error)
+	TeamsByEvent(ctx context.Context, event models.Event) ([]*models.Team, error)
+	MatchEventsByClubIdAndEventId(ctx context.Context, clubId, eventId int) ([]models.MatchEvent, error)
+}
+
+// Syncer persists a batch of entities, the DAOs in the dal package satisfy it.
+type Syncer[T any] interface {
+	SyncAll(ctx context.Context, items []T) (dal.SyncSummary, error)
+}
+
+// Remover marks the records whose key is not in keep as removed upstream.
+// The club, event and team DAOs satisfy it, a Syncer in the Store that also implements it has its stale records marked.
+type Remover interface {
+	MarkRemoved(ctx context.Context, keep []int) (int, error)
+}
+
+// Checkpointer persists the progress of a crawl, the sync state DAO satisfies it.
+// GetByKey returns an error matching pkg.ErrNotFound when there is no checkpoint for the key.
+type Checkpointer interface {
+	GetByKey(ctx context.Context, key string) (*models.SyncState, error)
+	Save(ctx context.Context, state models.SyncState) error
+}
+
+// Store holds the destinations of a crawl.
+// State is optional, without it no checkpoints are recorded and Resume and Since have no effect.
+type Store struct {
+	Organizations Syncer[models.Organization]
+	Clubs         Syncer[models.Club]
+	Events        Syncer[models.Event]
+	Teams         Syncer[*models.Team]
+	Matches       Syncer[models.MatchEvent]
+	State         Checkpointer
+}
+
+// Options controls how a crawl is carried out.
+type Options struct {
+	// Workers is the maximum number of units fetched at the same time.
+	Workers int
+	// Resume continues the last run when it did not finish, skipping the units it already completed.
+	Resume bool
+	// Since skips storing the teams of events and the matches of clubs whose content has not changed since this time.
+	// They are still fetched, only fetching them tells whether they changed.
+	Since time.Time
+	// Scope restricts the crawl to part of the data.
+	Scope Scope
+}
+
+// DefaultOptions returns the options used when none are given.
+func DefaultOptions() Options {
+	return Options{Workers: 8}
+}
+
+// Result summarizes a crawl.
+type Result struct {
+	RunId string
+	// Entities holds the counts of every entity type, keyed by the Entity constants.
+	Entities map[string]models.EntityCounts
+	Errors   []error
+}
+
+// Count returns the counts of an entity type.
+func (r *Result) Count(entity string) models.EntityCounts {
+	return r.Entities[entity]
+}
+
+// Skipped returns the number of units skipped across all entity types.
+func (r *Result) Skipped() int {
+	var skipped int
+
+	for _, counts := range r.Entities {
+		skipped += counts.Skipped
+	}
+
+	return skipped
+}
+
+// Crawler walks the TGS hierarchy of organizations, clubs, events, teams and matches and stores what it finds.
+// Organizations are crawled one after the other, the units within an organization are fetched in parallel
+// by a bounded pool of workers.
+type Crawler struct {
+	source Sourcer
+	store  Store
+	opts   Options
+	runId  string
+
+	mu     sync.Mutex
+	errs   []error
+	counts map[string]*models.EntityCounts
+	events map[int]*models.Event
+	// seen holds the keys of the clubs and teams found upstream, the events are in events.
+	seen struct {
+		clubs, teams []int
+	}
+}
+
+// New creates a crawler that reads from source and writes to store.
+func New(source Sourcer, store Store, opts Options) *Crawler {
+	if opts.Workers < 1 {
+		opts.Workers = DefaultOptions().Workers
+	}
+
+	counts := make(map[string]*models.EntityCounts)
+	for _, entity := range Entities {
+		counts[entity] = &models.EntityCounts{}
+	}
+
+	return &Crawler{source: source, store: store, opts: opts, counts: counts, events: make(map[int]*models.Event)}
+}
+
+// Run performs a crawl.
+// A failing unit does not abort the crawl, the errors are collected in the result and joined into the returned error.
+// The run is only marked as finished when every unit succeeded, so a later run with Resume picks up the rest.
+func (c *Crawler) Run(ctx context.Context) (*Result, error) {
+	var (
+		err           error
+		run           models.SyncState
+		organizations []models.Organization
+	)
+
+	if run, err = c.startRun(ctx); err != nil {
+		return c.result(), err
+	}
+
+	if organizations, err = c.source.Organizations(ctx, c.opts.Scope.ECNLOnly); err != nil {
+		return c.result(), fmt.Errorf("error getting organizations: %w", err)
+	}
+
+	organizations = filter(organizations, func(org models.Organization) bool {
+		return selects(c.opts.Scope.Organizations, org.Id, org.Name)
+	})
+
+	c.count(EntityOrganizations, func(counts *models.EntityCounts) { counts.Fetched += len(organizations) })
+
+	if c.opts.Scope.includes(EntityOrganizations) {
+		c.write(ctx, EntityOrganizations, func(ctx context.Context) (dal.SyncSummary, error) {
+			return c.store.Organizations.SyncAll(ctx, organizations)
+		}, "organizations")
+	}
+
+	if !c.opts.Scope.includes(EntityClubs, EntityEvents, EntityTeams, EntityMatches) {
+		organizations = nil
+	}
+
+	for _, org := range organizations {
+		if ctx.Err() != nil {
+			break
+		}
+
+		c.crawlOrganization(ctx, org)
+	}
+
+	// Units that never started because the crawl was cancelled are not reported individually.
+	if err = ctx.Err(); err != nil {
+		c.report(err)
+	}
+
+	// Only a complete crawl knows every record upstream, anything less would mark the part it missed as removed.
+	if result := c.result(); len(result.Errors) == 0 && result.Skipped() == 0 && c.opts.Scope.IsZero() {
+		c.markRemoved(ctx)
+	}
+
+	result := c.result()
+
+	if len(result.Errors) == 0 {
+		run.FinishedAt = time.Now()
+		c.checkpoint(ctx, run)
+	}
+
+	return result, errors.Join(result.Errors...)
+}
+
+// startRun records the start of a run, or picks up the unfinished run to resume.
+func (c *Crawler) startRun(ctx context.Context) (models.SyncState, error) {
+	now := time.Now()
+	run := models.SyncState{Key: models.SyncRunKey, RunId: strconv.FormatInt(now.UnixNano(), 36), StartedAt: now}
+
+	if c.store.State == nil {
+		c.runId = run.RunId
+		return run, nil
+	}
+
+	if c.opts.Resume {
+		last, err := c.store.State.GetByKey(ctx, models.SyncRunKey)
+
+		switch {
+		case err == nil && !last.Finished():
+			log.Printf("Resuming sync run %s started at %s", last.RunId, last.StartedAt.Format(time.RFC3339))
+			run = *last
+		case err == nil:
+			log.Printf("The last sync run %s finished, starting a new run", last.RunId)
+		case !errors.Is(err, pkg.ErrNotFound):
+			return run, fmt.Errorf("error getting the last sync run: %w", err)
+		}
+	}
+
+	c.runId = run.RunId
+	run.SyncedAt = now
+
+	if err := c.store.State.Save(ctx, run); err != nil {
+		return run, fmt.Errorf("error saving the sync run: %w", err)
+	}
+
+	return run, nil
+}
+
+// crawlOrganization crawls the clubs, events, teams and matches of an organization.
+func (c *Crawler) crawlOrganization(ctx context.Context, org models.Organization) {
+	key := models.OrganizationSyncKey(org.Id)
+
+	if state := c.lastState(ctx, key); c.opts.Resume && state != nil && state.RunId == c.runId {
+		c.count(EntityOrganizations, func(counts *models.EntityCounts) { counts.Skipped++ })
+		return
+	}
+
+	errs := len(c.result().Errors)
+
+	clubs, err := c.source.ClubsByOrganization(ctx, org)
+	if err != nil {
+		c.report(fmt.Errorf("error getting clubs for organization '%s': %w", org.Name, err))
+		return
+	}
+
+	clubs = filter(clubs, func(club models.Club) bool {
+		return selects(c.opts.Scope.Clubs, club.ClubId, club.Name)
+	})
+
+	c.count(EntityClubs, func(counts *models.EntityCounts) { counts.Fetched += len(clubs) })
+
+	// Clubs that are no longer part of the league have no event for the current season,
+	// they are left out so that they end up marked as removed.
+	current := filter(clubs, func(club models.Club) bool {
+		return club.EventId != 0
+	})
+
+	c.mu.Lock()
+	for _, club := range current {
+		c.seen.clubs = append(c.seen.clubs, club.ClubId)
+	}
+	c.mu.Unlock()
+
+	if c.opts.Scope.includes(EntityClubs) {
+		c.write(ctx, EntityClubs, func(ctx context.Context) (dal.SyncSummary, error) { return c.store.Clubs.SyncAll(ctx, current) }, "clubs for organization '%s'", org.Name)
+	}
+
+	if c.opts.Scope.includes(EntityEvents, EntityTeams, EntityMatches) {
+		events, fetched := c.crawlEvents(ctx, current)
+
+		if c.opts.Scope.includes(EntityTeams) {
+			c.crawlTeams(ctx, fetched)
+		}
+
+		if c.opts.Scope.includes(EntityMatches) {
+			c.crawlMatches(ctx, current, events)
+		}
+	}
+
+	// A scoped crawl only covers part of the organization, so it cannot mark it as done.
+	if ctx.Err() == nil && len(c.result().Errors) == errs && c.opts.Scope.IsZero() {
+		now := time.Now()
+		c.checkpoint(ctx, models.SyncState{Key: key, RunId: c.runId, OrgId: org.Id, Count: len(clubs), SyncedAt: now, ChangedAt: now})
+	}
+
+	log.Printf("Done syncing organization '%s'", org.Name)
+}
+
+// crawlEvents fetches every distinct event the clubs take part in exactly once and stores the named ones in scope.
+// Many clubs share an event, so looking the events up per club would repeat the same request over and over.
+// It returns the events of the clubs in scope, including the ones an earlier organization already fetched,
+// and the events fetched for this organization only, whose teams have not been crawled yet.
+func (c *Crawler) crawlEvents(ctx context.Context, clubs []models.Club) (events, fetched []models.Event) {
+	var ids, missing []int
+
+	seen := make(map[int]bool)
+	for _, club := range clubs {
+		if seen[club.EventId] {
+			continue
+		}
+
+		seen[club.EventId] = true
+		ids = append(ids, club.EventId)
+
+		c.mu.Lock()
+		_, known := c.events[club.EventId]
+		c.mu.Unlock()
+
+		if !known {
+			missing = append(missing, club.EventId)
+		}
+	}
+
+	sort.Ints(ids)
+	sort.Ints(missing)
+
+	forEach(ctx, c.opts.Workers, missing, func(ctx context.Context, id int) error {
+		event, err := c.source.EventById(ctx, id)
+		if err != nil {
+			return fmt.Errorf("error getting event %d: %w", id, err)
+		}
+
+		c.mu.Lock()
+		c.events[id] = event
+		c.mu.Unlock()
+
+		return nil
+	}, c.report)
+
+	c.mu.Lock()
+	for _, id := range ids {
+		if event, ok := c.events[id]; ok && event.Name != "" && selects(c.opts.Scope.Events, event.Id, event.Name) {
+			events = append(events, *event)
+
+			if slices.Contains(missing, id) {
+				fetched = append(fetched, *event)
+			}
+		}
+	}
+	c.mu.Unlock()
+
+	c.count(EntityEvents, func(counts *models.EntityCounts) { counts.Fetched += len(fetched) })
+
+	if c.opts.Scope.includes(EntityEvents) {
+		c.write(ctx, EntityEvents, func(ctx context.Context) (dal.SyncSummary, error) { return c.store.Events.SyncAll(ctx, fetched) }, "events")
+	}
+
+	return events, fetched
+}
+
+// crawlTeams fetches and stores the teams of every event.
+func (c *Crawler) crawlTeams(ctx context.Context, events []models.Event) {
+	forEach(ctx, c.opts.Workers, events, func(ctx context.Context, event models.Event) error {
+		key := models.EventSyncKey(event.Id)
+
+		state := c.lastState(ctx, key)
+		if c.skip(state) {
+			c.count(EntityTeams, func(counts *models.EntityCounts) { counts.Skipped++ })
+			return nil
+		}
+
+		teams, err := c.source.TeamsByEvent(ctx, event)
+		if err != nil {
+			return fmt.Errorf("error getting teams for event '%s': %w", event.Name, err)
+		}
+
+		// The fingerprint describes everything upstream returned, the checkpoint tells whether all of it was stored.
+		unit := c.unitState(state, models.SyncState{Key: key, EventId: event.Id, OrgId: event.OrgId, Count: len(teams)}, teams)
+
+		c.mu.Lock()
+		for _, team := range teams {
+			c.seen.teams = append(c.seen.teams, team.Id)
+		}
+		c.mu.Unlock()
+
+		teams = filter(teams, func(team *models.Team) bool {
+			return c.opts.Scope.includesAgeGroup(team.AgeGroup)
+		})
+
+		c.count(EntityTeams, func(counts *models.EntityCounts) { counts.Fetched += len(teams) })
+
+		if c.unchanged(state, unit) {
+			c.count(EntityTeams, func(counts *models.EntityCounts) { counts.Unchanged += len(teams) })
+			return nil
+		}
+
+		if c.write(ctx, EntityTeams, func(ctx context.Context) (dal.SyncSummary, error) { return c.store.Teams.SyncAll(ctx, teams) }, "teams for event '%s'", event.Name) {
+			c.checkpoint(ctx, unit)
+		}
+
+		return nil
+	}, c.report)
+}
+
+// crawlMatches fetches and stores the matches of every club in the event it takes part in.
+func (c *Crawler) crawlMatches(ctx context.Context, clubs []models.Club, events []models.Event) {
+	participants := filter(clubs, func(club models.Club) bool {
+		return slices.ContainsFunc(events, func(event models.Event) bool {
+			return event.Id == club.EventId
+		})
+	})
+
+	forEach(ctx, c.opts.Workers, participants, func(ctx context.Context, club models.Club) error {
+		key := models.ClubEventSyncKey(club.ClubId, club.EventId)
+
+		state := c.lastState(ctx, key)
+		if c.skip(state) {
+			c.count(EntityMatches, func(counts *models.EntityCounts) { counts.Skipped++ })
+			return nil
+		}
+
+		log.Printf("Syncing match results for club '%s' and event %d ...", club.Name, club.EventId)
+
+		matches, err := c.source.MatchEventsByClubIdAndEventId(ctx, club.ClubId, club.EventId)
+		if err != nil {
+			return fmt.Errorf("error getting matches for club '%s' and event %d: %w", club.Name, club.EventId, err)
+		}
+
+		unit := c.unitState(state, models.SyncState{Key: key, ClubId: club.ClubId, EventId: club.EventId, OrgId: club.OrgId, Count: len(matches)}, matches)
+
+		matches = filter(matches, func(match models.MatchEvent) bool {
+			return c.opts.Scope.includesAgeGroup(match.Division)
+		})
+
+		c.count(EntityMatches, func(counts *models.EntityCounts) { counts.Fetched += len(matches) })
+
+		if c.unchanged(state, unit) {
+			c.count(EntityMatches, func(counts *models.EntityCounts) { counts.Unchanged += len(matches) })
+			return nil
+		}
+
+		if c.write(ctx, EntityMatches, func(ctx context.Context) (dal.SyncSummary, error) { return c.store.Matches.SyncAll(ctx, matches) }, "matches for club '%s'", club.Name) {
+			c.checkpoint(ctx, unit)
+		}
+
+		return nil
+	}, c.report)
+}
+
+// lastState returns the checkpoint recorded for key, or nil when there is none.
+func (c *Crawler) lastState(ctx context.Context, key string) *models.SyncState {
+	if c.store.State == nil {
+		return nil
+	}
+
+	state, err := c.store.State.GetByKey(ctx, key)
+	if err != nil {
+		if !errors.Is(err, pkg.ErrNotFound) {
+			c.report(fmt.Errorf("error getting sync state '%s': %w", key, err))
+		}
+
+		return nil
+	}
+
+	return state
+}
+
+// skip returns true when the unit with the given checkpoint does not have to be fetched again,
+// because the resumed run already completed it.
+func (c *Crawler) skip(state *models.SyncState) bool {
+	return state != nil && c.opts.Resume && state.RunId == c.runId
+}
+
+// unchanged returns true when a unit that was fetched again does not have to be stored,
+// because it has the content of its last checkpoint, that checkpoint stored the unit completely
+// and its content has not changed since Options.Since.
+// Its checkpoint is left as is, so the change time keeps counting from the last store.
+func (c *Crawler) unchanged(last *models.SyncState, unit models.SyncState) bool {
+	if last == nil || !last.Complete || c.opts.Since.IsZero() {
+		return false
+	}
+
+	return last.Fingerprint == unit.Fingerprint && last.ChangedAt.Before(c.opts.Since)
+}
+
+// unitState completes the checkpoint of a unit, the change time only moves when the fingerprint of the items differs.
+// A crawl restricted to age groups only stores part of the items, so its checkpoint is not complete.
+func (c *Crawler) unitState(last *models.SyncState, state models.SyncState, items any) models.SyncState {
+	now := time.Now()
+
+	state.RunId = c.runId
+	state.Fingerprint = fingerprint(items)
+	state.Complete = len(c.opts.Scope.AgeGroups) == 0
+	state.SyncedAt = now
+	state.ChangedAt = now
+
+	if last != nil && last.Fingerprint == state.Fingerprint {
+		state.ChangedAt = last.ChangedAt
+	}
+
+	return state
+}
+
+// checkpoint saves a sync state, failures are reported without interrupting the crawl.
+func (c *Crawler) checkpoint(ctx context.Context, state models.SyncState) {
+	if c.store.State == nil {
+		return
+	}
+
+	c.save(ctx, func(ctx context.Context) error { return c.store.State.Save(ctx, state) }, "sync state '%s'", state.Key)
+}
+
+// markRemoved marks the clubs, events and teams that were not found upstream as removed.
+func (c *Crawler) markRemoved(ctx context.Context) {
+	c.mu.Lock()
+	keep := map[string][]int{EntityClubs: c.seen.clubs, EntityTeams: c.seen.teams}
+	for id, event := range c.events {
+		if event.Name != "" {
+			keep[EntityEvents] = append(keep[EntityEvents], id)
+		}
+	}
+	c.mu.Unlock()
+
+	stores := map[string]any{EntityClubs: c.store.Clubs, EntityEvents: c.store.Events, EntityTeams: c.store.Teams}
+
+	for _, entity := range []string{EntityClubs, EntityEvents, EntityTeams} {
+		remover, ok := stores[entity].(Remover)
+
+		// Nothing found upstream is more likely an outage than everything being removed.
+		if !ok || len(keep[entity]) == 0 {
+			continue
+		}
+
+		c.save(ctx, func(ctx context.Context) error {
+			removed, err := remover.MarkRemoved(ctx, keep[entity])
+			c.count(entity, func(counts *models.EntityCounts) { counts.Removed += removed })
+
+			return err
+		}, "the removed %s", entity)
+	}
+}
+
+// count updates the counts of an entity type.
+func (c *Crawler) count(entity string, fn func(counts *models.EntityCounts)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	fn(c.counts[entity])
+}
+
+// write runs a batch write of an entity type and adds its summary to the counts.
+// It returns true when the write succeeded.
+func (c *Crawler) write(ctx context.Context, entity string, fn func(context.Context) (dal.SyncSummary, error), format string, args ...any) bool {
+	return c.save(ctx, func(ctx context.Context) error {
+		summary, err := fn(ctx)
+
+		c.count(entity, func(counts *models.EntityCounts) {
+			counts.Inserted += summary.Inserted
+			counts.Updated += summary.Updated
+			counts.Unchanged += summary.Unchanged
+		})
+
+		return err
+	}, format, args...)
+}
+
+// save runs a store operation and reports its failure without interrupting the crawl.
+// The operation is not cancelled along with ctx, so a cancelled crawl keeps what it fetched.
+// It returns true when the operation succeeded.
+func (c *Crawler) save(ctx context.Context, fn func(context.Context) error, format string, args ...any) bool {
+	if err := fn(context.WithoutCancel(ctx)); err != nil {
+		c.report(fmt.Errorf("error saving "+format+": %w", append(args, err)...))
+		return false
+	}
+
+	return true
+}
+
+// report records an error for the result.
+func (c *Crawler) report(err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.errs = append(c.errs, err)
+}
+
+// result takes a snapshot of the counts and errors gathered so far.
+func (c *Crawler) result() *Result {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entities := make(map[string]models.EntityCounts, len(c.counts))
+	for entity, counts := range c.counts {
+		entities[entity] = *counts
+	}
+
+	return &Result{
+		RunId:    c.runId,
+		Entities: entities,
+		Errors:   append([]error(nil), c.errs...),
+	}
+}
+
+// filter returns the items for which keep returns true.
+func filter[T any](items []T, keep func(T) bool) []T {
+	var kept []T
+
+	for _, item := range items {
+		if keep(item) {
+			kept = append(kept, item)
+		}
+	}
+
+	return kept
+}
+
+// fingerprint returns a digest of the JSON encoding of items, used to tell whether upstream content changed.
+func fingerprint(items any) string {
+	data, err := json.Marshal(items)
+	if err != nil {
+		return ""
+	}
+
+	sum := sha256.Sum256(data)
+
+	return hex.EncodeToString(sum[:])
+}
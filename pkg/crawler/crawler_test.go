@@ -0,0 +1,523 @@
+package crawler_test
+
+import (
+	"context"
+	"errors"
+	"github.com/jedi-knights/ecnl/pkg"
+	"github.com/jedi-knights/ecnl/pkg/crawler"
+	"github.com/jedi-knights/ecnl/pkg/dal"
+	"github.com/jedi-knights/ecnl/pkg/models"
+	"github.com/jedi-knights/ecnl/pkg/services"
+	"github.com/jedi-knights/ecnl/pkg/stub"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// recorder is a crawler.Syncer and crawler.Remover that keeps everything it is given.
+type recorder[T any] struct {
+	mu    sync.Mutex
+	items []T
+	keep  []int
+	err   error
+}
+
+func (r *recorder[T]) SyncAll(_ context.Context, items []T) (dal.SyncSummary, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.err != nil {
+		return dal.SyncSummary{}, r.err
+	}
+
+	r.items = append(r.items, items...)
+
+	return dal.SyncSummary{Inserted: len(items)}, nil
+}
+
+func (r *recorder[T]) MarkRemoved(_ context.Context, keep []int) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.keep = keep
+
+	return 1, nil
+}
+
+// checkpoints is an in memory crawler.Checkpointer.
+type checkpoints struct {
+	mu     sync.Mutex
+	states map[string]models.SyncState
+}
+
+func (c *checkpoints) GetByKey(_ context.Context, key string) (*models.SyncState, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	state, ok := c.states[key]
+	if !ok {
+		return nil, pkg.ErrNotFound
+	}
+
+	return &state, nil
+}
+
+func (c *checkpoints) Save(_ context.Context, state models.SyncState) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.states[state.Key] = state
+
+	return nil
+}
+
+// countingSource counts the event lookups, can fail the match lookups of a club, move a club of the first organization
+// to the second one and correct the matches it returns.
+type countingSource struct {
+	*services.GlobalService
+	eventLookups atomic.Int32
+	matchLookups atomic.Int32
+	failClubId   int
+	movedClubId  int
+	correct      func(match *models.MatchEvent)
+}
+
+func (s *countingSource) ClubsByOrganization(ctx context.Context, org models.Organization) ([]models.Club, error) {
+	if s.movedClubId == 0 {
+		return s.GlobalService.ClubsByOrganization(ctx, org)
+	}
+
+	clubs, err := s.GlobalService.ClubsByOrganizationId(ctx, 12)
+	if err != nil {
+		return nil, err
+	}
+
+	var kept []models.Club
+
+	for _, club := range clubs {
+		if (club.ClubId == s.movedClubId) == (org.Id == 20) {
+			club.OrgId = org.Id
+			kept = append(kept, club)
+		}
+	}
+
+	return kept, nil
+}
+
+func (s *countingSource) EventById(ctx context.Context, eventId int) (*models.Event, error) {
+	s.eventLookups.Add(1)
+
+	return s.GlobalService.EventById(ctx, eventId)
+}
+
+func (s *countingSource) MatchEventsByClubIdAndEventId(ctx context.Context, clubId, eventId int) ([]models.MatchEvent, error) {
+	s.matchLookups.Add(1)
+
+	if clubId == s.failClubId {
+		return nil, errors.New("boom")
+	}
+
+	matches, err := s.GlobalService.MatchEventsByClubIdAndEventId(ctx, clubId, eventId)
+
+	if s.correct != nil {
+		for i := range matches {
+			s.correct(&matches[i])
+		}
+	}
+
+	return matches, err
+}
+
+const eventName = "ECNL Girls Southeast 2023-24"
+
+var _ = Describe("Crawler", func() {
+	var (
+		server  *httptest.Server
+		source  *countingSource
+		orgs    *recorder[models.Organization]
+		clubs   *recorder[models.Club]
+		events  *recorder[models.Event]
+		teams   *recorder[*models.Team]
+		matches *recorder[models.MatchEvent]
+		state   *checkpoints
+		store   crawler.Store
+	)
+
+	BeforeEach(func() {
+		server = httptest.NewServer(stub.NewServer("../../testdata/tgs"))
+
+		svc := services.NewTGSServiceWithClient(server.Client())
+		svc.BaseUrl = server.URL
+		svc.Cache = nil
+
+		source = &countingSource{GlobalService: svc}
+
+		orgs = &recorder[models.Organization]{}
+		clubs = &recorder[models.Club]{}
+		events = &recorder[models.Event]{}
+		teams = &recorder[*models.Team]{}
+		matches = &recorder[models.MatchEvent]{}
+
+		state = &checkpoints{states: make(map[string]models.SyncState)}
+
+		store = crawler.Store{Organizations: orgs, Clubs: clubs, Events: events, Teams: teams, Matches: matches, State: state}
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("should crawl and store every entity", func() {
+		// Arrange
+		c := crawler.New(source, store, crawler.Options{Workers: 4})
+
+		// Act
+		result, err := c.Run(context.Background())
+
+		// Assert
+		Expect(err).NotTo(HaveOccurred())
+		Expect(orgs.items).To(HaveLen(2))
+		// One of the clubs left the league and has no event for the current season.
+		Expect(clubs.items).To(HaveLen(2))
+		Expect(events.items).To(HaveLen(1))
+		Expect(events.items[0].Id).To(Equal(2776))
+		Expect(teams.items).To(HaveLen(4))
+		Expect(matches.items).To(HaveLen(6))
+		Expect(result.Count(crawler.EntityMatches).Fetched).To(Equal(6))
+		Expect(result.Count(crawler.EntityOrganizations).Inserted).To(Equal(2))
+		Expect(result.Count(crawler.EntityClubs).Fetched).To(Equal(3))
+		Expect(result.Count(crawler.EntityClubs).Inserted).To(Equal(2))
+		Expect(result.Count(crawler.EntityEvents).Inserted).To(Equal(1))
+		Expect(result.Count(crawler.EntityTeams).Inserted).To(Equal(4))
+		Expect(result.Count(crawler.EntityMatches).Inserted).To(Equal(6))
+		Expect(result.Errors).To(BeEmpty())
+	})
+
+	It("should mark the clubs, events and teams no longer found upstream as removed", func() {
+		// Arrange
+		c := crawler.New(source, store, crawler.DefaultOptions())
+
+		// Act
+		result, err := c.Run(context.Background())
+
+		// Assert
+		Expect(err).NotTo(HaveOccurred())
+		Expect(clubs.keep).To(ConsistOf(18, 100))
+		Expect(events.keep).To(ConsistOf(2776))
+		Expect(teams.keep).To(HaveLen(4))
+		Expect(orgs.keep).To(BeNil())
+		Expect(result.Count(crawler.EntityClubs).Removed).To(Equal(1))
+	})
+
+	It("should not mark anything as removed after an incomplete crawl", func() {
+		// Arrange
+		source.failClubId = 18
+		c := crawler.New(source, store, crawler.DefaultOptions())
+
+		// Act
+		_, err := c.Run(context.Background())
+
+		// Assert
+		Expect(err).To(HaveOccurred())
+		Expect(clubs.keep).To(BeNil())
+		Expect(teams.keep).To(BeNil())
+	})
+
+	It("should look up an event shared by many clubs only once", func() {
+		// Arrange
+		c := crawler.New(source, store, crawler.DefaultOptions())
+
+		// Act
+		_, err := c.Run(context.Background())
+
+		// Assert
+		Expect(err).NotTo(HaveOccurred())
+		Expect(source.eventLookups.Load()).To(Equal(int32(1)))
+	})
+
+	It("should crawl the matches of the clubs in an event an earlier organization already fetched", func() {
+		// Arrange
+		source.movedClubId = 18
+		c := crawler.New(source, store, crawler.DefaultOptions())
+
+		// Act
+		result, err := c.Run(context.Background())
+
+		// Assert
+		Expect(err).NotTo(HaveOccurred())
+		Expect(source.eventLookups.Load()).To(Equal(int32(1)))
+		Expect(source.matchLookups.Load()).To(Equal(int32(2)))
+		Expect(matches.items).To(HaveLen(6))
+		Expect(events.items).To(HaveLen(1))
+		Expect(teams.items).To(HaveLen(4))
+		Expect(result.Count(crawler.EntityEvents).Fetched).To(Equal(1))
+	})
+
+	It("should keep going and aggregate the errors of failing units", func() {
+		// Arrange
+		source.failClubId = 18
+		teams.err = errors.New("disk full")
+		c := crawler.New(source, store, crawler.DefaultOptions())
+
+		// Act
+		result, err := c.Run(context.Background())
+
+		// Assert
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("boom"))
+		Expect(err.Error()).To(ContainSubstring("disk full"))
+		Expect(result.Errors).To(HaveLen(2))
+		Expect(matches.items).To(HaveLen(3))
+	})
+
+	It("should stop when the context is cancelled", func() {
+		// Arrange
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		c := crawler.New(source, store, crawler.DefaultOptions())
+
+		// Act
+		_, err := c.Run(ctx)
+
+		// Assert
+		Expect(err).To(MatchError(context.Canceled))
+	})
+
+	Describe("Checkpoints", func() {
+		It("should mark the run finished when every unit succeeded", func() {
+			// Arrange
+			c := crawler.New(source, store, crawler.DefaultOptions())
+
+			// Act
+			result, err := c.Run(context.Background())
+
+			// Assert
+			Expect(err).NotTo(HaveOccurred())
+			Expect(state.states[models.SyncRunKey].RunId).To(Equal(result.RunId))
+			Expect(state.states[models.SyncRunKey].Finished()).To(BeTrue())
+			Expect(state.states).To(HaveKey(models.OrganizationSyncKey(12)))
+			Expect(state.states).To(HaveKey(models.EventSyncKey(2776)))
+			Expect(state.states[models.ClubEventSyncKey(100, 2776)].Count).To(Equal(3))
+		})
+
+		It("should resume an unfinished run without repeating completed units", func() {
+			// Arrange
+			source.failClubId = 18
+			first, err := crawler.New(source, store, crawler.DefaultOptions()).Run(context.Background())
+			Expect(err).To(HaveOccurred())
+			Expect(state.states[models.SyncRunKey].Finished()).To(BeFalse())
+
+			source.failClubId = 0
+			source.matchLookups.Store(0)
+
+			// Act
+			second, err := crawler.New(source, store, crawler.Options{Resume: true}).Run(context.Background())
+
+			// Assert
+			Expect(err).NotTo(HaveOccurred())
+			Expect(second.RunId).To(Equal(first.RunId))
+			Expect(source.matchLookups.Load()).To(Equal(int32(1)))
+			// The organization without clubs, the teams of the event and the matches of the first club.
+			Expect(second.Skipped()).To(Equal(3))
+			Expect(state.states[models.SyncRunKey].Finished()).To(BeTrue())
+		})
+
+		It("should start a new run when resuming a finished run", func() {
+			// Arrange
+			first, err := crawler.New(source, store, crawler.DefaultOptions()).Run(context.Background())
+			Expect(err).NotTo(HaveOccurred())
+
+			// Act
+			second, err := crawler.New(source, store, crawler.Options{Resume: true}).Run(context.Background())
+
+			// Assert
+			Expect(err).NotTo(HaveOccurred())
+			Expect(second.RunId).NotTo(Equal(first.RunId))
+			Expect(second.Skipped()).To(BeZero())
+		})
+
+		It("should only store units that changed since the given time", func() {
+			// Arrange
+			_, err := crawler.New(source, store, crawler.DefaultOptions()).Run(context.Background())
+			Expect(err).NotTo(HaveOccurred())
+			source.matchLookups.Store(0)
+			stored := len(matches.items)
+
+			// Act
+			result, err := crawler.New(source, store, crawler.Options{Since: time.Now()}).Run(context.Background())
+
+			// Assert
+			Expect(err).NotTo(HaveOccurred())
+			Expect(source.matchLookups.Load()).To(Equal(int32(2)))
+			Expect(matches.items).To(HaveLen(stored))
+			Expect(result.Count(crawler.EntityMatches).Unchanged).To(Equal(stored))
+			Expect(result.Skipped()).To(BeZero())
+		})
+
+		It("should store the units whose last checkpoint did not store them completely", func() {
+			// Arrange
+			_, err := crawler.New(source, store, crawler.DefaultOptions()).Run(context.Background())
+			Expect(err).NotTo(HaveOccurred())
+
+			key := models.ClubEventSyncKey(100, 2776)
+			incomplete := state.states[key]
+			incomplete.Complete = false
+			state.states[key] = incomplete
+			stored := len(matches.items)
+
+			// Act
+			result, err := crawler.New(source, store, crawler.Options{Since: time.Now()}).Run(context.Background())
+
+			// Assert
+			Expect(err).NotTo(HaveOccurred())
+			Expect(matches.items).To(HaveLen(stored + 3))
+			Expect(result.Count(crawler.EntityMatches).Inserted).To(Equal(3))
+			Expect(state.states[key].Complete).To(BeTrue())
+		})
+
+		It("should store the units that changed upstream after a run that left them unchanged", func() {
+			// Arrange
+			last := func() time.Time { return state.states[models.SyncRunKey].StartedAt }
+
+			_, err := crawler.New(source, store, crawler.DefaultOptions()).Run(context.Background())
+			Expect(err).NotTo(HaveOccurred())
+			_, err = crawler.New(source, store, crawler.Options{Since: last()}).Run(context.Background())
+			Expect(err).NotTo(HaveOccurred())
+
+			source.correct = func(match *models.MatchEvent) { match.HomeTeamScore += 10 }
+			stored, teamsStored := len(matches.items), len(teams.items)
+
+			// Act
+			result, err := crawler.New(source, store, crawler.Options{Since: last()}).Run(context.Background())
+
+			// Assert
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.Count(crawler.EntityMatches).Inserted).To(BeNumerically(">", 0))
+			Expect(matches.items[stored:]).To(HaveEach(HaveField("HomeTeamScore", BeNumerically(">=", 10))))
+			Expect(teams.items).To(HaveLen(teamsStored))
+		})
+
+		It("should keep the change time of units whose content did not change", func() {
+			// Arrange
+			_, err := crawler.New(source, store, crawler.DefaultOptions()).Run(context.Background())
+			Expect(err).NotTo(HaveOccurred())
+			changedAt := state.states[models.ClubEventSyncKey(100, 2776)].ChangedAt
+
+			// Act
+			_, err = crawler.New(source, store, crawler.DefaultOptions()).Run(context.Background())
+
+			// Assert
+			Expect(err).NotTo(HaveOccurred())
+			Expect(state.states[models.ClubEventSyncKey(100, 2776)].ChangedAt).To(Equal(changedAt))
+		})
+	})
+
+	Describe("Scope", func() {
+		It("should only crawl ECNL organizations when asked to", func() {
+			// Arrange
+			c := crawler.New(source, store, crawler.Options{Scope: crawler.Scope{ECNLOnly: true}})
+
+			// Act
+			_, err := c.Run(context.Background())
+
+			// Assert
+			Expect(err).NotTo(HaveOccurred())
+			Expect(orgs.items).To(HaveLen(1))
+			Expect(orgs.items[0].Name).To(Equal("ECNL Girls"))
+		})
+
+		It("should only store the entity types asked for", func() {
+			// Arrange
+			scope := crawler.Scope{Only: []string{crawler.EntityMatches}, AgeGroups: []string{"G2010"}}
+			c := crawler.New(source, store, crawler.Options{Scope: scope})
+
+			// Act
+			result, err := c.Run(context.Background())
+
+			// Assert
+			Expect(err).NotTo(HaveOccurred())
+			Expect(orgs.items).To(BeEmpty())
+			Expect(clubs.items).To(BeEmpty())
+			Expect(events.items).To(BeEmpty())
+			Expect(teams.items).To(BeEmpty())
+			Expect(matches.items).To(HaveLen(2))
+			Expect(matches.items[0].Division).To(Equal("G2010"))
+			Expect(result.Count(crawler.EntityMatches).Fetched).To(Equal(2))
+		})
+
+		It("should select organizations, clubs and events by id or name", func() {
+			// Arrange
+			scope := crawler.Scope{Organizations: []string{"ECNL Girls"}, Clubs: []string{"18"}, Events: []string{eventName}}
+			c := crawler.New(source, store, crawler.Options{Scope: scope})
+
+			// Act
+			_, err := c.Run(context.Background())
+
+			// Assert
+			Expect(err).NotTo(HaveOccurred())
+			Expect(orgs.items).To(HaveLen(1))
+			Expect(clubs.items).To(HaveLen(1))
+			Expect(clubs.items[0].ClubId).To(Equal(18))
+			Expect(events.items).To(HaveLen(1))
+			Expect(source.matchLookups.Load()).To(Equal(int32(1)))
+		})
+
+		It("should filter the teams by age group", func() {
+			// Arrange
+			scope := crawler.Scope{Only: []string{crawler.EntityTeams}, AgeGroups: []string{"g2009"}}
+			c := crawler.New(source, store, crawler.Options{Scope: scope})
+
+			// Act
+			_, err := c.Run(context.Background())
+
+			// Assert
+			Expect(err).NotTo(HaveOccurred())
+			Expect(teams.items).To(HaveLen(2))
+			Expect(source.matchLookups.Load()).To(BeZero())
+		})
+
+		It("should store the age groups a scoped crawl left out in a later crawl since its start", func() {
+			// Arrange
+			scope := crawler.Scope{AgeGroups: []string{"G2010"}}
+			_, err := crawler.New(source, store, crawler.Options{Scope: scope}).Run(context.Background())
+			Expect(err).NotTo(HaveOccurred())
+			Expect(matches.items).To(HaveLen(2))
+			Expect(teams.items).To(HaveLen(2))
+
+			// Act
+			result, err := crawler.New(source, store, crawler.Options{Since: time.Now()}).Run(context.Background())
+
+			// Assert
+			Expect(err).NotTo(HaveOccurred())
+			Expect(matches.items[2:]).To(HaveLen(6))
+			Expect(teams.items[2:]).To(HaveLen(4))
+			Expect(result.Count(crawler.EntityMatches).Unchanged).To(BeZero())
+		})
+
+		It("should not mark organizations as done in a scoped crawl", func() {
+			// Arrange
+			c := crawler.New(source, store, crawler.Options{Scope: crawler.Scope{Only: []string{crawler.EntityClubs}}})
+
+			// Act
+			_, err := c.Run(context.Background())
+
+			// Assert
+			Expect(err).NotTo(HaveOccurred())
+			Expect(state.states).NotTo(HaveKey(models.OrganizationSyncKey(12)))
+		})
+
+		It("should reject unknown entity types", func() {
+			// Arrange
+			scope := crawler.Scope{Only: []string{"players"}}
+
+			// Act
+			err := scope.Validate()
+
+			// Assert
+			Expect(err).To(HaveOccurred())
+		})
+	})
+})
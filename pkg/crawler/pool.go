@@ -0,0 +1,43 @@
+package crawler
+
+import (
+	"context"
+	"sync"
+)
+
+// forEach calls fn for every item using at most workers goroutines.
+// A failing item does not stop the others, every error is handed to report.
+// Items that have not started when the context is done are skipped.
+func forEach[T any](ctx context.Context, workers int, items []T, fn func(context.Context, T) error, report func(error)) {
+	var wg sync.WaitGroup
+
+	if workers < 1 {
+		workers = 1
+	}
+
+	sem := make(chan struct{}, workers)
+
+	for _, item := range items {
+		select {
+		case <-ctx.Done():
+			wg.Wait()
+			return
+		case sem <- struct{}{}:
+		}
+
+		wg.Add(1)
+
+		go func(item T) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
+			if err := fn(ctx, item); err != nil {
+				report(err)
+			}
+		}(item)
+	}
+
+	wg.Wait()
+}
@@ -0,0 +1,84 @@
+package crawler
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// The entity types a crawl can be restricted to.
+const (
+	EntityOrganizations = "orgs"
+	EntityClubs         = "clubs"
+	EntityEvents        = "events"
+	EntityTeams         = "teams"
+	EntityMatches       = "matches"
+)
+
+// Entities lists every entity type in the order they are crawled.
+var Entities = []string{EntityOrganizations, EntityClubs, EntityEvents, EntityTeams, EntityMatches}
+
+// Scope restricts a crawl to part of the TGS data.
+// Organizations, Events and Clubs hold ids or names. Empty fields do not restrict anything.
+type Scope struct {
+	ECNLOnly      bool
+	Organizations []string
+	Events        []string
+	Clubs         []string
+	AgeGroups     []string
+	Only          []string
+}
+
+// Validate returns an error when the scope names an unknown entity type.
+func (s Scope) Validate() error {
+	for _, entity := range s.Only {
+		if !slices.Contains(Entities, entity) {
+			return fmt.Errorf("unknown entity type '%s' expected one of %s", entity, strings.Join(Entities, ","))
+		}
+	}
+
+	return nil
+}
+
+// IsZero returns true when the scope covers everything.
+func (s Scope) IsZero() bool {
+	return !s.ECNLOnly && len(s.Organizations) == 0 && len(s.Events) == 0 && len(s.Clubs) == 0 && len(s.AgeGroups) == 0 && len(s.Only) == 0
+}
+
+// includes returns true when the entity type is part of the scope.
+func (s Scope) includes(entities ...string) bool {
+	if len(s.Only) == 0 {
+		return true
+	}
+
+	for _, entity := range entities {
+		if slices.Contains(s.Only, entity) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// includesAgeGroup returns true when the age group (e.g. G2009) is part of the scope.
+func (s Scope) includesAgeGroup(ageGroup string) bool {
+	return len(s.AgeGroups) == 0 || slices.ContainsFunc(s.AgeGroups, func(value string) bool {
+		return strings.EqualFold(value, ageGroup)
+	})
+}
+
+// selects returns true when values is empty or one of the values is the id or the name.
+func selects(values []string, id int, name string) bool {
+	if len(values) == 0 {
+		return true
+	}
+
+	for _, value := range values {
+		if value == strconv.Itoa(id) || strings.EqualFold(value, name) {
+			return true
+		}
+	}
+
+	return false
+}
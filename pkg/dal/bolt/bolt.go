@@ -0,0 +1,111 @@
+// Package bolt stores the repositories in a single bbolt file, so that ecnl runs without a database server.
+// The collections are loaded into the in memory repositories when the file is opened and every change
+// is written through to the file, one bucket per collection holding the documents as JSON.
+// bbolt locks the file, only one ecnl process at a time can open it.
+package bolt
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"github.com/jedi-knights/ecnl/pkg/dal"
+	"github.com/jedi-knights/ecnl/pkg/dal/memory"
+	"go.etcd.io/bbolt"
+	"time"
+)
+
+// DefaultPath is the file used when none is configured.
+const DefaultPath = "ecnl.db"
+
+// openTimeout is how long Open waits for another process to release the file.
+const openTimeout = 5 * time.Second
+
+// DB is a bbolt file holding the collections.
+type DB struct {
+	db *bbolt.DB
+}
+
+// Open opens the file at path, creating it when it does not exist.
+func Open(path string) (*DB, error) {
+	db, err := bbolt.Open(path, 0o600, &bbolt.Options{Timeout: openTimeout})
+	if errors.Is(err, bbolt.ErrTimeout) {
+		return nil, fmt.Errorf("the store '%s' is in use by another ecnl process", path)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("error opening the store '%s': %w", path, err)
+	}
+
+	return &DB{db: db}, nil
+}
+
+// NewRepositories opens the file at path and loads the repositories from it.
+func NewRepositories(path string) (*dal.Repositories, *DB, error) {
+	var (
+		err   error
+		db    *DB
+		repos *dal.Repositories
+	)
+
+	if db, err = Open(path); err != nil {
+		return nil, nil, err
+	}
+
+	if repos, err = memory.NewPersistentRepositories(db); err != nil {
+		_ = db.Close()
+		return nil, nil, err
+	}
+
+	return repos, db, nil
+}
+
+// Close closes the file.
+func (d *DB) Close() error {
+	return d.db.Close()
+}
+
+// Load calls fn with every document of the collection in the order of their ids.
+func (d *DB) Load(collection string, fn func(id uint64, data []byte) error) error {
+	return d.db.View(func(tx *bbolt.Tx) error {
+		bucket := tx.Bucket([]byte(collection))
+		if bucket == nil {
+			return nil
+		}
+
+		return bucket.ForEach(func(k, v []byte) error {
+			return fn(binary.BigEndian.Uint64(k), v)
+		})
+	})
+}
+
+// Write stores the documents in put and deletes those in del in one transaction.
+func (d *DB) Write(collection string, put map[uint64][]byte, del []uint64) error {
+	return d.db.Update(func(tx *bbolt.Tx) error {
+		bucket, err := tx.CreateBucketIfNotExists([]byte(collection))
+		if err != nil {
+			return err
+		}
+
+		for _, id := range del {
+			if err = bucket.Delete(key(id)); err != nil {
+				return err
+			}
+		}
+
+		for id, data := range put {
+			if err = bucket.Put(key(id), data); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// key encodes an id big endian, so that the documents of a bucket are iterated in the order of their ids.
+func key(id uint64) []byte {
+	k := make([]byte, 8)
+	binary.BigEndian.PutUint64(k, id)
+
+	return k
+}
@@ -0,0 +1,83 @@
+package bolt_test
+
+import (
+	"context"
+	"github.com/jedi-knights/ecnl/pkg/dal"
+	"github.com/jedi-knights/ecnl/pkg/dal/bolt"
+	"github.com/jedi-knights/ecnl/pkg/models"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"path/filepath"
+	"time"
+)
+
+var _ = Describe("Bolt", func() {
+	ctx := context.Background()
+	var path string
+
+	// reopen closes the store and opens it again, as the next command would.
+	reopen := func(db *bolt.DB) *dal.Repositories {
+		Expect(db.Close()).To(Succeed())
+
+		repos, db, err := bolt.NewRepositories(path)
+		Expect(err).NotTo(HaveOccurred())
+		DeferCleanup(db.Close)
+
+		return repos
+	}
+
+	BeforeEach(func() {
+		path = filepath.Join(GinkgoT().TempDir(), "ecnl.db")
+	})
+
+	It("should keep what was written across opens", func() {
+		// Arrange
+		repos, db, err := bolt.NewRepositories(path)
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = repos.Matches.SyncAll(ctx, []models.MatchEvent{
+			{MatchId: 1, HomeTeamId: 10, AwayTeamId: 11, Flight: "ECNL", Division: "G2009"},
+			{MatchId: 2, HomeTeamId: 11, AwayTeamId: 12, Flight: "ECNL RL", Division: "G2009"},
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(repos.Clubs.Create(ctx, models.Club{ClubId: 7, Name: "Alpha"})).To(Succeed())
+		Expect(repos.Clubs.DeleteById(ctx, 7)).To(Succeed())
+
+		// Act
+		repos = reopen(db)
+
+		// Assert
+		matches, err := repos.Matches.GetECNLByAgeGroup(ctx, "G2009")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(matches).To(HaveLen(1))
+
+		matches, err = repos.Matches.GetByTeamId(ctx, 11)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(matches).To(HaveLen(2))
+
+		clubs, err := repos.Clubs.GetAll(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(clubs).To(BeEmpty())
+	})
+
+	It("should find synced records unchanged after they were read back", func() {
+		// Arrange
+		removedAt := time.Now()
+		teams := []*models.Team{{Id: 1, Name: "Alpha G09"}, {Id: 2, Name: "Bravo G09", RemovedAt: &removedAt}}
+
+		repos, db, err := bolt.NewRepositories(path)
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = repos.Teams.SyncAll(ctx, teams)
+		Expect(err).NotTo(HaveOccurred())
+
+		repos = reopen(db)
+
+		// Act
+		summary, err := repos.Teams.SyncAll(ctx, teams)
+
+		// Assert
+		Expect(err).NotTo(HaveOccurred())
+		Expect(summary).To(Equal(dal.SyncSummary{Unchanged: 2}))
+	})
+})
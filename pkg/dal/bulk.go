@@ -0,0 +1,62 @@
+package dal
+
+import (
+	"context"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// bulkSize is the maximum number of writes sent to the server in a single bulk write.
+const bulkSize = 1000
+
+// SyncSummary counts the outcome of syncing a batch of documents.
+type SyncSummary struct {
+	Inserted  int
+	Updated   int
+	Unchanged int
+}
+
+// Add adds the counts of other to the summary.
+func (s *SyncSummary) Add(other SyncSummary) {
+	s.Inserted += other.Inserted
+	s.Updated += other.Updated
+	s.Unchanged += other.Unchanged
+}
+
+// Total returns the number of documents the summary covers.
+func (s SyncSummary) Total() int {
+	return s.Inserted + s.Updated + s.Unchanged
+}
+
+// bulkUpsert replaces the document matching the natural key of every item, inserting the ones that do not exist yet.
+// The writes are sent in unordered batches of bulkSize, a document whose content did not change counts as unchanged.
+func bulkUpsert[T any](ctx context.Context, col *mongo.Collection, items []T, key func(T) bson.M) (SyncSummary, error) {
+	var summary SyncSummary
+
+	for start := 0; start < len(items); start += bulkSize {
+		var (
+			err    error
+			result *mongo.BulkWriteResult
+		)
+
+		batch := items[start:min(start+bulkSize, len(items))]
+		writes := make([]mongo.WriteModel, 0, len(batch))
+
+		for _, item := range batch {
+			writes = append(writes, mongo.NewReplaceOneModel().SetFilter(key(item)).SetReplacement(item).SetUpsert(true))
+		}
+
+		if result, err = col.BulkWrite(ctx, writes, options.BulkWrite().SetOrdered(false)); err != nil {
+			return summary, err
+		}
+
+		summary.Add(SyncSummary{
+			Inserted:  int(result.UpsertedCount),
+			Updated:   int(result.ModifiedCount),
+			Unchanged: int(result.MatchedCount - result.ModifiedCount),
+		})
+	}
+
+	return summary, nil
+}
@@ -2,41 +2,81 @@ package dal
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"github.com/jedi-knights/ecnl/pkg"
 	"github.com/spf13/viper"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
-	"log"
 )
 
-// MustGetClient gets a mongo client.
-// If the client cannot be created, the program will exit.
-func MustGetClient(ctx context.Context) *mongo.Client {
+// DefaultDatabase is the name of the database unless mongo.database says otherwise.
+const DefaultDatabase = "ecnl"
+
+// Store owns the pooled client of a mongo database and the repositories that read and write it.
+// It is shared by the whole process and closed on shutdown.
+type Store struct {
+	client       *mongo.Client
+	database     *mongo.Database
+	repositories *Repositories
+}
+
+// NewStore connects to the server at uri and checks that it answers.
+func NewStore(ctx context.Context, uri, database string) (*Store, error) {
 	var (
 		err    error
 		client *mongo.Client
 	)
 
-	connectionString := viper.Get("mongo.uri").(string)
-
-	clientOptions := options.Client().ApplyURI(connectionString)
+	if uri == "" {
+		return nil, errors.New("the mongo uri is not set")
+	}
 
-	// connect to the database
-	if client, err = mongo.Connect(ctx, clientOptions); err != nil {
-		log.Fatal(err)
+	if database == "" {
+		database = DefaultDatabase
 	}
 
-	// Note: defer is not needed here because the client is used throughout the program.
-	//defer func(client *mongo.Client, ctx context.Context) {
-	//	err := client.Disconnect(ctx)
-	//	if err != nil {
-	//		log.Fatal(err)
-	//	}
-	//}(client, ctx)
+	if client, err = mongo.Connect(ctx, options.Client().ApplyURI(uri)); err != nil {
+		return nil, fmt.Errorf("error connecting to mongo: %w", err)
+	}
 
-	// Ping the database
 	if err = client.Ping(ctx, nil); err != nil {
-		log.Fatal(err)
+		_ = client.Disconnect(context.Background())
+
+		return nil, fmt.Errorf("error reaching mongo: %w", err)
+	}
+
+	db := client.Database(database)
+
+	return &Store{client: client, database: db, repositories: NewMongoRepositories(db)}, nil
+}
+
+// NewStoreFromConfig connects to the server at mongo.uri and uses the database named by mongo.database.
+func NewStoreFromConfig(ctx context.Context) (*Store, error) {
+	return NewStore(ctx, viper.GetString("mongo.uri"), viper.GetString("mongo.database"))
+}
+
+// Database returns the database of the store.
+func (s *Store) Database() *mongo.Database {
+	return s.database
+}
+
+// Repositories returns the repositories backed by the collections of the database.
+func (s *Store) Repositories() *Repositories {
+	return s.repositories
+}
+
+// Close disconnects the client, waiting for the operations in flight until ctx is done.
+func (s *Store) Close(ctx context.Context) error {
+	return s.client.Disconnect(ctx)
+}
+
+// notFound translates the driver's no documents error into pkg.ErrNotFound, described by format and args.
+// Any other error is returned unchanged.
+func notFound(err error, format string, args ...any) error {
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return fmt.Errorf(format+": %w", append(args, pkg.ErrNotFound)...)
 	}
 
-	return client
+	return err
 }
@@ -0,0 +1,97 @@
+package dal
+
+import (
+	"context"
+	"github.com/jedi-knights/ecnl/pkg/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"log"
+	"time"
+)
+
+type LeaseDAOer interface {
+	Index(ctx context.Context) error
+	GetAll(ctx context.Context) ([]models.Lease, error)
+	Acquire(ctx context.Context, name, holder string, ttl time.Duration) (bool, error)
+	Release(ctx context.Context, name, holder string) error
+}
+
+// LeaseDAO is the data access object for the leases of the scheduled jobs.
+type LeaseDAO struct {
+	col *mongo.Collection
+}
+
+// NewLeaseDAO creates a new lease data access object.
+func NewLeaseDAO(col *mongo.Collection) *LeaseDAO {
+	return &LeaseDAO{col: col}
+}
+
+// Index indexes the collection.
+func (dao *LeaseDAO) Index(ctx context.Context) error {
+	var (
+		err  error
+		name string
+	)
+
+	indexModel := mongo.IndexModel{
+		Keys:    bson.D{{Key: "name", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	}
+
+	if name, err = dao.col.Indexes().CreateOne(ctx, indexModel); err != nil {
+		return err
+	}
+
+	log.Printf("created index %s on leases collection", name)
+
+	return nil
+}
+
+// GetAll gets all leases, including expired ones.
+func (dao *LeaseDAO) GetAll(ctx context.Context) ([]models.Lease, error) {
+	var leases []models.Lease
+
+	cursor, err := dao.col.Find(ctx, bson.M{})
+	if err != nil {
+		return nil, err
+	}
+
+	if err = cursor.All(ctx, &leases); err != nil {
+		return nil, err
+	}
+
+	return leases, nil
+}
+
+// Acquire takes the lease on name when it is free or expired, or extends it when holder already has it.
+// It returns false when another holder has the lease.
+func (dao *LeaseDAO) Acquire(ctx context.Context, name, holder string, ttl time.Duration) (bool, error) {
+	now := time.Now()
+
+	filter := bson.M{
+		"name": name,
+		"$or": bson.A{
+			bson.M{"holder": holder},
+			bson.M{"expiresat": bson.M{"$lte": now}},
+		},
+	}
+
+	update := bson.M{"$set": bson.M{"holder": holder, "renewedat": now, "expiresat": now.Add(ttl)}}
+
+	// When the lease is held by someone else the filter matches nothing and the upsert
+	// collides with the unique index on name.
+	_, err := dao.col.UpdateOne(ctx, filter, update, options.Update().SetUpsert(true))
+	if mongo.IsDuplicateKeyError(err) {
+		return false, nil
+	}
+
+	return err == nil, err
+}
+
+// Release gives up the lease on name if holder has it.
+func (dao *LeaseDAO) Release(ctx context.Context, name, holder string) error {
+	_, err := dao.col.DeleteOne(ctx, bson.M{"name": name, "holder": holder})
+
+	return err
+}
@@ -0,0 +1,57 @@
+package dal
+
+import (
+	"errors"
+	"strings"
+)
+
+// ListOptions filters, orders and pages the documents of a listing.
+type ListOptions struct {
+	// Filter matches the stored fields exactly, e.g. {"division": "G2009"}.
+	Filter map[string]any
+	// Sort orders by the stored fields, a leading - orders descending, e.g. "-gamedate".
+	// The documents are in storage order unless it is set.
+	Sort []string
+	// Offset skips the first documents and Limit caps their number, zero does not.
+	Offset int
+	Limit  int
+}
+
+// Validate checks that the offset and limit are not negative.
+func (o ListOptions) Validate() error {
+	if o.Offset < 0 || o.Limit < 0 {
+		return errors.New("offset and limit must not be negative")
+	}
+
+	return nil
+}
+
+// Next returns the options of the page that follows a page of the listing.
+func (o ListOptions) Next() ListOptions {
+	o.Offset += o.Limit
+
+	return o
+}
+
+// SortField splits a sort field into its name and whether it orders descending.
+func SortField(field string) (string, bool) {
+	if name, ok := strings.CutPrefix(field, "-"); ok {
+		return name, true
+	}
+
+	return field, false
+}
+
+// Page is a page of a listing.
+type Page[T any] struct {
+	Items []T `json:"items"`
+	// Total counts the documents of the whole listing.
+	Total  int `json:"total"`
+	Offset int `json:"offset"`
+	Limit  int `json:"limit"`
+}
+
+// HasMore returns true when there are documents after the page.
+func (p Page[T]) HasMore() bool {
+	return p.Offset+len(p.Items) < p.Total
+}
@@ -2,506 +2,248 @@ package dal
 
 import (
 	"context"
-	"fmt"
 	"github.com/jedi-knights/ecnl/pkg/models"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 	"log"
+	"time"
 )
 
 type MatchEventDAOer interface {
-	Index() error
-	GetAll() ([]models.MatchEvent, error)
-	GetById(id int) (*models.MatchEvent, error)
-	GetByDivision(division string) ([]models.MatchEvent, error)
-	GetByHomeTeamName(teamName string) ([]models.MatchEvent, error)
-	GetByAwayTeamName(teamName string) ([]models.MatchEvent, error)
-	GetByTeamName(teamName string) ([]models.MatchEvent, error)
-	GetByHomeTeamId(teamId int) ([]models.MatchEvent, error)
-	GetByAwayTeamId(teamId int) ([]models.MatchEvent, error)
-	GetByTeamId(teamId int) ([]models.MatchEvent, error)
-	Update(matchEvent models.MatchEvent) error
-	Delete(matchEvent models.MatchEvent) error
-	DeleteById(id int) error
-	Create(matchEvent models.MatchEvent) error
-	Exists(matchEvent models.MatchEvent) (bool, error)
-	ExistsById(id int) (bool, error)
-	Sync(matchEvent models.MatchEvent) error
-	SyncAll(matchEvents []models.MatchEvent) error
+	Index(ctx context.Context) error
+	GetAll(ctx context.Context) ([]models.MatchEvent, error)
+	Each(ctx context.Context, opts ListOptions, fn func(models.MatchEvent) error) error
+	List(ctx context.Context, opts ListOptions) (Page[models.MatchEvent], error)
+	GetById(ctx context.Context, id int) (*models.MatchEvent, error)
+	GetByDivision(ctx context.Context, division string) ([]models.MatchEvent, error)
+	GetByHomeTeamName(ctx context.Context, teamName string) ([]models.MatchEvent, error)
+	GetByAwayTeamName(ctx context.Context, teamName string) ([]models.MatchEvent, error)
+	GetByTeamName(ctx context.Context, teamName string) ([]models.MatchEvent, error)
+	GetByHomeTeamId(ctx context.Context, teamId int) ([]models.MatchEvent, error)
+	GetByAwayTeamId(ctx context.Context, teamId int) ([]models.MatchEvent, error)
+	GetByTeamId(ctx context.Context, teamId int) ([]models.MatchEvent, error)
+	GetECNLByAgeGroup(ctx context.Context, ageGroup string) ([]models.MatchEvent, error)
+	Update(ctx context.Context, matchEvent models.MatchEvent) error
+	Delete(ctx context.Context, matchEvent models.MatchEvent) error
+	DeleteById(ctx context.Context, id int) error
+	Create(ctx context.Context, matchEvent models.MatchEvent) error
+	Exists(ctx context.Context, matchEvent models.MatchEvent) (bool, error)
+	ExistsById(ctx context.Context, id int) (bool, error)
+	Sync(ctx context.Context, matchEvent models.MatchEvent) error
+	SyncAll(ctx context.Context, matchEvents []models.MatchEvent) (SyncSummary, error)
+	WithoutHistory() MatchEventDAOer
+}
+
+// matchEventDescriptor describes how match events are stored, keyed on their matchid.
+var matchEventDescriptor = Descriptor[models.MatchEvent, int]{
+	Entity:     "match event",
+	Collection: "matches",
+	KeyField:   "matchid",
+	Key:        func(matchEvent models.MatchEvent) int { return matchEvent.MatchId },
+	Indexes: []mongo.IndexModel{
+		{Keys: bson.D{{Key: "matchid", Value: 1}}, Options: options.Index().SetUnique(true)},
+		// Serves the queries by division as well as the ECNL ones by flight and division.
+		{Keys: bson.D{{Key: "division", Value: 1}, {Key: "flight", Value: 1}}},
+		{Keys: bson.D{{Key: "hometeamid", Value: 1}}},
+		{Keys: bson.D{{Key: "awayteamid", Value: 1}}},
+		{Keys: bson.D{{Key: "hometeamname", Value: 1}}},
+		{Keys: bson.D{{Key: "awayteamname", Value: 1}}},
+	},
 }
 
 // MatchEventDAO is the data access object for match events.
 type MatchEventDAO struct {
-	ctx context.Context
-	col *mongo.Collection
+	*Repository[models.MatchEvent, int]
+	history MatchHistoryDAOer
 }
 
 // NewMatchEventDAO creates a new match event data access object.
-func NewMatchEventDAO(ctx context.Context, col *mongo.Collection) *MatchEventDAO {
-	return &MatchEventDAO{ctx: ctx, col: col}
+func NewMatchEventDAO(col *mongo.Collection) *MatchEventDAO {
+	return &MatchEventDAO{Repository: NewRepository(col, matchEventDescriptor)}
 }
 
-// Index indexes the collection.
-func (dao *MatchEventDAO) Index() error {
-	var (
-		err  error
-		name string
-	)
-
-	matchEventsIndexModel := mongo.IndexModel{
-		Keys: bson.D{{"matchid", 1}, {"hometeamname", 1}, {"awayteamname", 1}, {"division", 1}},
-	}
-
-	if name, err = dao.col.Indexes().CreateOne(dao.ctx, matchEventsIndexModel); err != nil {
-		return err
-	}
-
-	log.Printf("created index %s on match events collection", name)
-
-	return nil
+// NewMatchEventDAOWithHistory creates a new match event data access object that records
+// the changes it finds in existing match events when they are synced.
+func NewMatchEventDAOWithHistory(col *mongo.Collection, history MatchHistoryDAOer) *MatchEventDAO {
+	return &MatchEventDAO{Repository: NewRepository(col, matchEventDescriptor), history: history}
 }
 
-// GetAll gets all match events.
-func (dao *MatchEventDAO) GetAll() ([]models.MatchEvent, error) {
-	cursor, err := dao.col.Find(dao.ctx, bson.M{})
-	if err != nil {
-		return nil, err
-	}
-
-	var bMatchEvents []bson.M
-	if err = cursor.All(dao.ctx, &bMatchEvents); err != nil {
-		return nil, err
-	}
-
-	var matchEvents []models.MatchEvent
+// WithoutHistory returns a copy of the data access object that does not record the changes it syncs.
+func (dao *MatchEventDAO) WithoutHistory() MatchEventDAOer {
+	quiet := *dao
+	quiet.history = nil
 
-	for _, bMatchEvent := range bMatchEvents {
-		var matchEvent models.MatchEvent
-
-		bsonbytes, _ := bson.Marshal(bMatchEvent)
-
-		if err = bson.Unmarshal(bsonbytes, &matchEvent); err != nil {
-			return nil, err
-		}
-
-		matchEvents = append(matchEvents, matchEvent)
-	}
-
-	return matchEvents, nil
+	return &quiet
 }
 
 // GetById gets a match event by id.
-func (dao *MatchEventDAO) GetById(id int) (*models.MatchEvent, error) {
-	var (
-		err         error
-		matchEvents bson.M
-	)
-
-	if err = dao.col.FindOne(dao.ctx, bson.M{"matchid": id}).Decode(&matchEvents); err != nil {
-		if err.Error() == "mongo: no documents in result" {
-			return nil, fmt.Errorf("match event not found")
-		}
-
-		return nil, err
-	}
-
-	var matchEvent models.MatchEvent
-
-	bsonBytes, _ := bson.Marshal(matchEvents)
-	if err = bson.Unmarshal(bsonBytes, &matchEvent); err != nil {
-		return nil, err
-	}
-
-	return &matchEvent, nil
+func (dao *MatchEventDAO) GetById(ctx context.Context, id int) (*models.MatchEvent, error) {
+	return dao.GetByKey(ctx, id)
 }
 
 // GetByDivision gets match events by division.
-func (dao *MatchEventDAO) GetByDivision(division string) ([]models.MatchEvent, error) {
-	var (
-		err error
-	)
-
-	cursor, err := dao.col.Find(dao.ctx, bson.M{"division": division})
-	if err != nil {
-		return nil, err
-	}
-
-	var bMatchEvents []bson.M
-
-	if err = cursor.All(dao.ctx, &bMatchEvents); err != nil {
-		return nil, err
-	}
-
-	var matchEvents []models.MatchEvent
-
-	for _, bMatchEvent := range bMatchEvents {
-		var matchEvent models.MatchEvent
-
-		bsonBytes, _ := bson.Marshal(bMatchEvent)
-		if err = bson.Unmarshal(bsonBytes, &matchEvent); err != nil {
-			return nil, err
-		}
-
-		matchEvents = append(matchEvents, matchEvent)
-	}
-
-	return matchEvents, nil
+func (dao *MatchEventDAO) GetByDivision(ctx context.Context, division string) ([]models.MatchEvent, error) {
+	return dao.Find(ctx, bson.M{"division": division})
 }
 
 // GetByHomeTeamName gets match events by home team name.
-func (dao *MatchEventDAO) GetByHomeTeamName(teamName string) ([]models.MatchEvent, error) {
-	var (
-		err error
-	)
-
-	cursor, err := dao.col.Find(dao.ctx, bson.M{"hometeamname": teamName})
-	if err != nil {
-		return nil, err
-	}
-
-	var bMatchEvents []bson.M
-
-	if err = cursor.All(dao.ctx, &bMatchEvents); err != nil {
-		return nil, err
-	}
-
-	var matchEvents []models.MatchEvent
-
-	for _, bMatchEvent := range bMatchEvents {
-		var matchEvent models.MatchEvent
-
-		bsonBytes, _ := bson.Marshal(bMatchEvent)
-		if err = bson.Unmarshal(bsonBytes, &matchEvent); err != nil {
-			return nil, err
-		}
-
-		matchEvents = append(matchEvents, matchEvent)
-	}
-
-	return matchEvents, nil
+func (dao *MatchEventDAO) GetByHomeTeamName(ctx context.Context, teamName string) ([]models.MatchEvent, error) {
+	return dao.Find(ctx, bson.M{"hometeamname": teamName})
 }
 
 // GetByAwayTeamName gets match events by away team name.
-func (dao *MatchEventDAO) GetByAwayTeamName(teamName string) ([]models.MatchEvent, error) {
-	var (
-		err error
-	)
-
-	cursor, err := dao.col.Find(dao.ctx, bson.M{"awayteamname": teamName})
-	if err != nil {
-		return nil, err
-	}
-
-	var bMatchEvents []bson.M
-
-	if err = cursor.All(dao.ctx, &bMatchEvents); err != nil {
-		return nil, err
-	}
-
-	var matchEvents []models.MatchEvent
-
-	for _, bMatchEvent := range bMatchEvents {
-		var matchEvent models.MatchEvent
-
-		bsonBytes, _ := bson.Marshal(bMatchEvent)
-		if err = bson.Unmarshal(bsonBytes, &matchEvent); err != nil {
-			return nil, err
-		}
-
-		matchEvents = append(matchEvents, matchEvent)
-	}
-
-	return matchEvents, nil
+func (dao *MatchEventDAO) GetByAwayTeamName(ctx context.Context, teamName string) ([]models.MatchEvent, error) {
+	return dao.Find(ctx, bson.M{"awayteamname": teamName})
 }
 
-// GetByTeamName gets match events by team name.
-// This one is a little trickey because we have to check both home and away team names.
-func (dao *MatchEventDAO) GetByTeamName(teamName string) ([]models.MatchEvent, error) {
+// GetByTeamName gets match events by team name, the home matches first.
+func (dao *MatchEventDAO) GetByTeamName(ctx context.Context, teamName string) ([]models.MatchEvent, error) {
 	var (
 		err        error
 		homeEvents []models.MatchEvent
 		awayEvents []models.MatchEvent
 	)
 
-	if homeEvents, err = dao.GetByHomeTeamName(teamName); err != nil {
+	if homeEvents, err = dao.GetByHomeTeamName(ctx, teamName); err != nil {
 		return nil, err
 	}
 
-	if awayEvents, err = dao.GetByAwayTeamName(teamName); err != nil {
+	if awayEvents, err = dao.GetByAwayTeamName(ctx, teamName); err != nil {
 		return nil, err
 	}
 
-	events := append(homeEvents, awayEvents...)
-
-	return events, nil
+	return append(homeEvents, awayEvents...), nil
 }
 
 // GetByHomeTeamId gets match events by home team id.
-func (dao *MatchEventDAO) GetByHomeTeamId(teamId int) ([]models.MatchEvent, error) {
-	var (
-		err error
-	)
-
-	cursor, err := dao.col.Find(dao.ctx, bson.M{"hometeamid": teamId})
-	if err != nil {
-		return nil, err
-	}
-
-	var bMatchEvents []bson.M
-
-	if err = cursor.All(dao.ctx, &bMatchEvents); err != nil {
-		return nil, err
-	}
-
-	var matchEvents []models.MatchEvent
-
-	for _, bMatchEvent := range bMatchEvents {
-		var matchEvent models.MatchEvent
-
-		bsonBytes, _ := bson.Marshal(bMatchEvent)
-		if err = bson.Unmarshal(bsonBytes, &matchEvent); err != nil {
-			return nil, err
-		}
-
-		matchEvents = append(matchEvents, matchEvent)
-	}
-
-	return matchEvents, nil
+func (dao *MatchEventDAO) GetByHomeTeamId(ctx context.Context, teamId int) ([]models.MatchEvent, error) {
+	return dao.Find(ctx, bson.M{"hometeamid": teamId})
 }
 
 // GetByAwayTeamId gets match events by away team id.
-func (dao *MatchEventDAO) GetByAwayTeamId(teamId int) ([]models.MatchEvent, error) {
-	var (
-		err error
-	)
-
-	cursor, err := dao.col.Find(dao.ctx, bson.M{"awayteamid": teamId})
-	if err != nil {
-		return nil, err
-	}
-
-	var bMatchEvents []bson.M
-
-	if err = cursor.All(dao.ctx, &bMatchEvents); err != nil {
-		return nil, err
-	}
-
-	var matchEvents []models.MatchEvent
-
-	for _, bMatchEvent := range bMatchEvents {
-		var matchEvent models.MatchEvent
-
-		bsonBytes, _ := bson.Marshal(bMatchEvent)
-		if err = bson.Unmarshal(bsonBytes, &matchEvent); err != nil {
-			return nil, err
-		}
-
-		matchEvents = append(matchEvents, matchEvent)
-	}
-
-	return matchEvents, nil
+func (dao *MatchEventDAO) GetByAwayTeamId(ctx context.Context, teamId int) ([]models.MatchEvent, error) {
+	return dao.Find(ctx, bson.M{"awayteamid": teamId})
 }
 
-// GetECNLByAgeGroup gets ECNL match events by age group.
-func (dao *MatchEventDAO) GetECNLByAgeGroup(ageGroup string) ([]models.MatchEvent, error) {
-	var (
-		err error
-	)
-
-	cursor, err := dao.col.Find(dao.ctx, bson.M{"flight": "ECNL", "division": ageGroup})
-	if err != nil {
-		return nil, err
-	}
-
-	var bMatchEvents []bson.M
-
-	if err = cursor.All(dao.ctx, &bMatchEvents); err != nil {
-		return nil, err
-	}
-
-	var matchEvents []models.MatchEvent
-
-	for _, bMatchEvent := range bMatchEvents {
-		var matchEvent models.MatchEvent
-
-		bsonBytes, _ := bson.Marshal(bMatchEvent)
-		if err = bson.Unmarshal(bsonBytes, &matchEvent); err != nil {
-			return nil, err
-		}
-
-		matchEvents = append(matchEvents, matchEvent)
-	}
-
-	return matchEvents, nil
-}
-
-// GetByTeamId gets match events by team id.
-func (dao *MatchEventDAO) GetByTeamId(teamId int) ([]models.MatchEvent, error) {
+// GetByTeamId gets match events by team id, the home matches first.
+func (dao *MatchEventDAO) GetByTeamId(ctx context.Context, teamId int) ([]models.MatchEvent, error) {
 	var (
 		err        error
 		homeEvents []models.MatchEvent
 		awayEvents []models.MatchEvent
 	)
 
-	if homeEvents, err = dao.GetByHomeTeamId(teamId); err != nil {
+	if homeEvents, err = dao.GetByHomeTeamId(ctx, teamId); err != nil {
 		return nil, err
 	}
 
-	if awayEvents, err = dao.GetByAwayTeamId(teamId); err != nil {
+	if awayEvents, err = dao.GetByAwayTeamId(ctx, teamId); err != nil {
 		return nil, err
 	}
 
-	events := append(homeEvents, awayEvents...)
-
-	return events, nil
+	return append(homeEvents, awayEvents...), nil
 }
 
-// Update updates a match event.
-func (dao *MatchEventDAO) Update(matchEvent models.MatchEvent) error {
-	var (
-		err          error
-		updateResult *mongo.UpdateResult
-	)
-
-	log.Println("updating match event: ", matchEvent.String())
-
-	if updateResult, err = dao.col.UpdateOne(dao.ctx, bson.M{"matchid": matchEvent.MatchId}, bson.M{"$set": matchEvent}); err != nil {
-		return err
-	}
-
-	// Check to see if the update was successful.
-	if updateResult.MatchedCount != 1 {
-		return fmt.Errorf("update failed")
-	}
-
-	// The update was successful.
-	log.Printf("match event update successful: %v", updateResult)
-
-	return nil
+// GetECNLByAgeGroup gets ECNL match events by age group.
+func (dao *MatchEventDAO) GetECNLByAgeGroup(ctx context.Context, ageGroup string) ([]models.MatchEvent, error) {
+	return dao.Find(ctx, bson.M{"flight": "ECNL", "division": ageGroup})
 }
 
 // Delete deletes a match event.
-func (dao *MatchEventDAO) Delete(matchEvent models.MatchEvent) error {
-	return dao.DeleteById(matchEvent.MatchId)
+func (dao *MatchEventDAO) Delete(ctx context.Context, matchEvent models.MatchEvent) error {
+	return dao.DeleteByKey(ctx, matchEvent.MatchId)
 }
 
 // DeleteById deletes a match event by id.
-func (dao *MatchEventDAO) DeleteById(id int) error {
-	var (
-		err          error
-		deleteResult *mongo.DeleteResult
-	)
-
-	if deleteResult, err = dao.col.DeleteOne(dao.ctx, bson.M{"matchid": id}); err != nil {
-		return err
-	}
-
-	// Check to see if the delete was successful.
-	if deleteResult.DeletedCount != 1 {
-		return fmt.Errorf("delete failed")
-	}
-
-	// The delete was successful.
-	log.Printf("match event delete successful: %v", deleteResult)
+func (dao *MatchEventDAO) DeleteById(ctx context.Context, id int) error {
+	return dao.DeleteByKey(ctx, id)
+}
 
-	return nil
+// Exists checks if a match event between the same teams on the same date exists.
+func (dao *MatchEventDAO) Exists(ctx context.Context, matchEvent models.MatchEvent) (bool, error) {
+	return dao.exists(ctx, bson.M{"hometeamname": matchEvent.HomeTeamName, "awayteamname": matchEvent.AwayTeamName, "gamedate": matchEvent.GameDate})
 }
 
-// Create creates a match event.
-func (dao *MatchEventDAO) Create(matchEvent models.MatchEvent) error {
-	var (
-		err error
-	)
+// ExistsById checks if a match event exists by id.
+func (dao *MatchEventDAO) ExistsById(ctx context.Context, id int) (bool, error) {
+	return dao.ExistsByKey(ctx, id)
+}
 
-	if _, err = dao.col.InsertOne(dao.ctx, matchEvent); err != nil {
-		return err
-	}
+// Sync creates or replaces the match event.
+func (dao *MatchEventDAO) Sync(ctx context.Context, matchEvent models.MatchEvent) error {
+	_, err := dao.SyncAll(ctx, []models.MatchEvent{matchEvent})
 
-	return nil
+	return err
 }
 
-// Exists checks if a match event exists.
-func (dao *MatchEventDAO) Exists(matchEvent models.MatchEvent) (bool, error) {
+// SyncAll creates or replaces the match events in bulk, keyed on their matchid.
+// When the DAO has a history, the changes to existing match events (e.g. corrected scores) are recorded in it.
+func (dao *MatchEventDAO) SyncAll(ctx context.Context, matchEvents []models.MatchEvent) (SyncSummary, error) {
 	var (
-		err         error
-		bMatchEvent bson.M
+		err       error
+		summary   SyncSummary
+		histories []models.MatchHistory
 	)
 
-	if err = dao.col.FindOne(dao.ctx, bson.M{"hometeamname": matchEvent.HomeTeamName, "awayteamname": matchEvent.AwayTeamName, "gamedate": matchEvent.GameDate}).Decode(&bMatchEvent); err != nil {
-		if err.Error() == "mongo: no documents in result" {
-			return false, nil
+	if dao.history != nil {
+		if histories, err = dao.changes(ctx, matchEvents); err != nil {
+			return summary, err
 		}
+	}
 
-		return false, err
+	if summary, err = dao.Repository.SyncAll(ctx, matchEvents); err != nil {
+		return summary, err
 	}
 
-	return true, nil
-}
+	if len(histories) > 0 {
+		log.Printf("recording changes to %d match events", len(histories))
 
-// ExistsById checks if a match event exists by id.
-func (dao *MatchEventDAO) ExistsById(id int) (bool, error) {
-	var (
-		err         error
-		matchEvents bson.M
-	)
-
-	if err = dao.col.FindOne(dao.ctx, bson.M{"matchid": id}).Decode(&matchEvents); err != nil {
-		if err.Error() == "mongo: no documents in result" {
-			return false, nil
+		if err = dao.history.CreateAll(ctx, histories); err != nil {
+			return summary, err
 		}
-
-		return false, err
 	}
 
-	return true, nil
+	return summary, nil
 }
 
-// Sync syncs a match event.
-func (dao *MatchEventDAO) Sync(matchEvent models.MatchEvent) error {
+// changes compares the match events with the stored versions and returns a history record for each one that changed.
+func (dao *MatchEventDAO) changes(ctx context.Context, matchEvents []models.MatchEvent) ([]models.MatchHistory, error) {
 	var (
-		err    error
-		answer bool
+		err       error
+		stored    []models.MatchEvent
+		histories []models.MatchHistory
 	)
 
-	// log.Printf("syncing match event %v", matchEvent.String())
-
-	if answer, err = dao.Exists(matchEvent); err != nil {
-		return err
+	ids := make([]int, 0, len(matchEvents))
+	for _, matchEvent := range matchEvents {
+		ids = append(ids, matchEvent.MatchId)
 	}
 
-	if !answer {
-		// The match event doesn't exist.
-		log.Printf("inserting match event '%d' ...", matchEvent.MatchId)
-		if _, err = dao.col.InsertOne(dao.ctx, matchEvent); err != nil {
-			return err
-		}
-
-		// log.Printf("match event insert successful: %v", matchEvent)
-		return nil
+	if stored, err = dao.Find(ctx, bson.M{"matchid": bson.M{"$in": ids}}); err != nil {
+		return nil, err
 	}
 
-	// The match event exists.
-	// The code below has been commented out for performance.
-	// It is unlikely that a match is going to change so we don't need to update it if we already have it.
-	// log.Printf("skipping match event '%s'", matchEvent.String())
-
-	//log.Printf("updating match event '%s' ...", matchEvent.MatchId)
-	//if err = dao.Update(matchEvent); err != nil {
-	//	return err
-	//}
-	//
-	//log.Printf("match event update successful: %v", matchEvent)
+	byId := make(map[int]models.MatchEvent, len(stored))
+	for _, matchEvent := range stored {
+		byId[matchEvent.MatchId] = matchEvent
+	}
 
-	return nil
-}
+	now := time.Now()
 
-// SyncAll syncs all match events.
-func (dao *MatchEventDAO) SyncAll(matchEvents []models.MatchEvent) error {
 	for _, matchEvent := range matchEvents {
-		if err := dao.Sync(matchEvent); err != nil {
-			return err
+		previous, ok := byId[matchEvent.MatchId]
+		if !ok {
+			continue
 		}
+
+		if history := models.NewMatchHistory(previous, matchEvent, now); history != nil {
+			histories = append(histories, *history)
+		}
+
+		// A match listed twice in the batch is only compared against what is stored once.
+		byId[matchEvent.MatchId] = matchEvent
 	}
 
-	return nil
+	return histories, nil
 }
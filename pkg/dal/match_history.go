@@ -0,0 +1,56 @@
+package dal
+
+import (
+	"context"
+	"github.com/jedi-knights/ecnl/pkg/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"time"
+)
+
+type MatchHistoryDAOer interface {
+	Index(ctx context.Context) error
+	GetRecent(ctx context.Context, since time.Time, limit int) ([]models.MatchHistory, error)
+	GetByMatchId(ctx context.Context, id int) ([]models.MatchHistory, error)
+	CreateAll(ctx context.Context, histories []models.MatchHistory) error
+}
+
+// matchHistoryDescriptor describes how the change history of match events is stored.
+// A match has a record for every change, so the matchid does not identify a record on its own.
+var matchHistoryDescriptor = Descriptor[models.MatchHistory, int]{
+	Entity:     "match history",
+	Collection: "match_history",
+	KeyField:   "matchid",
+	Key:        func(history models.MatchHistory) int { return history.MatchId },
+	Filter: func(history models.MatchHistory) bson.M {
+		return bson.M{"matchid": history.MatchId, "changedat": history.ChangedAt}
+	},
+	Indexes: []mongo.IndexModel{
+		{Keys: bson.D{{Key: "changedat", Value: -1}}},
+		{Keys: bson.D{{Key: "matchid", Value: 1}, {Key: "changedat", Value: -1}}},
+	},
+}
+
+// MatchHistoryDAO is the data access object for the change history of match events.
+type MatchHistoryDAO struct {
+	*Repository[models.MatchHistory, int]
+}
+
+// NewMatchHistoryDAO creates a new match history data access object.
+func NewMatchHistoryDAO(col *mongo.Collection) *MatchHistoryDAO {
+	return &MatchHistoryDAO{NewRepository(col, matchHistoryDescriptor)}
+}
+
+// GetRecent gets the most recent changes first, limited to those made at or after since.
+// A limit of zero returns every change.
+func (dao *MatchHistoryDAO) GetRecent(ctx context.Context, since time.Time, limit int) ([]models.MatchHistory, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "changedat", Value: -1}}).SetLimit(int64(limit))
+
+	return dao.Find(ctx, bson.M{"changedat": bson.M{"$gte": since}}, opts)
+}
+
+// GetByMatchId gets the changes of a match, the most recent first.
+func (dao *MatchHistoryDAO) GetByMatchId(ctx context.Context, id int) ([]models.MatchHistory, error) {
+	return dao.Find(ctx, bson.M{"matchid": id}, options.Find().SetSort(bson.D{{Key: "changedat", Value: -1}}))
+}
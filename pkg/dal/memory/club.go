@@ -0,0 +1,141 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"github.com/jedi-knights/ecnl/pkg"
+	"github.com/jedi-knights/ecnl/pkg/dal"
+	"github.com/jedi-knights/ecnl/pkg/models"
+	"time"
+)
+
+// ClubDAO is an in memory dal.ClubDAOer.
+type ClubDAO struct {
+	table       *table[models.Club]
+	withRemoved bool
+}
+
+// NewClubDAO creates an empty in memory club data access object.
+func NewClubDAO() *ClubDAO {
+	return &ClubDAO{table: newTable("clubs", func(club models.Club) any { return club.ClubId })}
+}
+
+func (dao *ClubDAO) WithRemoved() dal.ClubDAOer {
+	removed := *dao
+	removed.withRemoved = true
+
+	return &removed
+}
+
+func (dao *ClubDAO) Index(ctx context.Context) error {
+	return nil
+}
+
+func (dao *ClubDAO) GetAll(ctx context.Context) ([]models.Club, error) {
+	return dao.table.find(dao.active(all[models.Club])), nil
+}
+
+func (dao *ClubDAO) Each(ctx context.Context, opts dal.ListOptions, fn func(models.Club) error) error {
+	return each(ctx, dao.table, dao.active(all[models.Club]), opts, fn)
+}
+
+func (dao *ClubDAO) List(ctx context.Context, opts dal.ListOptions) (dal.Page[models.Club], error) {
+	return list(dao.table, dao.active(all[models.Club]), opts)
+}
+
+func (dao *ClubDAO) GetById(ctx context.Context, id int) (*models.Club, error) {
+	if club, ok := dao.table.first(dao.active(byClubId(id))); ok {
+		return club, nil
+	}
+
+	return nil, fmt.Errorf("club id %d: %w", id, pkg.ErrNotFound)
+}
+
+func (dao *ClubDAO) GetByName(ctx context.Context, name string) (*models.Club, error) {
+	if club, ok := dao.table.first(dao.active(byClubName(name))); ok {
+		return club, nil
+	}
+
+	return nil, fmt.Errorf("club name '%s': %w", name, pkg.ErrNotFound)
+}
+
+func (dao *ClubDAO) Update(ctx context.Context, club models.Club) error {
+	if replaced, err := dao.table.replace(byClubId(club.ClubId), club); err != nil || !replaced {
+		return notFound(err, "update club id %d", club.ClubId)
+	}
+
+	return nil
+}
+
+func (dao *ClubDAO) Delete(ctx context.Context, club models.Club) error {
+	return dao.DeleteById(ctx, club.ClubId)
+}
+
+func (dao *ClubDAO) DeleteById(ctx context.Context, id int) error {
+	if removed, err := dao.table.remove(byClubId(id), 1); err != nil || removed != 1 {
+		return notFound(err, "delete club id %d", id)
+	}
+
+	return nil
+}
+
+func (dao *ClubDAO) DeleteByName(ctx context.Context, name string) error {
+	if removed, err := dao.table.remove(byClubName(name), 1); err != nil || removed != 1 {
+		return notFound(err, "delete club name '%s'", name)
+	}
+
+	return nil
+}
+
+func (dao *ClubDAO) Create(ctx context.Context, club models.Club) error {
+	return dao.table.insert(club)
+}
+
+func (dao *ClubDAO) Exists(ctx context.Context, club models.Club) (bool, error) {
+	return dao.ExistsById(ctx, club.ClubId)
+}
+
+func (dao *ClubDAO) ExistsById(ctx context.Context, id int) (bool, error) {
+	return dao.table.count(dao.active(byClubId(id))) > 0, nil
+}
+
+func (dao *ClubDAO) ExistsByName(ctx context.Context, name string) (bool, error) {
+	return dao.table.count(dao.active(byClubName(name))) > 0, nil
+}
+
+func (dao *ClubDAO) Sync(ctx context.Context, club models.Club) error {
+	_, err := dao.SyncAll(ctx, []models.Club{club})
+
+	return err
+}
+
+func (dao *ClubDAO) MarkRemoved(ctx context.Context, keep []int) (int, error) {
+	now := time.Now()
+	kept := set(keep)
+
+	return dao.table.update(func(club models.Club) bool {
+		return club.RemovedAt == nil && !kept[club.ClubId]
+	}, func(club *models.Club) bool {
+		club.RemovedAt = &now
+		return true
+	})
+}
+
+func (dao *ClubDAO) SyncAll(ctx context.Context, clubs []models.Club) (dal.SyncSummary, error) {
+	return dao.table.upsert(clubs)
+}
+
+// active restricts match to the clubs that were not removed upstream, unless the data access object includes them.
+func (dao *ClubDAO) active(match func(models.Club) bool) func(models.Club) bool {
+	return func(club models.Club) bool {
+		return (dao.withRemoved || club.RemovedAt == nil) && match(club)
+	}
+}
+
+func byClubId(id int) func(models.Club) bool {
+	return func(club models.Club) bool { return club.ClubId == id }
+}
+
+func byClubName(name string) func(models.Club) bool {
+	return func(club models.Club) bool { return club.Name == name }
+}
@@ -0,0 +1,141 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"github.com/jedi-knights/ecnl/pkg"
+	"github.com/jedi-knights/ecnl/pkg/dal"
+	"github.com/jedi-knights/ecnl/pkg/models"
+	"time"
+)
+
+// EventDAO is an in memory dal.EventDAOer.
+type EventDAO struct {
+	table       *table[models.Event]
+	withRemoved bool
+}
+
+// NewEventDAO creates an empty in memory event data access object.
+func NewEventDAO() *EventDAO {
+	return &EventDAO{table: newTable("events", func(event models.Event) any { return event.Id })}
+}
+
+func (dao *EventDAO) WithRemoved() dal.EventDAOer {
+	removed := *dao
+	removed.withRemoved = true
+
+	return &removed
+}
+
+func (dao *EventDAO) Index(ctx context.Context) error {
+	return nil
+}
+
+func (dao *EventDAO) GetAll(ctx context.Context) ([]models.Event, error) {
+	return dao.table.find(dao.active(all[models.Event])), nil
+}
+
+func (dao *EventDAO) Each(ctx context.Context, opts dal.ListOptions, fn func(models.Event) error) error {
+	return each(ctx, dao.table, dao.active(all[models.Event]), opts, fn)
+}
+
+func (dao *EventDAO) List(ctx context.Context, opts dal.ListOptions) (dal.Page[models.Event], error) {
+	return list(dao.table, dao.active(all[models.Event]), opts)
+}
+
+func (dao *EventDAO) GetById(ctx context.Context, id int) (*models.Event, error) {
+	if event, ok := dao.table.first(dao.active(byEventId(id))); ok {
+		return event, nil
+	}
+
+	return nil, fmt.Errorf("event id %d: %w", id, pkg.ErrNotFound)
+}
+
+func (dao *EventDAO) GetByName(ctx context.Context, name string) (*models.Event, error) {
+	if event, ok := dao.table.first(dao.active(byEventName(name))); ok {
+		return event, nil
+	}
+
+	return nil, fmt.Errorf("event name '%s': %w", name, pkg.ErrNotFound)
+}
+
+func (dao *EventDAO) Update(ctx context.Context, event models.Event) error {
+	if replaced, err := dao.table.replace(byEventId(event.Id), event); err != nil || !replaced {
+		return notFound(err, "update event id %d", event.Id)
+	}
+
+	return nil
+}
+
+func (dao *EventDAO) Delete(ctx context.Context, event models.Event) error {
+	return dao.DeleteById(ctx, event.Id)
+}
+
+func (dao *EventDAO) DeleteById(ctx context.Context, id int) error {
+	if removed, err := dao.table.remove(byEventId(id), 1); err != nil || removed != 1 {
+		return notFound(err, "delete event id %d", id)
+	}
+
+	return nil
+}
+
+func (dao *EventDAO) DeleteByName(ctx context.Context, name string) error {
+	if removed, err := dao.table.remove(byEventName(name), 1); err != nil || removed != 1 {
+		return notFound(err, "delete event name '%s'", name)
+	}
+
+	return nil
+}
+
+func (dao *EventDAO) Create(ctx context.Context, event models.Event) error {
+	return dao.table.insert(event)
+}
+
+func (dao *EventDAO) Exists(ctx context.Context, event models.Event) (bool, error) {
+	return dao.ExistsById(ctx, event.Id)
+}
+
+func (dao *EventDAO) ExistsById(ctx context.Context, id int) (bool, error) {
+	return dao.table.count(dao.active(byEventId(id))) > 0, nil
+}
+
+func (dao *EventDAO) ExistsByName(ctx context.Context, name string) (bool, error) {
+	return dao.table.count(dao.active(byEventName(name))) > 0, nil
+}
+
+func (dao *EventDAO) Sync(ctx context.Context, event models.Event) error {
+	_, err := dao.SyncAll(ctx, []models.Event{event})
+
+	return err
+}
+
+func (dao *EventDAO) MarkRemoved(ctx context.Context, keep []int) (int, error) {
+	now := time.Now()
+	kept := set(keep)
+
+	return dao.table.update(func(event models.Event) bool {
+		return event.RemovedAt == nil && !kept[event.Id]
+	}, func(event *models.Event) bool {
+		event.RemovedAt = &now
+		return true
+	})
+}
+
+func (dao *EventDAO) SyncAll(ctx context.Context, events []models.Event) (dal.SyncSummary, error) {
+	return dao.table.upsert(events)
+}
+
+// active restricts match to the events that were not removed upstream, unless the data access object includes them.
+func (dao *EventDAO) active(match func(models.Event) bool) func(models.Event) bool {
+	return func(event models.Event) bool {
+		return (dao.withRemoved || event.RemovedAt == nil) && match(event)
+	}
+}
+
+func byEventId(id int) func(models.Event) bool {
+	return func(event models.Event) bool { return event.Id == id }
+}
+
+func byEventName(name string) func(models.Event) bool {
+	return func(event models.Event) bool { return event.Name == name }
+}
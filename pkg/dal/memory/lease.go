@@ -0,0 +1,53 @@
+package memory
+
+import (
+	"context"
+	"github.com/jedi-knights/ecnl/pkg/models"
+	"sync"
+	"time"
+)
+
+// LeaseDAO is an in memory dal.LeaseDAOer, it only keeps jobs from overlapping within a process.
+type LeaseDAO struct {
+	mu    sync.Mutex
+	table *table[models.Lease]
+}
+
+// NewLeaseDAO creates an empty in memory lease data access object.
+func NewLeaseDAO() *LeaseDAO {
+	return &LeaseDAO{table: newTable("leases", func(lease models.Lease) any { return lease.Name })}
+}
+
+func (dao *LeaseDAO) Index(ctx context.Context) error {
+	return nil
+}
+
+func (dao *LeaseDAO) GetAll(ctx context.Context) ([]models.Lease, error) {
+	return dao.table.find(all[models.Lease]), nil
+}
+
+func (dao *LeaseDAO) Acquire(ctx context.Context, name, holder string, ttl time.Duration) (bool, error) {
+	dao.mu.Lock()
+	defer dao.mu.Unlock()
+
+	now := time.Now()
+
+	if lease, ok := dao.table.get(name); ok && lease.Holder != holder && lease.ExpiresAt.After(now) {
+		return false, nil
+	}
+
+	if _, err := dao.table.upsert([]models.Lease{{Name: name, Holder: holder, RenewedAt: now, ExpiresAt: now.Add(ttl)}}); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (dao *LeaseDAO) Release(ctx context.Context, name, holder string) error {
+	dao.mu.Lock()
+	defer dao.mu.Unlock()
+
+	_, err := dao.table.remove(func(lease models.Lease) bool { return lease.Name == name && lease.Holder == holder }, 1)
+
+	return err
+}
@@ -0,0 +1,192 @@
+package memory
+
+import (
+	"context"
+	"github.com/jedi-knights/ecnl/pkg/dal"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"sort"
+	"time"
+)
+
+// each calls fn with the records of the listing that match, one at a time, until ctx is done.
+func each[T any](ctx context.Context, t *table[T], match func(T) bool, opts dal.ListOptions, fn func(T) error) error {
+	page, err := list(t, match, opts)
+	if err != nil {
+		return err
+	}
+
+	for _, item := range page.Items {
+		if err = ctx.Err(); err != nil {
+			return err
+		}
+
+		if err = fn(item); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// list gets a page of the records of the listing that match.
+// Filter and Sort name the fields the records are stored under in mongo, the bson ones.
+func list[T any](t *table[T], match func(T) bool, opts dal.ListOptions) (dal.Page[T], error) {
+	page := dal.Page[T]{Offset: opts.Offset, Limit: opts.Limit}
+
+	if err := opts.Validate(); err != nil {
+		return page, err
+	}
+
+	var (
+		items    []T
+		document []bson.M
+	)
+
+	for _, item := range t.find(match) {
+		fields := fieldsOf(item)
+
+		if matches(fields, opts.Filter) {
+			items = append(items, item)
+			document = append(document, fields)
+		}
+	}
+
+	if len(opts.Sort) > 0 {
+		order := make([]int, len(items))
+		for i := range order {
+			order[i] = i
+		}
+
+		sort.SliceStable(order, func(i, j int) bool {
+			return less(document[order[i]], document[order[j]], opts.Sort)
+		})
+
+		sorted := make([]T, len(items))
+		for i, k := range order {
+			sorted[i] = items[k]
+		}
+
+		items = sorted
+	}
+
+	page.Total = len(items)
+
+	if opts.Offset >= len(items) {
+		return page, nil
+	}
+
+	items = items[opts.Offset:]
+
+	if opts.Limit > 0 && opts.Limit < len(items) {
+		items = items[:opts.Limit]
+	}
+
+	page.Items = items
+
+	return page, nil
+}
+
+// fieldsOf returns the fields of item by the names it is stored under.
+func fieldsOf(item any) bson.M {
+	var fields bson.M
+
+	if data, err := bson.Marshal(item); err == nil {
+		_ = bson.Unmarshal(data, &fields)
+	}
+
+	return fields
+}
+
+func matches(fields bson.M, filter map[string]any) bool {
+	for field, value := range filter {
+		if compare(fields[field], value) != 0 {
+			return false
+		}
+	}
+
+	return true
+}
+
+func less(a, b bson.M, sortFields []string) bool {
+	for _, field := range sortFields {
+		name, descending := dal.SortField(field)
+
+		c := compare(a[name], b[name])
+		if descending {
+			c = -c
+		}
+
+		if c != 0 {
+			return c < 0
+		}
+	}
+
+	return false
+}
+
+// compare orders two values the way they compare in mongo for the types the models use,
+// numbers by value whatever their type and missing values first.
+func compare(a, b any) int {
+	a, b = normalize(a), normalize(b)
+
+	switch x := a.(type) {
+	case nil:
+		if b == nil {
+			return 0
+		}
+
+		return -1
+	case float64:
+		if y, ok := b.(float64); ok {
+			return order(x < y, x > y)
+		}
+	case string:
+		if y, ok := b.(string); ok {
+			return order(x < y, x > y)
+		}
+	case bool:
+		if y, ok := b.(bool); ok {
+			return order(!x && y, x && !y)
+		}
+	}
+
+	// A missing value comes first and values of different types are never equal.
+	return 1
+}
+
+func order(less, greater bool) int {
+	switch {
+	case less:
+		return -1
+	case greater:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func normalize(v any) any {
+	switch x := v.(type) {
+	case int:
+		return float64(x)
+	case int32:
+		return float64(x)
+	case int64:
+		return float64(x)
+	case float32:
+		return float64(x)
+	case time.Time:
+		return float64(x.UnixMilli())
+	case *time.Time:
+		if x == nil {
+			return nil
+		}
+
+		return float64(x.UnixMilli())
+	case primitive.DateTime:
+		return float64(x)
+	default:
+		return v
+	}
+}
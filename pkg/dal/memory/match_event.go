@@ -0,0 +1,188 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"github.com/jedi-knights/ecnl/pkg"
+	"github.com/jedi-knights/ecnl/pkg/dal"
+	"github.com/jedi-knights/ecnl/pkg/models"
+	"time"
+)
+
+// MatchEventDAO is an in memory dal.MatchEventDAOer.
+type MatchEventDAO struct {
+	table   *table[models.MatchEvent]
+	history dal.MatchHistoryDAOer
+}
+
+// NewMatchEventDAO creates an empty in memory match event data access object.
+func NewMatchEventDAO() *MatchEventDAO {
+	return &MatchEventDAO{table: newTable("matches", func(matchEvent models.MatchEvent) any { return matchEvent.MatchId })}
+}
+
+// NewMatchEventDAOWithHistory creates an empty in memory match event data access object that records the changes synced to match events in history.
+func NewMatchEventDAOWithHistory(history dal.MatchHistoryDAOer) *MatchEventDAO {
+	dao := NewMatchEventDAO()
+	dao.history = history
+
+	return dao
+}
+
+func (dao *MatchEventDAO) WithoutHistory() dal.MatchEventDAOer {
+	quiet := *dao
+	quiet.history = nil
+
+	return &quiet
+}
+
+func (dao *MatchEventDAO) Index(ctx context.Context) error {
+	return nil
+}
+
+func (dao *MatchEventDAO) GetAll(ctx context.Context) ([]models.MatchEvent, error) {
+	return dao.table.find(all[models.MatchEvent]), nil
+}
+
+func (dao *MatchEventDAO) Each(ctx context.Context, opts dal.ListOptions, fn func(models.MatchEvent) error) error {
+	return each(ctx, dao.table, all[models.MatchEvent], opts, fn)
+}
+
+func (dao *MatchEventDAO) List(ctx context.Context, opts dal.ListOptions) (dal.Page[models.MatchEvent], error) {
+	return list(dao.table, all[models.MatchEvent], opts)
+}
+
+func (dao *MatchEventDAO) GetById(ctx context.Context, id int) (*models.MatchEvent, error) {
+	if matchEvent, ok := dao.table.get(id); ok {
+		return matchEvent, nil
+	}
+
+	return nil, fmt.Errorf("match event id %d: %w", id, pkg.ErrNotFound)
+}
+
+func (dao *MatchEventDAO) GetByDivision(ctx context.Context, division string) ([]models.MatchEvent, error) {
+	return dao.table.find(func(matchEvent models.MatchEvent) bool { return matchEvent.Division == division }), nil
+}
+
+func (dao *MatchEventDAO) GetByHomeTeamName(ctx context.Context, teamName string) ([]models.MatchEvent, error) {
+	return dao.table.find(func(matchEvent models.MatchEvent) bool { return matchEvent.HomeTeamName == teamName }), nil
+}
+
+func (dao *MatchEventDAO) GetByAwayTeamName(ctx context.Context, teamName string) ([]models.MatchEvent, error) {
+	return dao.table.find(func(matchEvent models.MatchEvent) bool { return matchEvent.AwayTeamName == teamName }), nil
+}
+
+func (dao *MatchEventDAO) GetByTeamName(ctx context.Context, teamName string) ([]models.MatchEvent, error) {
+	homeEvents, _ := dao.GetByHomeTeamName(ctx, teamName)
+	awayEvents, _ := dao.GetByAwayTeamName(ctx, teamName)
+
+	return append(homeEvents, awayEvents...), nil
+}
+
+func (dao *MatchEventDAO) GetByHomeTeamId(ctx context.Context, teamId int) ([]models.MatchEvent, error) {
+	return dao.table.find(func(matchEvent models.MatchEvent) bool { return matchEvent.HomeTeamId == teamId }), nil
+}
+
+func (dao *MatchEventDAO) GetByAwayTeamId(ctx context.Context, teamId int) ([]models.MatchEvent, error) {
+	return dao.table.find(func(matchEvent models.MatchEvent) bool { return matchEvent.AwayTeamId == teamId }), nil
+}
+
+func (dao *MatchEventDAO) GetByTeamId(ctx context.Context, teamId int) ([]models.MatchEvent, error) {
+	homeEvents, _ := dao.GetByHomeTeamId(ctx, teamId)
+	awayEvents, _ := dao.GetByAwayTeamId(ctx, teamId)
+
+	return append(homeEvents, awayEvents...), nil
+}
+
+func (dao *MatchEventDAO) GetECNLByAgeGroup(ctx context.Context, ageGroup string) ([]models.MatchEvent, error) {
+	return dao.table.find(func(matchEvent models.MatchEvent) bool {
+		return matchEvent.Flight == "ECNL" && matchEvent.Division == ageGroup
+	}), nil
+}
+
+func (dao *MatchEventDAO) Update(ctx context.Context, matchEvent models.MatchEvent) error {
+	if replaced, err := dao.table.replace(byMatchId(matchEvent.MatchId), matchEvent); err != nil || !replaced {
+		return notFound(err, "update match event id %d", matchEvent.MatchId)
+	}
+
+	return nil
+}
+
+func (dao *MatchEventDAO) Delete(ctx context.Context, matchEvent models.MatchEvent) error {
+	return dao.DeleteById(ctx, matchEvent.MatchId)
+}
+
+func (dao *MatchEventDAO) DeleteById(ctx context.Context, id int) error {
+	if removed, err := dao.table.remove(byMatchId(id), 1); err != nil || removed != 1 {
+		return notFound(err, "delete match event id %d", id)
+	}
+
+	return nil
+}
+
+func (dao *MatchEventDAO) Create(ctx context.Context, matchEvent models.MatchEvent) error {
+	return dao.table.insert(matchEvent)
+}
+
+func (dao *MatchEventDAO) Exists(ctx context.Context, matchEvent models.MatchEvent) (bool, error) {
+	return dao.table.count(func(stored models.MatchEvent) bool {
+		return stored.HomeTeamName == matchEvent.HomeTeamName && stored.AwayTeamName == matchEvent.AwayTeamName && stored.GameDate == matchEvent.GameDate
+	}) > 0, nil
+}
+
+func (dao *MatchEventDAO) ExistsById(ctx context.Context, id int) (bool, error) {
+	_, ok := dao.table.get(id)
+
+	return ok, nil
+}
+
+func (dao *MatchEventDAO) Sync(ctx context.Context, matchEvent models.MatchEvent) error {
+	_, err := dao.SyncAll(ctx, []models.MatchEvent{matchEvent})
+
+	return err
+}
+
+func (dao *MatchEventDAO) SyncAll(ctx context.Context, matchEvents []models.MatchEvent) (dal.SyncSummary, error) {
+	var histories []models.MatchHistory
+
+	now := time.Now()
+
+	if dao.history != nil {
+		byId := make(map[int]models.MatchEvent)
+
+		for _, matchEvent := range matchEvents {
+			previous, ok := byId[matchEvent.MatchId]
+			if !ok {
+				stored, found := dao.table.get(matchEvent.MatchId)
+				if !found {
+					continue
+				}
+
+				previous = *stored
+			}
+
+			if history := models.NewMatchHistory(previous, matchEvent, now); history != nil {
+				histories = append(histories, *history)
+			}
+
+			// Like the mongo DAO, a match listed twice in the batch is only compared against what is stored once.
+			byId[matchEvent.MatchId] = matchEvent
+		}
+	}
+
+	summary, err := dao.table.upsert(matchEvents)
+	if err != nil {
+		return summary, err
+	}
+
+	if len(histories) > 0 {
+		if err = dao.history.CreateAll(ctx, histories); err != nil {
+			return summary, err
+		}
+	}
+
+	return summary, nil
+}
+
+func byMatchId(id int) func(models.MatchEvent) bool {
+	return func(matchEvent models.MatchEvent) bool { return matchEvent.MatchId == id }
+}
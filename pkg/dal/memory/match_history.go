@@ -0,0 +1,56 @@
+package memory
+
+import (
+	"context"
+	"github.com/jedi-knights/ecnl/pkg/models"
+	"sort"
+	"time"
+)
+
+type matchHistoryKey struct {
+	matchId   int
+	changedAt time.Time
+}
+
+// MatchHistoryDAO is an in memory dal.MatchHistoryDAOer.
+type MatchHistoryDAO struct {
+	table *table[models.MatchHistory]
+}
+
+// NewMatchHistoryDAO creates an empty in memory match history data access object.
+func NewMatchHistoryDAO() *MatchHistoryDAO {
+	return &MatchHistoryDAO{table: newTable("match_history", func(history models.MatchHistory) any {
+		return matchHistoryKey{matchId: history.MatchId, changedAt: history.ChangedAt}
+	})}
+}
+
+func (dao *MatchHistoryDAO) Index(ctx context.Context) error {
+	return nil
+}
+
+func (dao *MatchHistoryDAO) GetRecent(ctx context.Context, since time.Time, limit int) ([]models.MatchHistory, error) {
+	histories := newestFirst(dao.table.find(func(history models.MatchHistory) bool {
+		return !history.ChangedAt.Before(since)
+	}))
+
+	if limit > 0 && len(histories) > limit {
+		histories = histories[:limit]
+	}
+
+	return histories, nil
+}
+
+func (dao *MatchHistoryDAO) GetByMatchId(ctx context.Context, id int) ([]models.MatchHistory, error) {
+	return newestFirst(dao.table.find(func(history models.MatchHistory) bool { return history.MatchId == id })), nil
+}
+
+// CreateAll stores the histories, like the match_history collection it allows several changes of a match at the same time.
+func (dao *MatchHistoryDAO) CreateAll(ctx context.Context, histories []models.MatchHistory) error {
+	return dao.table.appendAll(histories)
+}
+
+func newestFirst(histories []models.MatchHistory) []models.MatchHistory {
+	sort.SliceStable(histories, func(i, j int) bool { return histories[i].ChangedAt.After(histories[j].ChangedAt) })
+
+	return histories
+}
@@ -0,0 +1,89 @@
+// Package memory holds in memory implementations of the repositories in the dal package.
+// On their own they keep nothing across processes and are meant for tests and for trying the commands without a database,
+// given a Persister they are the cache in front of a single file store such as the one in the bolt package.
+package memory
+
+import (
+	"fmt"
+	"github.com/jedi-knights/ecnl/pkg"
+	"github.com/jedi-knights/ecnl/pkg/dal"
+)
+
+// NewRepositories creates empty in memory repositories.
+func NewRepositories() *dal.Repositories {
+	repos, _ := newRepositories()
+
+	return repos
+}
+
+// NewPersistentRepositories creates in memory repositories that are loaded from store and write every change through to it.
+func NewPersistentRepositories(store Persister) (*dal.Repositories, error) {
+	repos, tables := newRepositories()
+
+	for _, t := range tables {
+		if err := t.persist(store); err != nil {
+			return nil, err
+		}
+	}
+
+	return repos, nil
+}
+
+type persistent interface {
+	persist(store Persister) error
+}
+
+func newRepositories() (*dal.Repositories, []persistent) {
+	organizations := NewOrganizationDAO()
+	clubs := NewClubDAO()
+	events := NewEventDAO()
+	teams := NewTeamDAO()
+	history := NewMatchHistoryDAO()
+	matches := NewMatchEventDAOWithHistory(history)
+	rpiEvents := NewRPIEventDAO()
+	rpiSnapshots := NewRPISnapshotDAO()
+	runs := NewRunDAO()
+	syncState := NewSyncStateDAO()
+	leases := NewLeaseDAO()
+
+	repos := &dal.Repositories{
+		Organizations: organizations,
+		Clubs:         clubs,
+		Events:        events,
+		Teams:         teams,
+		Matches:       matches,
+		RPIEvents:     rpiEvents,
+		RPISnapshots:  rpiSnapshots,
+		MatchHistory:  history,
+		Runs:          runs,
+		SyncState:     syncState,
+		Leases:        leases,
+	}
+
+	return repos, []persistent{
+		organizations.table, clubs.table, events.table, teams.table, matches.table,
+		rpiEvents.table, rpiSnapshots.table, history.table, runs.table, syncState.table, leases.table,
+	}
+}
+
+// notFound returns err when there is one, otherwise a pkg.ErrNotFound described by format and args.
+func notFound(err error, format string, args ...any) error {
+	if err != nil {
+		return err
+	}
+
+	return fmt.Errorf(format+": %w", append(args, pkg.ErrNotFound)...)
+}
+
+func all[T any](T) bool {
+	return true
+}
+
+func set(keys []int) map[int]bool {
+	members := make(map[int]bool, len(keys))
+	for _, key := range keys {
+		members[key] = true
+	}
+
+	return members
+}
@@ -0,0 +1,255 @@
+package memory_test
+
+import (
+	"context"
+	"github.com/jedi-knights/ecnl/pkg"
+	"github.com/jedi-knights/ecnl/pkg/dal"
+	"github.com/jedi-knights/ecnl/pkg/dal/memory"
+	"github.com/jedi-knights/ecnl/pkg/models"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"time"
+)
+
+var _ = Describe("Memory", func() {
+	ctx := context.Background()
+	var repos *dal.Repositories
+
+	BeforeEach(func() {
+		repos = memory.NewRepositories()
+	})
+
+	Describe("SyncAll", func() {
+		It("should insert, update and leave records unchanged on their key", func() {
+			// Arrange
+			_, err := repos.Clubs.SyncAll(ctx, []models.Club{{ClubId: 1, Name: "Alpha"}, {ClubId: 2, Name: "Bravo"}})
+			Expect(err).NotTo(HaveOccurred())
+
+			// Act
+			summary, err := repos.Clubs.SyncAll(ctx, []models.Club{{ClubId: 1, Name: "Alpha"}, {ClubId: 2, Name: "Bravo FC"}, {ClubId: 3, Name: "Charlie"}})
+
+			// Assert
+			Expect(err).NotTo(HaveOccurred())
+			Expect(summary).To(Equal(dal.SyncSummary{Inserted: 1, Updated: 1, Unchanged: 1}))
+
+			club, err := repos.Clubs.GetById(ctx, 2)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(club.Name).To(Equal("Bravo FC"))
+		})
+
+		It("should record the corrections of synced matches", func() {
+			// Arrange
+			_, err := repos.Matches.SyncAll(ctx, []models.MatchEvent{{MatchId: 9001, HomeTeamScore: 0, AwayTeamScore: 0}})
+			Expect(err).NotTo(HaveOccurred())
+
+			// Act
+			_, err = repos.Matches.SyncAll(ctx, []models.MatchEvent{{MatchId: 9001, HomeTeamScore: 2, AwayTeamScore: 1}})
+
+			// Assert
+			Expect(err).NotTo(HaveOccurred())
+
+			histories, err := repos.MatchHistory.GetByMatchId(ctx, 9001)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(histories).To(HaveLen(1))
+		})
+
+		It("should compare a match listed twice in a batch against its earlier listing", func() {
+			// Arrange
+			_, err := repos.Matches.SyncAll(ctx, []models.MatchEvent{{MatchId: 9001, HomeTeamScore: 0, AwayTeamScore: 0}})
+			Expect(err).NotTo(HaveOccurred())
+
+			// Act
+			_, err = repos.Matches.SyncAll(ctx, []models.MatchEvent{
+				{MatchId: 9001, HomeTeamScore: 1, AwayTeamScore: 0},
+				{MatchId: 9001, HomeTeamScore: 2, AwayTeamScore: 0},
+			})
+
+			// Assert
+			Expect(err).NotTo(HaveOccurred())
+
+			histories, err := repos.MatchHistory.GetByMatchId(ctx, 9001)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(histories).To(HaveLen(2))
+
+			var changes []models.FieldChange
+			for _, history := range histories {
+				changes = append(changes, history.Changes...)
+			}
+
+			Expect(changes).To(ConsistOf(
+				models.FieldChange{Field: "homeTeamScore", Old: "0", New: "1"},
+				models.FieldChange{Field: "homeTeamScore", Old: "1", New: "2"},
+			))
+		})
+
+		It("should not record corrections without history", func() {
+			// Arrange
+			matches := repos.Matches.WithoutHistory()
+			_, err := matches.SyncAll(ctx, []models.MatchEvent{{MatchId: 9001}})
+			Expect(err).NotTo(HaveOccurred())
+
+			// Act
+			_, err = matches.SyncAll(ctx, []models.MatchEvent{{MatchId: 9001, HomeTeamScore: 2}})
+
+			// Assert
+			Expect(err).NotTo(HaveOccurred())
+
+			histories, err := repos.MatchHistory.GetByMatchId(ctx, 9001)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(histories).To(BeEmpty())
+		})
+	})
+
+	Describe("RPI events", func() {
+		It("should key the rows of a ranking on the team name, not the team id", func() {
+			// Arrange
+			at := time.Date(2023, 10, 1, 0, 0, 0, 0, time.UTC)
+
+			// Act
+			summary, err := repos.RPIEvents.SyncAll(ctx, []models.RPIEvent{
+				{TeamId: 1001, TeamName: "Alpha G09", Timestamp: at, Ranking: 1},
+				{TeamId: 1001, TeamName: "Alpha ECNL G09", Timestamp: at, Ranking: 2},
+				{TeamName: "Bravo G09", Timestamp: at, Ranking: 3},
+				{TeamName: "Charlie G09", Timestamp: at, Ranking: 4},
+				{TeamId: 1001, TeamName: "Alpha G09", Timestamp: at, Ranking: 1},
+			})
+
+			// Assert
+			Expect(err).NotTo(HaveOccurred())
+			Expect(summary).To(Equal(dal.SyncSummary{Inserted: 4, Unchanged: 1}))
+		})
+	})
+
+	Describe("MarkRemoved", func() {
+		It("should hide removed teams unless asked for them", func() {
+			// Arrange
+			_, err := repos.Teams.SyncAll(ctx, []*models.Team{{Id: 1, Name: "Alpha G09"}, {Id: 2, Name: "Bravo G09"}})
+			Expect(err).NotTo(HaveOccurred())
+
+			// Act
+			removed, err := repos.Teams.MarkRemoved(ctx, []int{1})
+
+			// Assert
+			Expect(err).NotTo(HaveOccurred())
+			Expect(removed).To(Equal(1))
+
+			teams, err := repos.Teams.GetAll(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(teams).To(HaveLen(1))
+
+			teams, err = repos.Teams.WithRemoved().GetAll(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(teams).To(HaveLen(2))
+		})
+	})
+
+	Describe("GetById", func() {
+		It("should return ErrNotFound for an unknown id", func() {
+			// Act
+			_, err := repos.Events.GetById(ctx, 42)
+
+			// Assert
+			Expect(err).To(MatchError(pkg.ErrNotFound))
+		})
+	})
+
+	Describe("List", func() {
+		BeforeEach(func() {
+			_, err := repos.Matches.SyncAll(ctx, []models.MatchEvent{
+				{MatchId: 1, GameDate: "2023-09-02", Flight: "ECNL", Division: "G2009"},
+				{MatchId: 2, GameDate: "2023-09-09", Flight: "ECNL", Division: "G2009"},
+				{MatchId: 3, GameDate: "2023-09-16", Flight: "ECNL RL", Division: "G2009"},
+				{MatchId: 4, GameDate: "2023-09-23", Flight: "ECNL", Division: "G2009"},
+				{MatchId: 5, GameDate: "2023-09-30", Flight: "ECNL", Division: "G2010"},
+			})
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("should page the filtered matches in the sort order", func() {
+			// Act
+			page, err := repos.Matches.List(ctx, dal.ListOptions{
+				Filter: map[string]any{"flight": "ECNL", "division": "G2009"},
+				Sort:   []string{"-gamedate"},
+				Offset: 1,
+				Limit:  1,
+			})
+
+			// Assert
+			Expect(err).NotTo(HaveOccurred())
+			Expect(page.Total).To(Equal(3))
+			Expect(page.Items).To(HaveLen(1))
+			Expect(page.Items[0].MatchId).To(Equal(2))
+			Expect(page.HasMore()).To(BeTrue())
+		})
+
+		It("should filter on numbers whatever their type", func() {
+			// Act
+			page, err := repos.Matches.List(ctx, dal.ListOptions{Filter: map[string]any{"matchid": int64(5)}})
+
+			// Assert
+			Expect(err).NotTo(HaveOccurred())
+			Expect(page.Items).To(HaveLen(1))
+			Expect(page.HasMore()).To(BeFalse())
+		})
+
+		It("should refuse a negative offset", func() {
+			// Act
+			_, err := repos.Matches.List(ctx, dal.ListOptions{Offset: -1})
+
+			// Assert
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("should stream the matches until fn fails", func() {
+			// Arrange
+			var seen []int
+
+			// Act
+			err := repos.Matches.Each(ctx, dal.ListOptions{Sort: []string{"matchid"}}, func(match models.MatchEvent) error {
+				if seen = append(seen, match.MatchId); len(seen) == 2 {
+					return pkg.ErrNotFound
+				}
+
+				return nil
+			})
+
+			// Assert
+			Expect(err).To(MatchError(pkg.ErrNotFound))
+			Expect(seen).To(Equal([]int{1, 2}))
+		})
+	})
+
+	Describe("Create", func() {
+		It("should return ErrDuplicate for a taken key", func() {
+			// Arrange
+			Expect(repos.Matches.Create(ctx, models.MatchEvent{MatchId: 1})).To(Succeed())
+
+			// Act
+			err := repos.Matches.Create(ctx, models.MatchEvent{MatchId: 1, Venue: "Field 2"})
+
+			// Assert
+			Expect(err).To(MatchError(pkg.ErrDuplicate))
+		})
+	})
+
+	Describe("Acquire", func() {
+		It("should refuse a lease held by another holder until it expires", func() {
+			// Arrange
+			acquired, err := repos.Leases.Acquire(ctx, "sync", "a", 50*time.Millisecond)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(acquired).To(BeTrue())
+
+			// Act
+			held, err := repos.Leases.Acquire(ctx, "sync", "b", time.Minute)
+
+			// Assert
+			Expect(err).NotTo(HaveOccurred())
+			Expect(held).To(BeFalse())
+
+			Eventually(func() bool {
+				acquired, _ := repos.Leases.Acquire(ctx, "sync", "b", time.Minute)
+				return acquired
+			}).Should(BeTrue())
+		})
+	})
+})
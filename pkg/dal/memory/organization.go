@@ -0,0 +1,113 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"github.com/jedi-knights/ecnl/pkg"
+	"github.com/jedi-knights/ecnl/pkg/dal"
+	"github.com/jedi-knights/ecnl/pkg/models"
+)
+
+// OrganizationDAO is an in memory dal.OrganizationDAOer.
+type OrganizationDAO struct {
+	table *table[models.Organization]
+}
+
+// NewOrganizationDAO creates an empty in memory organization data access object.
+func NewOrganizationDAO() *OrganizationDAO {
+	return &OrganizationDAO{table: newTable("organizations", func(organization models.Organization) any { return organization.Id })}
+}
+
+func (dao *OrganizationDAO) Index(ctx context.Context) error {
+	return nil
+}
+
+func (dao *OrganizationDAO) GetAll(ctx context.Context) ([]models.Organization, error) {
+	return dao.table.find(all[models.Organization]), nil
+}
+
+func (dao *OrganizationDAO) Each(ctx context.Context, opts dal.ListOptions, fn func(models.Organization) error) error {
+	return each(ctx, dao.table, all[models.Organization], opts, fn)
+}
+
+func (dao *OrganizationDAO) List(ctx context.Context, opts dal.ListOptions) (dal.Page[models.Organization], error) {
+	return list(dao.table, all[models.Organization], opts)
+}
+
+func (dao *OrganizationDAO) GetById(ctx context.Context, id int) (*models.Organization, error) {
+	if organization, ok := dao.table.first(byOrganizationId(id)); ok {
+		return organization, nil
+	}
+
+	return nil, fmt.Errorf("organization id %d: %w", id, pkg.ErrNotFound)
+}
+
+func (dao *OrganizationDAO) GetByName(ctx context.Context, name string) (*models.Organization, error) {
+	if organization, ok := dao.table.first(byOrganizationName(name)); ok {
+		return organization, nil
+	}
+
+	return nil, fmt.Errorf("organization name '%s': %w", name, pkg.ErrNotFound)
+}
+
+func (dao *OrganizationDAO) Update(ctx context.Context, organization models.Organization) error {
+	if replaced, err := dao.table.replace(byOrganizationId(organization.Id), organization); err != nil || !replaced {
+		return notFound(err, "update organization id %d", organization.Id)
+	}
+
+	return nil
+}
+
+func (dao *OrganizationDAO) Delete(ctx context.Context, organization models.Organization) error {
+	return dao.DeleteById(ctx, organization.Id)
+}
+
+func (dao *OrganizationDAO) DeleteByName(ctx context.Context, name string) error {
+	if removed, err := dao.table.remove(byOrganizationName(name), 1); err != nil || removed != 1 {
+		return notFound(err, "delete organization name '%s'", name)
+	}
+
+	return nil
+}
+
+func (dao *OrganizationDAO) DeleteById(ctx context.Context, id int) error {
+	if removed, err := dao.table.remove(byOrganizationId(id), 1); err != nil || removed != 1 {
+		return notFound(err, "delete organization id %d", id)
+	}
+
+	return nil
+}
+
+func (dao *OrganizationDAO) Create(ctx context.Context, organization models.Organization) error {
+	return dao.table.insert(organization)
+}
+
+func (dao *OrganizationDAO) Exists(ctx context.Context, organization models.Organization) (bool, error) {
+	return dao.ExistsById(ctx, organization.Id)
+}
+
+func (dao *OrganizationDAO) ExistsByName(ctx context.Context, name string) (bool, error) {
+	return dao.table.count(byOrganizationName(name)) > 0, nil
+}
+
+func (dao *OrganizationDAO) ExistsById(ctx context.Context, id int) (bool, error) {
+	return dao.table.count(byOrganizationId(id)) > 0, nil
+}
+
+func (dao *OrganizationDAO) Sync(ctx context.Context, organization models.Organization) error {
+	_, err := dao.SyncAll(ctx, []models.Organization{organization})
+
+	return err
+}
+
+func (dao *OrganizationDAO) SyncAll(ctx context.Context, organizations []models.Organization) (dal.SyncSummary, error) {
+	return dao.table.upsert(organizations)
+}
+
+func byOrganizationId(id int) func(models.Organization) bool {
+	return func(organization models.Organization) bool { return organization.Id == id }
+}
+
+func byOrganizationName(name string) func(models.Organization) bool {
+	return func(organization models.Organization) bool { return organization.Name == name }
+}
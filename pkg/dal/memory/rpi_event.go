@@ -0,0 +1,105 @@
+package memory
+
+import (
+	"context"
+	"github.com/jedi-knights/ecnl/pkg/dal"
+	"github.com/jedi-knights/ecnl/pkg/models"
+	"sort"
+	"time"
+)
+
+type rpiEventKey struct {
+	snapshotId string
+	teamName   string
+	timestamp  time.Time
+}
+
+// RPIEventDAO is an in memory dal.RPIEventDAOer.
+type RPIEventDAO struct {
+	table *table[models.RPIEvent]
+}
+
+// NewRPIEventDAO creates an empty in memory RPI event data access object.
+func NewRPIEventDAO() *RPIEventDAO {
+	return &RPIEventDAO{table: newTable("rpi_events", func(rpiEvent models.RPIEvent) any {
+		return rpiEventKey{snapshotId: rpiEvent.SnapshotId, teamName: rpiEvent.TeamName, timestamp: rpiEvent.Timestamp.UTC()}
+	})}
+}
+
+func (dao *RPIEventDAO) Index(ctx context.Context) error {
+	return nil
+}
+
+func (dao *RPIEventDAO) GetAll(ctx context.Context) ([]models.RPIEvent, error) {
+	return dao.table.find(all[models.RPIEvent]), nil
+}
+
+func (dao *RPIEventDAO) Each(ctx context.Context, opts dal.ListOptions, fn func(models.RPIEvent) error) error {
+	return each(ctx, dao.table, all[models.RPIEvent], opts, fn)
+}
+
+func (dao *RPIEventDAO) List(ctx context.Context, opts dal.ListOptions) (dal.Page[models.RPIEvent], error) {
+	return list(dao.table, all[models.RPIEvent], opts)
+}
+
+func (dao *RPIEventDAO) GetByTeamId(ctx context.Context, teamId int) ([]models.RPIEvent, error) {
+	return dao.table.find(byRPITeamId(teamId)), nil
+}
+
+func (dao *RPIEventDAO) GetByTeamName(ctx context.Context, teamName string) ([]models.RPIEvent, error) {
+	return dao.table.find(byRPITeamName(teamName)), nil
+}
+
+func (dao *RPIEventDAO) GetBySnapshotId(ctx context.Context, snapshotId string) ([]models.RPIEvent, error) {
+	rpiEvents := dao.table.find(func(rpiEvent models.RPIEvent) bool { return rpiEvent.SnapshotId == snapshotId })
+
+	sort.SliceStable(rpiEvents, func(i, j int) bool { return rpiEvents[i].Ranking < rpiEvents[j].Ranking })
+
+	return rpiEvents, nil
+}
+
+func (dao *RPIEventDAO) Create(ctx context.Context, rpiEvent models.RPIEvent) error {
+	return dao.table.insert(rpiEvent)
+}
+
+func (dao *RPIEventDAO) CreateAll(ctx context.Context, rpiEvents []models.RPIEvent) error {
+	for _, rpiEvent := range rpiEvents {
+		if err := dao.table.insert(rpiEvent); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (dao *RPIEventDAO) DeleteByTeamId(ctx context.Context, teamId int) error {
+	if removed, err := dao.table.remove(byRPITeamId(teamId), 0); err != nil || removed == 0 {
+		return notFound(err, "delete rpi events for team id %d", teamId)
+	}
+
+	return nil
+}
+
+func (dao *RPIEventDAO) DeleteByTeamName(ctx context.Context, teamName string) error {
+	if removed, err := dao.table.remove(byRPITeamName(teamName), 0); err != nil || removed == 0 {
+		return notFound(err, "delete rpi events for team name '%s'", teamName)
+	}
+
+	return nil
+}
+
+func (dao *RPIEventDAO) DeleteBySnapshotId(ctx context.Context, snapshotId string) (int, error) {
+	return dao.table.remove(func(rpiEvent models.RPIEvent) bool { return rpiEvent.SnapshotId == snapshotId }, 0)
+}
+
+func (dao *RPIEventDAO) SyncAll(ctx context.Context, rpiEvents []models.RPIEvent) (dal.SyncSummary, error) {
+	return dao.table.upsert(rpiEvents)
+}
+
+func byRPITeamId(teamId int) func(models.RPIEvent) bool {
+	return func(rpiEvent models.RPIEvent) bool { return rpiEvent.TeamId == teamId }
+}
+
+func byRPITeamName(teamName string) func(models.RPIEvent) bool {
+	return func(rpiEvent models.RPIEvent) bool { return rpiEvent.TeamName == teamName }
+}
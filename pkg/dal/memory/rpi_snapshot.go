@@ -0,0 +1,73 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"github.com/jedi-knights/ecnl/pkg"
+	"github.com/jedi-knights/ecnl/pkg/dal"
+	"github.com/jedi-knights/ecnl/pkg/models"
+	"time"
+)
+
+// RPISnapshotDAO is an in memory dal.RPISnapshotDAOer.
+type RPISnapshotDAO struct {
+	table *table[models.RPISnapshot]
+}
+
+// NewRPISnapshotDAO creates an empty in memory RPI snapshot data access object.
+func NewRPISnapshotDAO() *RPISnapshotDAO {
+	return &RPISnapshotDAO{table: newTable("rpi_snapshots", func(snapshot models.RPISnapshot) any { return snapshot.Id })}
+}
+
+func (dao *RPISnapshotDAO) Index(ctx context.Context) error {
+	return nil
+}
+
+func (dao *RPISnapshotDAO) GetAll(ctx context.Context) ([]models.RPISnapshot, error) {
+	return dao.table.find(all[models.RPISnapshot]), nil
+}
+
+func (dao *RPISnapshotDAO) Each(ctx context.Context, opts dal.ListOptions, fn func(models.RPISnapshot) error) error {
+	return each(ctx, dao.table, all[models.RPISnapshot], opts, fn)
+}
+
+func (dao *RPISnapshotDAO) List(ctx context.Context, opts dal.ListOptions) (dal.Page[models.RPISnapshot], error) {
+	return list(dao.table, all[models.RPISnapshot], opts)
+}
+
+func (dao *RPISnapshotDAO) GetById(ctx context.Context, id string) (*models.RPISnapshot, error) {
+	if snapshot, ok := dao.table.get(id); ok {
+		return snapshot, nil
+	}
+
+	return nil, fmt.Errorf("rpi snapshot '%s': %w", id, pkg.ErrNotFound)
+}
+
+func (dao *RPISnapshotDAO) GetAsOf(ctx context.Context, ageGroup string, asOf time.Time) (*models.RPISnapshot, error) {
+	var latest *models.RPISnapshot
+
+	for _, snapshot := range dao.table.find(func(snapshot models.RPISnapshot) bool {
+		return snapshot.AgeGroup == ageGroup && (asOf.IsZero() || !snapshot.MatchCutoff.After(asOf))
+	}) {
+		snapshot := snapshot
+
+		if latest == nil || snapshot.MatchCutoff.After(latest.MatchCutoff) ||
+			snapshot.MatchCutoff.Equal(latest.MatchCutoff) && snapshot.ComputedAt.After(latest.ComputedAt) {
+			latest = &snapshot
+		}
+	}
+
+	if latest == nil {
+		return nil, fmt.Errorf("rpi snapshot of age group '%s' as of %s: %w", ageGroup, asOf.Format(time.RFC3339), pkg.ErrNotFound)
+	}
+
+	return latest, nil
+}
+
+func (dao *RPISnapshotDAO) Create(ctx context.Context, snapshot models.RPISnapshot) error {
+	return dao.table.insert(snapshot)
+}
+
+func (dao *RPISnapshotDAO) SyncAll(ctx context.Context, snapshots []models.RPISnapshot) (dal.SyncSummary, error) {
+	return dao.table.upsert(snapshots)
+}
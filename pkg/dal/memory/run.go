@@ -0,0 +1,49 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"github.com/jedi-knights/ecnl/pkg"
+	"github.com/jedi-knights/ecnl/pkg/models"
+	"sort"
+)
+
+// RunDAO is an in memory dal.RunDAOer.
+type RunDAO struct {
+	table *table[models.Run]
+}
+
+// NewRunDAO creates an empty in memory run data access object.
+func NewRunDAO() *RunDAO {
+	return &RunDAO{table: newTable("runs", func(run models.Run) any { return run.Id })}
+}
+
+func (dao *RunDAO) Index(ctx context.Context) error {
+	return nil
+}
+
+func (dao *RunDAO) GetRecent(ctx context.Context, command string, limit int) ([]models.Run, error) {
+	runs := dao.table.find(func(run models.Run) bool { return command == "" || run.Command == command })
+
+	sort.SliceStable(runs, func(i, j int) bool { return runs[i].StartedAt.After(runs[j].StartedAt) })
+
+	if limit > 0 && len(runs) > limit {
+		runs = runs[:limit]
+	}
+
+	return runs, nil
+}
+
+func (dao *RunDAO) GetById(ctx context.Context, id string) (*models.Run, error) {
+	if run, ok := dao.table.get(id); ok {
+		return run, nil
+	}
+
+	return nil, fmt.Errorf("run '%s': %w", id, pkg.ErrNotFound)
+}
+
+func (dao *RunDAO) Save(ctx context.Context, run models.Run) error {
+	_, err := dao.table.upsert([]models.Run{run})
+
+	return err
+}
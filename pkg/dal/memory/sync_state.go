@@ -0,0 +1,46 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"github.com/jedi-knights/ecnl/pkg"
+	"github.com/jedi-knights/ecnl/pkg/models"
+)
+
+// SyncStateDAO is an in memory dal.SyncStateDAOer.
+type SyncStateDAO struct {
+	table *table[models.SyncState]
+}
+
+// NewSyncStateDAO creates an empty in memory sync state data access object.
+func NewSyncStateDAO() *SyncStateDAO {
+	return &SyncStateDAO{table: newTable("sync_state", func(state models.SyncState) any { return state.Key })}
+}
+
+func (dao *SyncStateDAO) Index(ctx context.Context) error {
+	return nil
+}
+
+func (dao *SyncStateDAO) GetAll(ctx context.Context) ([]models.SyncState, error) {
+	return dao.table.find(all[models.SyncState]), nil
+}
+
+func (dao *SyncStateDAO) GetByKey(ctx context.Context, key string) (*models.SyncState, error) {
+	if state, ok := dao.table.get(key); ok {
+		return state, nil
+	}
+
+	return nil, fmt.Errorf("sync state '%s': %w", key, pkg.ErrNotFound)
+}
+
+func (dao *SyncStateDAO) Save(ctx context.Context, state models.SyncState) error {
+	_, err := dao.table.upsert([]models.SyncState{state})
+
+	return err
+}
+
+func (dao *SyncStateDAO) DeleteAll(ctx context.Context) error {
+	_, err := dao.table.remove(all[models.SyncState], 0)
+
+	return err
+}
@@ -0,0 +1,321 @@
+package memory
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/jedi-knights/ecnl/pkg"
+	"github.com/jedi-knights/ecnl/pkg/dal"
+	"sync"
+)
+
+// Persister keeps the records of the tables so that they outlive the process.
+// Records are identified by an id the table assigns, ids grow in the order the records were first stored.
+type Persister interface {
+	// Load calls fn with every record of the collection, in the order of their ids.
+	Load(collection string, fn func(id uint64, data []byte) error) error
+	// Write stores the records in put and deletes those in del, all at once.
+	Write(collection string, put map[uint64][]byte, del []uint64) error
+}
+
+type record[T any] struct {
+	id   uint64
+	item T
+}
+
+// table is a collection of records, kept in the order they were first stored.
+// Records are indexed on their key, the field the DAOs sync on.
+// A table with a Persister writes every change through to it before applying it.
+type table[T any] struct {
+	mu    sync.RWMutex
+	name  string
+	key   func(T) any
+	store Persister
+	next  uint64
+	ids   []uint64
+	items map[uint64]T
+	index map[any]uint64
+}
+
+func newTable[T any](name string, key func(T) any) *table[T] {
+	return &table[T]{name: name, key: key, items: make(map[uint64]T), index: make(map[any]uint64)}
+}
+
+// persist loads the records of the table from store and writes the changes to come through to it.
+func (t *table[T]) persist(store Persister) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.store = store
+
+	return store.Load(t.name, func(id uint64, data []byte) error {
+		var item T
+
+		if err := json.Unmarshal(data, &item); err != nil {
+			return fmt.Errorf("error reading record %d of %s: %w", id, t.name, err)
+		}
+
+		t.add(record[T]{id: id, item: item})
+		t.next = max(t.next, id)
+
+		return nil
+	})
+}
+
+// find returns copies of the records that match.
+func (t *table[T]) find(match func(T) bool) []T {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	var found []T
+
+	for _, id := range t.ids {
+		if match(t.items[id]) {
+			found = append(found, t.items[id])
+		}
+	}
+
+	return found
+}
+
+// first returns a copy of the first record that matches.
+func (t *table[T]) first(match func(T) bool) (*T, bool) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	for _, id := range t.ids {
+		if item := t.items[id]; match(item) {
+			return &item, true
+		}
+	}
+
+	return nil, false
+}
+
+// get returns a copy of the record with the key.
+func (t *table[T]) get(key any) (*T, bool) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	id, ok := t.index[key]
+	if !ok {
+		return nil, false
+	}
+
+	item := t.items[id]
+
+	return &item, true
+}
+
+// count returns the number of records that match.
+func (t *table[T]) count(match func(T) bool) int {
+	return len(t.find(match))
+}
+
+// insert appends a record, it fails with pkg.ErrDuplicate when its key is taken.
+func (t *table[T]) insert(item T) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if _, ok := t.index[t.key(item)]; ok {
+		return fmt.Errorf("insert into %s: %v: %w", t.name, t.key(item), pkg.ErrDuplicate)
+	}
+
+	return t.commit([]record[T]{t.fresh(item)}, nil)
+}
+
+// appendAll stores the records without checking their keys, for collections whose key is not unique.
+func (t *table[T]) appendAll(items []T) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	put := make([]record[T], 0, len(items))
+	for _, item := range items {
+		put = append(put, t.fresh(item))
+	}
+
+	return t.commit(put, nil)
+}
+
+// replace replaces the first record that matches, it returns false when there is none.
+func (t *table[T]) replace(match func(T) bool, item T) (bool, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for _, id := range t.ids {
+		if match(t.items[id]) {
+			return true, t.commit([]record[T]{{id: id, item: item}}, nil)
+		}
+	}
+
+	return false, nil
+}
+
+// update applies fn to every record that matches and returns how many it changed.
+func (t *table[T]) update(match func(T) bool, fn func(*T) bool) (int, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var changed []record[T]
+
+	for _, id := range t.ids {
+		if item := t.items[id]; match(item) && fn(&item) {
+			changed = append(changed, record[T]{id: id, item: item})
+		}
+	}
+
+	return len(changed), t.commit(changed, nil)
+}
+
+// remove deletes the records that match, at most limit of them unless limit is zero, and returns how many it deleted.
+func (t *table[T]) remove(match func(T) bool, limit int) (int, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var removed []uint64
+
+	for _, id := range t.ids {
+		if limit > 0 && len(removed) == limit {
+			break
+		}
+
+		if match(t.items[id]) {
+			removed = append(removed, id)
+		}
+	}
+
+	return len(removed), t.commit(nil, removed)
+}
+
+// upsert replaces the records with the same key as the items or appends them, the way the DAOs sync.
+func (t *table[T]) upsert(items []T) (dal.SyncSummary, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var (
+		summary dal.SyncSummary
+		changed []record[T]
+	)
+
+	// An item that appears twice in items is inserted once and then updated.
+	pending := make(map[any]int)
+
+	for _, item := range items {
+		key := t.key(item)
+
+		if i, ok := pending[key]; ok {
+			if equal(changed[i].item, item) {
+				summary.Unchanged++
+			} else {
+				changed[i].item = item
+				summary.Updated++
+			}
+
+			continue
+		}
+
+		id, ok := t.index[key]
+
+		switch {
+		case !ok:
+			changed = append(changed, t.fresh(item))
+			summary.Inserted++
+		case equal(t.items[id], item):
+			summary.Unchanged++
+			continue
+		default:
+			changed = append(changed, record[T]{id: id, item: item})
+			summary.Updated++
+		}
+
+		pending[key] = len(changed) - 1
+	}
+
+	return summary, t.commit(changed, nil)
+}
+
+// fresh gives an item the next id, the caller holds the lock.
+func (t *table[T]) fresh(item T) record[T] {
+	t.next++
+
+	return record[T]{id: t.next, item: item}
+}
+
+// commit writes the changes through to the store and then applies them, the caller holds the lock.
+func (t *table[T]) commit(put []record[T], del []uint64) error {
+	if len(put) == 0 && len(del) == 0 {
+		return nil
+	}
+
+	if t.store != nil {
+		data := make(map[uint64][]byte, len(put))
+
+		for _, r := range put {
+			encoded, err := json.Marshal(r.item)
+			if err != nil {
+				return fmt.Errorf("error writing %s: %w", t.name, err)
+			}
+
+			data[r.id] = encoded
+		}
+
+		if err := t.store.Write(t.name, data, del); err != nil {
+			return fmt.Errorf("error writing %s: %w", t.name, err)
+		}
+	}
+
+	if len(del) > 0 {
+		t.drop(del)
+	}
+
+	for _, r := range put {
+		t.add(r)
+	}
+
+	return nil
+}
+
+// add stores a record or replaces the one with its id, the caller holds the lock.
+// A record whose key is already taken is stored but only the first one is found by key.
+func (t *table[T]) add(r record[T]) {
+	if _, ok := t.items[r.id]; !ok {
+		t.ids = append(t.ids, r.id)
+	}
+
+	t.items[r.id] = r.item
+
+	if _, ok := t.index[t.key(r.item)]; !ok {
+		t.index[t.key(r.item)] = r.id
+	}
+}
+
+// drop deletes the records with the ids and rebuilds the index, the caller holds the lock.
+func (t *table[T]) drop(del []uint64) {
+	for _, id := range del {
+		delete(t.items, id)
+	}
+
+	ids := t.ids[:0]
+	t.index = make(map[any]uint64, len(t.items))
+
+	for _, id := range t.ids {
+		if item, ok := t.items[id]; ok {
+			ids = append(ids, id)
+
+			if _, ok = t.index[t.key(item)]; !ok {
+				t.index[t.key(item)] = id
+			}
+		}
+	}
+
+	t.ids = ids
+}
+
+// equal compares records by their JSON, the form they are persisted in, so that times read back
+// from a store compare equal to the ones that were written.
+func equal[T any](a, b T) bool {
+	x, errX := json.Marshal(a)
+	y, errY := json.Marshal(b)
+
+	return errX == nil && errY == nil && bytes.Equal(x, y)
+}
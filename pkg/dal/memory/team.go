@@ -0,0 +1,146 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"github.com/jedi-knights/ecnl/pkg"
+	"github.com/jedi-knights/ecnl/pkg/dal"
+	"github.com/jedi-knights/ecnl/pkg/models"
+	"time"
+)
+
+// TeamDAO is an in memory dal.TeamDAOer.
+type TeamDAO struct {
+	table       *table[models.Team]
+	withRemoved bool
+}
+
+// NewTeamDAO creates an empty in memory team data access object.
+func NewTeamDAO() *TeamDAO {
+	return &TeamDAO{table: newTable("teams", func(team models.Team) any { return team.Id })}
+}
+
+func (dao *TeamDAO) WithRemoved() dal.TeamDAOer {
+	removed := *dao
+	removed.withRemoved = true
+
+	return &removed
+}
+
+func (dao *TeamDAO) Index(ctx context.Context) error {
+	return nil
+}
+
+func (dao *TeamDAO) GetAll(ctx context.Context) ([]models.Team, error) {
+	return dao.table.find(dao.active(all[models.Team])), nil
+}
+
+func (dao *TeamDAO) Each(ctx context.Context, opts dal.ListOptions, fn func(models.Team) error) error {
+	return each(ctx, dao.table, dao.active(all[models.Team]), opts, fn)
+}
+
+func (dao *TeamDAO) List(ctx context.Context, opts dal.ListOptions) (dal.Page[models.Team], error) {
+	return list(dao.table, dao.active(all[models.Team]), opts)
+}
+
+func (dao *TeamDAO) GetById(ctx context.Context, id int) (*models.Team, error) {
+	if team, ok := dao.table.first(dao.active(byTeamId(id))); ok {
+		return team, nil
+	}
+
+	return nil, fmt.Errorf("team id %d: %w", id, pkg.ErrNotFound)
+}
+
+func (dao *TeamDAO) GetByName(ctx context.Context, name string) (*models.Team, error) {
+	if team, ok := dao.table.first(dao.active(byTeamName(name))); ok {
+		return team, nil
+	}
+
+	return nil, fmt.Errorf("team name '%s': %w", name, pkg.ErrNotFound)
+}
+
+func (dao *TeamDAO) Update(ctx context.Context, team models.Team) error {
+	if replaced, err := dao.table.replace(byTeamId(team.Id), team); err != nil || !replaced {
+		return notFound(err, "update team id %d", team.Id)
+	}
+
+	return nil
+}
+
+func (dao *TeamDAO) Delete(ctx context.Context, team models.Team) error {
+	return dao.DeleteById(ctx, team.Id)
+}
+
+func (dao *TeamDAO) DeleteById(ctx context.Context, id int) error {
+	if removed, err := dao.table.remove(byTeamId(id), 1); err != nil || removed != 1 {
+		return notFound(err, "delete team id %d", id)
+	}
+
+	return nil
+}
+
+func (dao *TeamDAO) DeleteByName(ctx context.Context, name string) error {
+	if removed, err := dao.table.remove(byTeamName(name), 1); err != nil || removed != 1 {
+		return notFound(err, "delete team name '%s'", name)
+	}
+
+	return nil
+}
+
+func (dao *TeamDAO) Create(ctx context.Context, team models.Team) error {
+	return dao.table.insert(team)
+}
+
+func (dao *TeamDAO) Exists(ctx context.Context, team models.Team) (bool, error) {
+	return dao.ExistsById(ctx, team.Id)
+}
+
+func (dao *TeamDAO) ExistsById(ctx context.Context, id int) (bool, error) {
+	return dao.table.count(dao.active(byTeamId(id))) > 0, nil
+}
+
+func (dao *TeamDAO) ExistsByName(ctx context.Context, name string) (bool, error) {
+	return dao.table.count(dao.active(byTeamName(name))) > 0, nil
+}
+
+func (dao *TeamDAO) Sync(ctx context.Context, team models.Team) error {
+	_, err := dao.SyncAll(ctx, []*models.Team{&team})
+
+	return err
+}
+
+func (dao *TeamDAO) MarkRemoved(ctx context.Context, keep []int) (int, error) {
+	now := time.Now()
+	kept := set(keep)
+
+	return dao.table.update(func(team models.Team) bool {
+		return team.RemovedAt == nil && !kept[team.Id]
+	}, func(team *models.Team) bool {
+		team.RemovedAt = &now
+		return true
+	})
+}
+
+func (dao *TeamDAO) SyncAll(ctx context.Context, teams []*models.Team) (dal.SyncSummary, error) {
+	values := make([]models.Team, 0, len(teams))
+	for _, team := range teams {
+		values = append(values, *team)
+	}
+
+	return dao.table.upsert(values)
+}
+
+// active restricts match to the teams that were not removed upstream, unless the data access object includes them.
+func (dao *TeamDAO) active(match func(models.Team) bool) func(models.Team) bool {
+	return func(team models.Team) bool {
+		return (dao.withRemoved || team.RemovedAt == nil) && match(team)
+	}
+}
+
+func byTeamId(id int) func(models.Team) bool {
+	return func(team models.Team) bool { return team.Id == id }
+}
+
+func byTeamName(name string) func(models.Team) bool {
+	return func(team models.Team) bool { return team.Name == name }
+}
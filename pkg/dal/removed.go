@@ -0,0 +1,31 @@
+package dal
+
+import (
+	"context"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"time"
+)
+
+// active restricts filter to the records that were not removed upstream, unless withRemoved is set.
+// A record is active when its removedat field is missing or null.
+func active(filter bson.M, withRemoved bool) bson.M {
+	if !withRemoved {
+		filter["removedat"] = nil
+	}
+
+	return filter
+}
+
+// markRemoved stamps the active records of col whose key is not in keep with the time they were found to be removed upstream.
+// A record that comes back upstream becomes active again the next time it is synced, because syncing replaces the whole document.
+func markRemoved[K any](ctx context.Context, col *mongo.Collection, key string, keep []K) (int, error) {
+	filter := bson.M{key: bson.M{"$nin": keep}, "removedat": nil}
+
+	result, err := col.UpdateMany(ctx, filter, bson.M{"$set": bson.M{"removedat": time.Now()}})
+	if err != nil {
+		return 0, err
+	}
+
+	return int(result.ModifiedCount), nil
+}
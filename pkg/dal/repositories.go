@@ -0,0 +1,56 @@
+package dal
+
+import (
+	"context"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Repositories are the data access objects of every collection, behind their interfaces
+// so that the controllers and commands do not depend on the storage.
+type Repositories struct {
+	Organizations OrganizationDAOer
+	Clubs         ClubDAOer
+	Events        EventDAOer
+	Teams         TeamDAOer
+	Matches       MatchEventDAOer
+	RPIEvents     RPIEventDAOer
+	RPISnapshots  RPISnapshotDAOer
+	MatchHistory  MatchHistoryDAOer
+	Runs          RunDAOer
+	SyncState     SyncStateDAOer
+	Leases        LeaseDAOer
+}
+
+// NewMongoRepositories creates the repositories backed by the collections of database.
+// Synced matches record their corrections in the match history.
+func NewMongoRepositories(database *mongo.Database) *Repositories {
+	history := NewMatchHistoryDAO(database.Collection("match_history"))
+
+	return &Repositories{
+		Organizations: NewOrganizationDAO(database.Collection("organizations")),
+		Clubs:         NewClubDAO(database.Collection("clubs")),
+		Events:        NewEventDAO(database.Collection("events")),
+		Teams:         NewTeamDAO(database.Collection("teams")),
+		Matches:       NewMatchEventDAOWithHistory(database.Collection("matches"), history),
+		RPIEvents:     NewRPIEventDAO(database.Collection("rpi_events")),
+		RPISnapshots:  NewRPISnapshotDAO(database.Collection("rpi_snapshots")),
+		MatchHistory:  history,
+		Runs:          NewRunDAO(database.Collection("runs")),
+		SyncState:     NewSyncStateDAO(database.Collection("sync_state")),
+		Leases:        NewLeaseDAO(database.Collection("leases")),
+	}
+}
+
+// Index creates the indexes of every repository.
+func (r *Repositories) Index(ctx context.Context) error {
+	for _, repository := range []interface{ Index(context.Context) error }{
+		r.Organizations, r.Clubs, r.Events, r.Teams, r.Matches,
+		r.RPIEvents, r.RPISnapshots, r.MatchHistory, r.Runs, r.SyncState, r.Leases,
+	} {
+		if err := repository.Index(ctx); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
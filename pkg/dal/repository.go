@@ -0,0 +1,331 @@
+package dal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/jedi-knights/ecnl/pkg"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"log"
+)
+
+// Descriptor describes how the documents of an entity are stored.
+type Descriptor[T any, K comparable] struct {
+	// Entity names a document in errors, e.g. "club".
+	Entity string
+	// Collection names the collection in logs.
+	Collection string
+	// KeyField is the field holding the natural key of a document and Key returns it.
+	KeyField string
+	Key      func(T) K
+	// Filter matches the stored version of a document when its natural key spans several fields.
+	// It defaults to matching KeyField.
+	Filter func(T) bson.M
+	// NameField is the field looked up by name, empty when the entity has no name.
+	NameField string
+	// Removable is set for the entities marked removed upstream instead of being deleted,
+	// reads leave the removed documents out unless the repository includes them.
+	Removable bool
+	Indexes   []mongo.IndexModel
+}
+
+// Repository reads and writes the documents of an entity in a collection, as its Descriptor says.
+// The DAOs of the entities embed one and only add their special queries.
+type Repository[T any, K comparable] struct {
+	col         *mongo.Collection
+	desc        Descriptor[T, K]
+	withRemoved bool
+}
+
+// NewRepository creates a repository for the documents desc describes in col.
+func NewRepository[T any, K comparable](col *mongo.Collection, desc Descriptor[T, K]) *Repository[T, K] {
+	return &Repository[T, K]{col: col, desc: desc}
+}
+
+// WithRemoved returns a copy of the repository whose reads include the documents removed upstream.
+func (r *Repository[T, K]) WithRemoved() *Repository[T, K] {
+	removed := *r
+	removed.withRemoved = true
+
+	return &removed
+}
+
+// Index creates the indexes of the descriptor.
+func (r *Repository[T, K]) Index(ctx context.Context) error {
+	if len(r.desc.Indexes) == 0 {
+		return nil
+	}
+
+	names, err := r.col.Indexes().CreateMany(ctx, r.desc.Indexes)
+	if err != nil {
+		// The indexes of a database synced by an older version conflict until it is migrated.
+		return fmt.Errorf("error indexing the %s collection, run ecnl migrate up if the database predates its indexes: %w", r.desc.Collection, err)
+	}
+
+	for _, name := range names {
+		log.Printf("created index %s on %s collection", name, r.desc.Collection)
+	}
+
+	return nil
+}
+
+// Find gets the documents that match filter.
+func (r *Repository[T, K]) Find(ctx context.Context, filter bson.M, opts ...*options.FindOptions) ([]T, error) {
+	var items []T
+
+	cursor, err := r.col.Find(ctx, r.active(filter), opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = cursor.All(ctx, &items); err != nil {
+		return nil, err
+	}
+
+	return items, nil
+}
+
+// Each calls fn with the documents of the listing one at a time, without holding all of them in memory.
+// It stops at the first error fn returns.
+func (r *Repository[T, K]) Each(ctx context.Context, opts ListOptions, fn func(T) error) error {
+	if err := opts.Validate(); err != nil {
+		return err
+	}
+
+	cursor, err := r.col.Find(ctx, r.active(bson.M(opts.Filter)), findOptions(opts))
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var item T
+
+		if err = cursor.Decode(&item); err != nil {
+			return err
+		}
+
+		if err = fn(item); err != nil {
+			return err
+		}
+	}
+
+	return cursor.Err()
+}
+
+// List gets a page of the listing along with the number of documents in all of it.
+func (r *Repository[T, K]) List(ctx context.Context, opts ListOptions) (Page[T], error) {
+	var (
+		err  error
+		page = Page[T]{Offset: opts.Offset, Limit: opts.Limit}
+	)
+
+	if err = opts.Validate(); err != nil {
+		return page, err
+	}
+
+	if page.Total, err = r.Count(ctx, bson.M(opts.Filter)); err != nil {
+		return page, err
+	}
+
+	if page.Items, err = r.Find(ctx, bson.M(opts.Filter), findOptions(opts)); err != nil {
+		return page, err
+	}
+
+	return page, nil
+}
+
+// FindOne gets the first document that matches filter, what describes it in the error when there is none.
+func (r *Repository[T, K]) FindOne(ctx context.Context, filter bson.M, what string, opts ...*options.FindOneOptions) (*T, error) {
+	var item T
+
+	if err := r.col.FindOne(ctx, r.active(filter), opts...).Decode(&item); err != nil {
+		return nil, notFound(err, "%s", what)
+	}
+
+	return &item, nil
+}
+
+// Count counts the documents that match filter.
+func (r *Repository[T, K]) Count(ctx context.Context, filter bson.M) (int, error) {
+	count, err := r.col.CountDocuments(ctx, r.active(filter))
+
+	return int(count), err
+}
+
+// GetAll gets all the documents.
+func (r *Repository[T, K]) GetAll(ctx context.Context) ([]T, error) {
+	return r.Find(ctx, bson.M{})
+}
+
+// GetByKey gets the document by its natural key.
+func (r *Repository[T, K]) GetByKey(ctx context.Context, key K) (*T, error) {
+	return r.FindOne(ctx, bson.M{r.desc.KeyField: key}, r.describe(key))
+}
+
+// GetByName gets the document by name.
+func (r *Repository[T, K]) GetByName(ctx context.Context, name string) (*T, error) {
+	return r.FindOne(ctx, bson.M{r.desc.NameField: name}, fmt.Sprintf("%s name '%s'", r.desc.Entity, name))
+}
+
+// ExistsByKey checks to see if the document exists by its natural key.
+func (r *Repository[T, K]) ExistsByKey(ctx context.Context, key K) (bool, error) {
+	return r.exists(ctx, bson.M{r.desc.KeyField: key})
+}
+
+// ExistsByName checks to see if the document exists by name.
+func (r *Repository[T, K]) ExistsByName(ctx context.Context, name string) (bool, error) {
+	return r.exists(ctx, bson.M{r.desc.NameField: name})
+}
+
+// Create creates the document, it fails with pkg.ErrDuplicate when one with the same key is stored.
+func (r *Repository[T, K]) Create(ctx context.Context, item T) error {
+	_, err := r.col.InsertOne(ctx, item)
+	if mongo.IsDuplicateKeyError(err) {
+		return fmt.Errorf("create %s: %w", r.describe(r.desc.Key(item)), pkg.ErrDuplicate)
+	}
+
+	return err
+}
+
+// CreateAll creates the documents in bulk.
+func (r *Repository[T, K]) CreateAll(ctx context.Context, items []T) error {
+	if len(items) == 0 {
+		return nil
+	}
+
+	documents := make([]any, 0, len(items))
+	for _, item := range items {
+		documents = append(documents, item)
+	}
+
+	_, err := r.col.InsertMany(ctx, documents)
+	if mongo.IsDuplicateKeyError(err) {
+		return fmt.Errorf("create %s: %w", r.desc.Entity, pkg.ErrDuplicate)
+	}
+
+	return err
+}
+
+// Update updates the stored version of the document.
+func (r *Repository[T, K]) Update(ctx context.Context, item T) error {
+	result, err := r.col.UpdateOne(ctx, r.filter(item), bson.M{"$set": item})
+	if err != nil {
+		return err
+	}
+
+	if result.MatchedCount != 1 {
+		return fmt.Errorf("update %s: %w", r.describe(r.desc.Key(item)), pkg.ErrNotFound)
+	}
+
+	return nil
+}
+
+// DeleteByKey deletes the document by its natural key.
+func (r *Repository[T, K]) DeleteByKey(ctx context.Context, key K) error {
+	return r.deleteOne(ctx, bson.M{r.desc.KeyField: key}, r.describe(key))
+}
+
+// DeleteByName deletes the document by name.
+func (r *Repository[T, K]) DeleteByName(ctx context.Context, name string) error {
+	return r.deleteOne(ctx, bson.M{r.desc.NameField: name}, fmt.Sprintf("%s name '%s'", r.desc.Entity, name))
+}
+
+// DeleteMany deletes the documents that match filter and returns how many it deleted.
+func (r *Repository[T, K]) DeleteMany(ctx context.Context, filter bson.M) (int, error) {
+	result, err := r.col.DeleteMany(ctx, filter)
+	if err != nil {
+		return 0, err
+	}
+
+	return int(result.DeletedCount), nil
+}
+
+// SyncAll creates or replaces the documents in bulk, keyed on their natural key.
+func (r *Repository[T, K]) SyncAll(ctx context.Context, items []T) (SyncSummary, error) {
+	return bulkUpsert(ctx, r.col, items, r.filter)
+}
+
+// MarkRemoved marks the documents whose key is not in keep as removed upstream and returns how many it marked.
+func (r *Repository[T, K]) MarkRemoved(ctx context.Context, keep []K) (int, error) {
+	return markRemoved(ctx, r.col, r.desc.KeyField, keep)
+}
+
+func (r *Repository[T, K]) exists(ctx context.Context, filter bson.M) (bool, error) {
+	err := r.col.FindOne(ctx, r.active(filter)).Err()
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return false, nil
+	}
+
+	return err == nil, err
+}
+
+func (r *Repository[T, K]) deleteOne(ctx context.Context, filter bson.M, what string) error {
+	result, err := r.col.DeleteOne(ctx, filter)
+	if err != nil {
+		return err
+	}
+
+	if result.DeletedCount != 1 {
+		return fmt.Errorf("delete %s: %w", what, pkg.ErrNotFound)
+	}
+
+	return nil
+}
+
+// active restricts a copy of filter to the documents that were not removed upstream, for the removable entities.
+func (r *Repository[T, K]) active(filter bson.M) bson.M {
+	restricted := make(bson.M, len(filter)+1)
+	for field, value := range filter {
+		restricted[field] = value
+	}
+
+	if !r.desc.Removable {
+		return restricted
+	}
+
+	return active(restricted, r.withRemoved)
+}
+
+// findOptions translates the order and page of a listing.
+func findOptions(opts ListOptions) *options.FindOptions {
+	find := options.Find().SetSkip(int64(opts.Offset)).SetLimit(int64(opts.Limit))
+
+	if len(opts.Sort) > 0 {
+		sort := bson.D{}
+
+		for _, field := range opts.Sort {
+			name, descending := SortField(field)
+
+			if descending {
+				sort = append(sort, bson.E{Key: name, Value: -1})
+			} else {
+				sort = append(sort, bson.E{Key: name, Value: 1})
+			}
+		}
+
+		find.SetSort(sort)
+	}
+
+	return find
+}
+
+// filter matches the stored version of item.
+func (r *Repository[T, K]) filter(item T) bson.M {
+	if r.desc.Filter != nil {
+		return r.desc.Filter(item)
+	}
+
+	return bson.M{r.desc.KeyField: r.desc.Key(item)}
+}
+
+// describe describes the document with the key in errors, e.g. club 42 or run 'abc'.
+func (r *Repository[T, K]) describe(key K) string {
+	if s, ok := any(key).(string); ok {
+		return fmt.Sprintf("%s '%s'", r.desc.Entity, s)
+	}
+
+	return fmt.Sprintf("%s %v", r.desc.Entity, key)
+}
@@ -3,171 +3,117 @@ package dal
 import (
 	"context"
 	"fmt"
+	"github.com/jedi-knights/ecnl/pkg"
 	"github.com/jedi-knights/ecnl/pkg/models"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 	"log"
 )
 
 type RPIEventDAOer interface {
-	Index() error
-	GetByTeamId(teamId int) ([]models.RPIEvent, error)
-	GetByTeamName(teamName string) ([]models.RPIEvent, error)
-	Create(rpiEvent models.RPIEvent) error
-	DeleteByTeamId(teamId int) error
-	DeleteByTeamName(teamName string) error
+	Index(ctx context.Context) error
+	GetAll(ctx context.Context) ([]models.RPIEvent, error)
+	Each(ctx context.Context, opts ListOptions, fn func(models.RPIEvent) error) error
+	List(ctx context.Context, opts ListOptions) (Page[models.RPIEvent], error)
+	GetByTeamId(ctx context.Context, teamId int) ([]models.RPIEvent, error)
+	GetByTeamName(ctx context.Context, teamName string) ([]models.RPIEvent, error)
+	GetBySnapshotId(ctx context.Context, snapshotId string) ([]models.RPIEvent, error)
+	Create(ctx context.Context, rpiEvent models.RPIEvent) error
+	CreateAll(ctx context.Context, rpiEvents []models.RPIEvent) error
+	DeleteByTeamId(ctx context.Context, teamId int) error
+	DeleteByTeamName(ctx context.Context, teamName string) error
+	DeleteBySnapshotId(ctx context.Context, snapshotId string) (int, error)
+	SyncAll(ctx context.Context, rpiEvents []models.RPIEvent) (SyncSummary, error)
 }
 
-type RPIEventDAO struct {
-	ctx context.Context
-	col *mongo.Collection
+// rpiEventDescriptor describes how RPI events are stored, keyed on their snapshot, team name and timestamp.
+// The events of a ranking share its snapshot and timestamp and a team is ranked once by name,
+// while one team id can show up under several names or not be known at all.
+// The events generated before there were snapshots have no snapshot id.
+var rpiEventDescriptor = Descriptor[models.RPIEvent, string]{
+	Entity:     "rpi event",
+	Collection: "rpi_events",
+	KeyField:   "team_name",
+	Key:        func(rpiEvent models.RPIEvent) string { return rpiEvent.TeamName },
+	Filter: func(rpiEvent models.RPIEvent) bson.M {
+		return bson.M{"snapshot_id": snapshotId(rpiEvent.SnapshotId), "team_name": rpiEvent.TeamName, "timestamp": rpiEvent.Timestamp}
+	},
+	NameField: "team_name",
+	Indexes: []mongo.IndexModel{
+		{Keys: bson.D{{Key: "snapshot_id", Value: 1}, {Key: "team_name", Value: 1}, {Key: "timestamp", Value: 1}}, Options: options.Index().SetUnique(true)},
+		{Keys: bson.D{{Key: "team_name", Value: 1}}},
+		{Keys: bson.D{{Key: "team_id", Value: 1}}},
+		{Keys: bson.D{{Key: "snapshot_id", Value: 1}, {Key: "ranking", Value: 1}}},
+	},
 }
 
-func NewRPIEventDAO(ctx context.Context, col *mongo.Collection) *RPIEventDAO {
-	return &RPIEventDAO{ctx: ctx, col: col}
+// RPIEventDAO is the data access object for RPI events.
+type RPIEventDAO struct {
+	*Repository[models.RPIEvent, string]
 }
 
-// Index indexes the collection.
-func (dao *RPIEventDAO) Index() error {
-	var (
-		name string
-		err  error
-	)
-
-	// create an index on the team_id field
-	if name, err = dao.col.Indexes().CreateOne(dao.ctx, mongo.IndexModel{
-		Keys: bson.D{{"team_id", 1}},
-	}); err != nil {
-		return err
-	} else {
-		log.Printf("created index %s on rpi_events collection", name)
-	}
-
-	// create an index on the team_name field
-	if name, err = dao.col.Indexes().CreateOne(dao.ctx, mongo.IndexModel{
-		Keys: bson.D{{"team_name", 1}},
-	}); err != nil {
-		return err
-	} else {
-		log.Printf("created index %s on rpi_events collection", name)
-	}
-
-	return nil
+func NewRPIEventDAO(col *mongo.Collection) *RPIEventDAO {
+	return &RPIEventDAO{NewRepository(col, rpiEventDescriptor)}
 }
 
 // GetByTeamId gets a collection of RPI events by team id.
-func (dao *RPIEventDAO) GetByTeamId(teamId int) ([]models.RPIEvent, error) {
-	var (
-		err     error
-		cursor  *mongo.Cursor
-		bEvents []bson.M
-		event   models.RPIEvent
-		events  []models.RPIEvent
-	)
-
-	if cursor, err = dao.col.Find(dao.ctx, bson.M{"team_id": teamId}); err != nil {
-		return nil, err
-	}
-	if err = cursor.All(dao.ctx, &bEvents); err != nil {
-		return nil, err
-	}
-
-	for _, bEvent := range bEvents {
-		bsonBytes, _ := bson.Marshal(bEvent)
-		if err = bson.Unmarshal(bsonBytes, &event); err != nil {
-			return nil, err
-		}
-
-		events = append(events, event)
-	}
-
-	return events, nil
+func (dao *RPIEventDAO) GetByTeamId(ctx context.Context, teamId int) ([]models.RPIEvent, error) {
+	return dao.Find(ctx, bson.M{"team_id": teamId})
 }
 
 // GetByTeamName gets a collection of RPI events by team name.
-func (dao *RPIEventDAO) GetByTeamName(teamName string) ([]models.RPIEvent, error) {
-	var (
-		err     error
-		cursor  *mongo.Cursor
-		bEvents []bson.M
-		event   models.RPIEvent
-		events  []models.RPIEvent
-	)
-
-	if cursor, err = dao.col.Find(dao.ctx, bson.M{"team_name": teamName}); err != nil {
-		return nil, err
-	}
-	if err = cursor.All(dao.ctx, &bEvents); err != nil {
-		return nil, err
-	}
-
-	for _, bEvent := range bEvents {
-		bsonBytes, _ := bson.Marshal(bEvent)
-		if err = bson.Unmarshal(bsonBytes, &event); err != nil {
-			return nil, err
-		}
-
-		events = append(events, event)
-	}
-
-	return events, nil
+func (dao *RPIEventDAO) GetByTeamName(ctx context.Context, teamName string) ([]models.RPIEvent, error) {
+	return dao.Find(ctx, bson.M{"team_name": teamName})
 }
 
-// Create creates an RPI event.
-func (dao *RPIEventDAO) Create(rpiEvent models.RPIEvent) error {
-	var err error
-
-	if _, err = dao.col.InsertOne(dao.ctx, rpiEvent); err != nil {
-		return err
-	}
-
-	return nil
+// GetBySnapshotId gets the rows of an RPI snapshot, ordered by ranking.
+func (dao *RPIEventDAO) GetBySnapshotId(ctx context.Context, snapshotId string) ([]models.RPIEvent, error) {
+	return dao.Find(ctx, bson.M{"snapshot_id": snapshotId}, options.Find().SetSort(bson.D{{Key: "ranking", Value: 1}}))
 }
 
 // DeleteByTeamId deletes a collection of RPI events by team id.
-func (dao *RPIEventDAO) DeleteByTeamId(teamId int) error {
-	var (
-		err          error
-		deleteResult *mongo.DeleteResult
-	)
-
-	if deleteResult, err = dao.col.DeleteMany(dao.ctx, bson.M{"team_id": teamId}); err != nil {
+func (dao *RPIEventDAO) DeleteByTeamId(ctx context.Context, teamId int) error {
+	deleted, err := dao.DeleteMany(ctx, bson.M{"team_id": teamId})
+	if err != nil {
 		return err
 	}
 
-	log.Printf("deleted %d rpi events for team id %d", deleteResult.DeletedCount, teamId)
-
-	// Check to see if the delete was successful.
-	if deleteResult.DeletedCount > 0 {
-		return fmt.Errorf("delete failed")
+	if deleted == 0 {
+		return fmt.Errorf("delete rpi events for team id %d: %w", teamId, pkg.ErrNotFound)
 	}
 
-	// The delete was successful.
-	log.Printf("rpi event delete successful: %v", deleteResult)
+	log.Printf("deleted %d rpi events for team id %d", deleted, teamId)
 
 	return nil
 }
 
 // DeleteByTeamName deletes a collection of RPI event by team name.
-func (dao *RPIEventDAO) DeleteByTeamName(teamName string) error {
-	var (
-		err          error
-		deleteResult *mongo.DeleteResult
-	)
-
-	if deleteResult, err = dao.col.DeleteMany(dao.ctx, bson.M{"team_name": teamName}); err != nil {
+func (dao *RPIEventDAO) DeleteByTeamName(ctx context.Context, teamName string) error {
+	deleted, err := dao.DeleteMany(ctx, bson.M{"team_name": teamName})
+	if err != nil {
 		return err
 	}
 
-	log.Printf("deleted %d rpi events for team id %s", deleteResult.DeletedCount, teamName)
-
-	// Check to see if the delete was successful.
-	if deleteResult.DeletedCount > 0 {
-		return fmt.Errorf("delete failed")
+	if deleted == 0 {
+		return fmt.Errorf("delete rpi events for team name '%s': %w", teamName, pkg.ErrNotFound)
 	}
 
-	// The delete was successful.
-	log.Printf("rpi event delete successful: %v", deleteResult)
+	log.Printf("deleted %d rpi events for team name %s", deleted, teamName)
 
 	return nil
 }
+
+// DeleteBySnapshotId deletes the rows of an RPI snapshot and returns how many it deleted.
+func (dao *RPIEventDAO) DeleteBySnapshotId(ctx context.Context, snapshotId string) (int, error) {
+	return dao.DeleteMany(ctx, bson.M{"snapshot_id": snapshotId})
+}
+
+// snapshotId matches the snapshot id of an event, it is left out of the events without one and null matches those.
+func snapshotId(id string) any {
+	if id == "" {
+		return nil
+	}
+
+	return id
+}
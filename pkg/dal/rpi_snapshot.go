@@ -0,0 +1,62 @@
+package dal
+
+import (
+	"context"
+	"fmt"
+	"github.com/jedi-knights/ecnl/pkg/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"time"
+)
+
+type RPISnapshotDAOer interface {
+	Index(ctx context.Context) error
+	GetAll(ctx context.Context) ([]models.RPISnapshot, error)
+	Each(ctx context.Context, opts ListOptions, fn func(models.RPISnapshot) error) error
+	List(ctx context.Context, opts ListOptions) (Page[models.RPISnapshot], error)
+	GetById(ctx context.Context, id string) (*models.RPISnapshot, error)
+	GetAsOf(ctx context.Context, ageGroup string, asOf time.Time) (*models.RPISnapshot, error)
+	Create(ctx context.Context, snapshot models.RPISnapshot) error
+	SyncAll(ctx context.Context, snapshots []models.RPISnapshot) (SyncSummary, error)
+}
+
+// rpiSnapshotDescriptor describes how RPI snapshots are stored, keyed on their id.
+var rpiSnapshotDescriptor = Descriptor[models.RPISnapshot, string]{
+	Entity:     "rpi snapshot",
+	Collection: "rpi_snapshots",
+	KeyField:   "id",
+	Key:        func(snapshot models.RPISnapshot) string { return snapshot.Id },
+	Indexes: []mongo.IndexModel{
+		{Keys: bson.D{{Key: "id", Value: 1}}, Options: options.Index().SetUnique(true)},
+		{Keys: bson.D{{Key: "agegroup", Value: 1}, {Key: "matchcutoff", Value: -1}, {Key: "computedat", Value: -1}}},
+	},
+}
+
+// RPISnapshotDAO is the data access object for RPI snapshots.
+type RPISnapshotDAO struct {
+	*Repository[models.RPISnapshot, string]
+}
+
+func NewRPISnapshotDAO(col *mongo.Collection) *RPISnapshotDAO {
+	return &RPISnapshotDAO{NewRepository(col, rpiSnapshotDescriptor)}
+}
+
+// GetById gets an RPI snapshot by id.
+func (dao *RPISnapshotDAO) GetById(ctx context.Context, id string) (*models.RPISnapshot, error) {
+	return dao.GetByKey(ctx, id)
+}
+
+// GetAsOf gets the snapshot of the age group with the latest match cutoff at or before asOf,
+// the most recently computed one when several share it. A zero asOf gets the latest snapshot.
+func (dao *RPISnapshotDAO) GetAsOf(ctx context.Context, ageGroup string, asOf time.Time) (*models.RPISnapshot, error) {
+	filter := bson.M{"agegroup": ageGroup}
+
+	if !asOf.IsZero() {
+		filter["matchcutoff"] = bson.M{"$lte": asOf}
+	}
+
+	latest := options.FindOne().SetSort(bson.D{{Key: "matchcutoff", Value: -1}, {Key: "computedat", Value: -1}})
+
+	return dao.FindOne(ctx, filter, fmt.Sprintf("rpi snapshot of age group '%s' as of %s", ageGroup, asOf.Format(time.RFC3339)), latest)
+}
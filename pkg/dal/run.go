@@ -0,0 +1,66 @@
+package dal
+
+import (
+	"context"
+	"github.com/jedi-knights/ecnl/pkg/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type RunDAOer interface {
+	Index(ctx context.Context) error
+	GetRecent(ctx context.Context, command string, limit int) ([]models.Run, error)
+	GetById(ctx context.Context, id string) (*models.Run, error)
+	Save(ctx context.Context, run models.Run) error
+}
+
+// runDescriptor describes how the ledger of runs is stored, keyed on the run id.
+var runDescriptor = Descriptor[models.Run, string]{
+	Entity:     "run",
+	Collection: "runs",
+	KeyField:   "id",
+	Key:        func(run models.Run) string { return run.Id },
+	Indexes: []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "id", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys: bson.D{{Key: "command", Value: 1}, {Key: "startedat", Value: -1}},
+		},
+	},
+}
+
+// RunDAO is the data access object for the ledger of runs.
+type RunDAO struct {
+	*Repository[models.Run, string]
+}
+
+// NewRunDAO creates a new run data access object.
+func NewRunDAO(col *mongo.Collection) *RunDAO {
+	return &RunDAO{NewRepository(col, runDescriptor)}
+}
+
+// GetRecent gets the most recent runs first, limited to those of command unless it is empty.
+// A limit of zero returns every run.
+func (dao *RunDAO) GetRecent(ctx context.Context, command string, limit int) ([]models.Run, error) {
+	filter := bson.M{}
+	if command != "" {
+		filter["command"] = command
+	}
+
+	return dao.Find(ctx, filter, options.Find().SetSort(bson.D{{Key: "startedat", Value: -1}}).SetLimit(int64(limit)))
+}
+
+// GetById gets the run by id.
+func (dao *RunDAO) GetById(ctx context.Context, id string) (*models.Run, error) {
+	return dao.GetByKey(ctx, id)
+}
+
+// Save creates or replaces the run with the same id.
+func (dao *RunDAO) Save(ctx context.Context, run models.Run) error {
+	_, err := dao.SyncAll(ctx, []models.Run{run})
+
+	return err
+}
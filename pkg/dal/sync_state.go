@@ -0,0 +1,55 @@
+package dal
+
+import (
+	"context"
+	"github.com/jedi-knights/ecnl/pkg/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type SyncStateDAOer interface {
+	Index(ctx context.Context) error
+	GetAll(ctx context.Context) ([]models.SyncState, error)
+	GetByKey(ctx context.Context, key string) (*models.SyncState, error)
+	Save(ctx context.Context, state models.SyncState) error
+	DeleteAll(ctx context.Context) error
+}
+
+// syncStateDescriptor describes how the checkpoints of the sync are stored, keyed on their key.
+var syncStateDescriptor = Descriptor[models.SyncState, string]{
+	Entity:     "sync state",
+	Collection: "sync_state",
+	KeyField:   "key",
+	Key:        func(state models.SyncState) string { return state.Key },
+	Indexes: []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "key", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+	},
+}
+
+// SyncStateDAO is the data access object for the checkpoints of the sync.
+type SyncStateDAO struct {
+	*Repository[models.SyncState, string]
+}
+
+// NewSyncStateDAO creates a new sync state data access object.
+func NewSyncStateDAO(col *mongo.Collection) *SyncStateDAO {
+	return &SyncStateDAO{NewRepository(col, syncStateDescriptor)}
+}
+
+// Save creates or replaces the sync state with the same key.
+func (dao *SyncStateDAO) Save(ctx context.Context, state models.SyncState) error {
+	_, err := dao.SyncAll(ctx, []models.SyncState{state})
+
+	return err
+}
+
+// DeleteAll deletes every sync state, the next sync starts from scratch.
+func (dao *SyncStateDAO) DeleteAll(ctx context.Context) error {
+	_, err := dao.DeleteMany(ctx, bson.M{})
+
+	return err
+}
@@ -2,341 +2,101 @@ package dal
 
 import (
 	"context"
-	"fmt"
 	"github.com/jedi-knights/ecnl/pkg/models"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
-	"log"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 type TeamDAOer interface {
-	Index() error
-	GetAll() ([]models.Team, error)
-	GetByName(name string) (*models.Team, error)
-	GetById(id int) (*models.Team, error)
-	Update(team models.Team) error
-	Delete(team models.Team) error
-	DeleteByName(name string) error
-	DeleteById(id int) error
-	Create(team models.Team) error
-	Exists(team models.Team) (bool, error)
-	ExistsByName(name string) (bool, error)
-	ExistsById(id int) (bool, error)
-	Sync(team models.Team) error
-	SyncAll(teams []*models.Team) error
+	Index(ctx context.Context) error
+	GetAll(ctx context.Context) ([]models.Team, error)
+	Each(ctx context.Context, opts ListOptions, fn func(models.Team) error) error
+	List(ctx context.Context, opts ListOptions) (Page[models.Team], error)
+	GetByName(ctx context.Context, name string) (*models.Team, error)
+	GetById(ctx context.Context, id int) (*models.Team, error)
+	Update(ctx context.Context, team models.Team) error
+	Delete(ctx context.Context, team models.Team) error
+	DeleteByName(ctx context.Context, name string) error
+	DeleteById(ctx context.Context, id int) error
+	Create(ctx context.Context, team models.Team) error
+	Exists(ctx context.Context, team models.Team) (bool, error)
+	ExistsByName(ctx context.Context, name string) (bool, error)
+	ExistsById(ctx context.Context, id int) (bool, error)
+	Sync(ctx context.Context, team models.Team) error
+	MarkRemoved(ctx context.Context, keep []int) (int, error)
+	WithRemoved() TeamDAOer
+	SyncAll(ctx context.Context, teams []*models.Team) (SyncSummary, error)
+}
+
+// teamDescriptor describes how teams are stored, keyed on their id.
+var teamDescriptor = Descriptor[models.Team, int]{
+	Entity:     "team",
+	Collection: "teams",
+	KeyField:   "id",
+	Key:        func(team models.Team) int { return team.Id },
+	NameField:  "name",
+	Removable:  true,
+	Indexes: []mongo.IndexModel{
+		{Keys: bson.D{{Key: "id", Value: 1}}, Options: options.Index().SetUnique(true)},
+		{Keys: bson.D{{Key: "name", Value: 1}}},
+		{Keys: bson.D{{Key: "clubid", Value: 1}}},
+	},
 }
 
 // TeamDAO is the data access object for teams.
 type TeamDAO struct {
-	ctx context.Context
-	col *mongo.Collection
+	*Repository[models.Team, int]
 }
 
 // NewTeamDAO creates a new team data access object.
-func NewTeamDAO(ctx context.Context, col *mongo.Collection) *TeamDAO {
-	return &TeamDAO{ctx: ctx, col: col}
+func NewTeamDAO(col *mongo.Collection) *TeamDAO {
+	return &TeamDAO{NewRepository(col, teamDescriptor)}
 }
 
-/*
-{
-  "result": "success",
-  "data": {
-    "teamList": [
-      {
-        "teamID": 58734,
-        "teamName": "Alabama FC ECNL G09",
-        "status": 2,
-        "clubID": 18,
-        "initialSeed": 1,
-        "clubLogo": "https://s3.amazonaws.com/images.totalglobalsports.com/PlayerForm/_da463c0400e545cbbfb1f23ee81d0cde_afc.png",
-        "firstName": "Thomas",
-        "lastName": "Brower",
-        "wdl": "32W 10D 16L",
-        "flightRequested": null,
-        "currentFlightID": 0,
-        "currentFlight": "ECNL",
-        "currentFlightIDString": "19789"
-      },
-...
-*/
-
-// Index indexes the collection.
-func (dao *TeamDAO) Index() error {
-	var (
-		err  error
-		name string
-	)
-
-	if name, err = dao.col.Indexes().CreateOne(dao.ctx, mongo.IndexModel{
-		Keys: bson.D{{"id", 1}},
-	}); err != nil {
-		return err
-	} else {
-		log.Printf("created index %s on teams collection", name)
-	}
-
-	if name, err = dao.col.Indexes().CreateOne(dao.ctx, mongo.IndexModel{
-		Keys: bson.D{{"name", 1}},
-	}); err != nil {
-		return err
-	} else {
-		log.Printf("created index %s on teams collection", name)
-	}
-
-	if name, err = dao.col.Indexes().CreateOne(dao.ctx, mongo.IndexModel{
-		Keys: bson.D{{"clubid", 1}},
-	}); err != nil {
-		return err
-	} else {
-		log.Printf("created index %s on teams collection", name)
-	}
-
-	return nil
-}
-
-// GetAll gets all teams.
-func (dao *TeamDAO) GetAll() ([]models.Team, error) {
-	var (
-		cursor *mongo.Cursor
-		err    error
-	)
-
-	if cursor, err = dao.col.Find(dao.ctx, bson.M{}); err != nil {
-		return nil, err
-	}
-
-	var bteams []bson.M
-	if err = cursor.All(dao.ctx, &bteams); err != nil {
-		return nil, err
-	}
-
-	var teams []models.Team
-
-	for _, bteam := range bteams {
-		var team models.Team
-
-		bsonBytes, _ := bson.Marshal(bteam)
-		if err = bson.Unmarshal(bsonBytes, &team); err != nil {
-			return nil, err
-		}
-
-		teams = append(teams, team)
-	}
-
-	return teams, nil
-}
-
-// GetByName gets a team by name.
-func (dao *TeamDAO) GetByName(name string) (*models.Team, error) {
-	var (
-		err   error
-		bteam bson.M
-	)
-
-	if err = dao.col.FindOne(dao.ctx, bson.M{"name": name}).Decode(&bteam); err != nil {
-		return nil, err
-	}
-
-	var team models.Team
-
-	bsonBytes, _ := bson.Marshal(bteam)
-	if err = bson.Unmarshal(bsonBytes, &team); err != nil {
-		return nil, err
-	}
-
-	return &team, nil
-}
-
-// GetById gets a team by id.
-func (dao *TeamDAO) GetById(id int) (*models.Team, error) {
-	var (
-		err   error
-		bteam bson.M
-	)
-
-	if err = dao.col.FindOne(dao.ctx, bson.M{"id": id}).Decode(&bteam); err != nil {
-		return nil, err
-	}
-
-	var team models.Team
-
-	bsonBytes, _ := bson.Marshal(bteam)
-	if err = bson.Unmarshal(bsonBytes, &team); err != nil {
-		return nil, err
-	}
-
-	return &team, nil
-}
-
-// Update updates a team.
-func (dao *TeamDAO) Update(team models.Team) error {
-	var (
-		err          error
-		updateResult *mongo.UpdateResult
-	)
-
-	log.Println("updating team: ", team.String())
-
-	if updateResult, err = dao.col.UpdateOne(dao.ctx, bson.M{"id": team.Id}, bson.M{"$set": team}); err != nil {
-		return err
-	}
-
-	// Check to see if the update was successful.
-	if updateResult.MatchedCount != 1 {
-		return fmt.Errorf("update failed")
-	}
-
-	// The update was successful.
-	log.Printf("team update successful: %v", updateResult)
-
-	return nil
-}
-
-// Delete deletes a team.
-func (dao *TeamDAO) Delete(team models.Team) error {
-	return dao.DeleteById(team.Id)
-}
-
-// DeleteByName deletes a team by name.
-func (dao *TeamDAO) DeleteByName(name string) error {
-	var (
-		err          error
-		deleteResult *mongo.DeleteResult
-	)
-
-	if deleteResult, err = dao.col.DeleteOne(dao.ctx, bson.M{"name": name}); err != nil {
-		return err
-	}
-
-	// Check to see if the delete was successful.
-	if deleteResult.DeletedCount != 1 {
-		return fmt.Errorf("delete failed")
-	}
-
-	// The delete was successful.
-	log.Printf("team delete successful: %v", deleteResult)
-
-	return nil
+// WithRemoved returns a copy of the data access object whose getters include the teams removed upstream.
+func (dao *TeamDAO) WithRemoved() TeamDAOer {
+	return &TeamDAO{dao.Repository.WithRemoved()}
 }
 
-// DeleteById deletes a team by id.
-func (dao *TeamDAO) DeleteById(id int) error {
-	var (
-		err          error
-		deleteResult *mongo.DeleteResult
-	)
-
-	if deleteResult, err = dao.col.DeleteOne(dao.ctx, bson.M{"id": id}); err != nil {
-		return err
-	}
-
-	// Check to see if the delete was successful.
-	if deleteResult.DeletedCount != 1 {
-		return fmt.Errorf("delete failed")
-	}
-
-	// The delete was successful.
-	log.Printf("team delete successful: %v", deleteResult)
-
-	return nil
+// GetById gets the team by id.
+func (dao *TeamDAO) GetById(ctx context.Context, id int) (*models.Team, error) {
+	return dao.GetByKey(ctx, id)
 }
 
-// Create creates a team.
-func (dao *TeamDAO) Create(team models.Team) error {
-	var (
-		err error
-	)
-
-	if _, err = dao.col.InsertOne(dao.ctx, team); err != nil {
-		return err
-	}
-
-	return nil
+// Delete deletes the team.
+func (dao *TeamDAO) Delete(ctx context.Context, team models.Team) error {
+	return dao.DeleteByKey(ctx, team.Id)
 }
 
-// Exists checks if a team exists.
-func (dao *TeamDAO) Exists(team models.Team) (bool, error) {
-	var (
-		err   error
-		bTeam bson.M
-	)
-
-	if err = dao.col.FindOne(dao.ctx, bson.M{"id": team.Id}).Decode(&bTeam); err != nil {
-		if err.Error() == "mongo: no documents in result" {
-			return false, nil
-		}
-
-		return false, err
-	}
-
-	return true, nil
+// DeleteById deletes the team by id.
+func (dao *TeamDAO) DeleteById(ctx context.Context, id int) error {
+	return dao.DeleteByKey(ctx, id)
 }
 
-// ExistsByName checks if a team exists by name.
-func (dao *TeamDAO) ExistsByName(name string) (bool, error) {
-	var (
-		err   error
-		count int64
-	)
-
-	if count, err = dao.col.CountDocuments(dao.ctx, bson.M{"name": name}); err != nil {
-		return false, err
-	}
-
-	return count >= 1, nil
+// Exists checks to see if the team exists.
+func (dao *TeamDAO) Exists(ctx context.Context, team models.Team) (bool, error) {
+	return dao.ExistsByKey(ctx, team.Id)
 }
 
-// ExistsById checks if a team exists by id.
-func (dao *TeamDAO) ExistsById(id int) (bool, error) {
-	var (
-		err   error
-		count int64
-	)
-
-	if count, err = dao.col.CountDocuments(dao.ctx, bson.M{"id": id}); err != nil {
-		return false, err
-	}
-
-	return count >= 1, nil
+// ExistsById checks to see if the team exists by id.
+func (dao *TeamDAO) ExistsById(ctx context.Context, id int) (bool, error) {
+	return dao.ExistsByKey(ctx, id)
 }
 
-// Sync syncs a team.
-func (dao *TeamDAO) Sync(team models.Team) error {
-	var (
-		err    error
-		answer bool
-	)
-
-	if answer, err = dao.Exists(team); err != nil {
-		return err
-	}
-
-	if !answer {
-		// Create the team.
-		if err = dao.Create(team); err != nil {
-			return err
-		}
-
-		log.Printf("team '%s' created", team.Name)
-	} else {
-		// Update the team.
-		if err = dao.Update(team); err != nil {
-			return err
-		}
-
-		log.Printf("team '%s' updated", team.Name)
-	}
+// Sync creates or replaces the team.
+func (dao *TeamDAO) Sync(ctx context.Context, team models.Team) error {
+	_, err := dao.SyncAll(ctx, []*models.Team{&team})
 
-	return nil
+	return err
 }
 
-// SyncAll syncs all teams.
-func (dao *TeamDAO) SyncAll(teams []*models.Team) error {
+// SyncAll creates or replaces the teams in bulk, keyed on their id.
+func (dao *TeamDAO) SyncAll(ctx context.Context, teams []*models.Team) (SyncSummary, error) {
+	values := make([]models.Team, 0, len(teams))
 	for _, team := range teams {
-		if err := dao.Sync(*team); err != nil {
-			return err
-		}
+		values = append(values, *team)
 	}
 
-	return nil
+	return dao.Repository.SyncAll(ctx, values)
 }
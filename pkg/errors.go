@@ -0,0 +1,60 @@
+package pkg
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+var (
+	// ErrNotFound is returned when a requested entity does not exist.
+	ErrNotFound = errors.New("not found")
+	// ErrDuplicate is returned when an entity with the same natural key is already stored.
+	ErrDuplicate = errors.New("duplicate")
+	// ErrInvalid is returned when a request asks for something that cannot be answered, e.g. the history of team id 0.
+	ErrInvalid = errors.New("invalid")
+	// ErrUpstreamFailure is returned when TGS responds with a result other than success.
+	ErrUpstreamFailure = errors.New("upstream failure")
+)
+
+// SnippetLength is the number of bytes of a response body kept by a DecodeError.
+const SnippetLength = 256
+
+// UpstreamStatusError is returned when TGS responds with an unexpected HTTP status code.
+type UpstreamStatusError struct {
+	Code int
+	URL  string
+}
+
+func (e *UpstreamStatusError) Error() string {
+	return fmt.Sprintf("unexpected status code %d from %s", e.Code, e.URL)
+}
+
+// Is makes a 404 from upstream match ErrNotFound.
+func (e *UpstreamStatusError) Is(target error) bool {
+	return target == ErrNotFound && e.Code == http.StatusNotFound
+}
+
+// DecodeError is returned when a response body cannot be decoded.
+type DecodeError struct {
+	URL  string
+	Body string
+	Err  error
+}
+
+// NewDecodeError creates a decode error that keeps a snippet of the offending body.
+func NewDecodeError(url string, body []byte, err error) *DecodeError {
+	if len(body) > SnippetLength {
+		body = body[:SnippetLength]
+	}
+
+	return &DecodeError{URL: url, Body: string(body), Err: err}
+}
+
+func (e *DecodeError) Error() string {
+	return fmt.Sprintf("error decoding response from %s: %v: %q", e.URL, e.Err, e.Body)
+}
+
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
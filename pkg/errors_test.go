@@ -0,0 +1,52 @@
+package pkg_test
+
+import (
+	"errors"
+	"fmt"
+	"github.com/jedi-knights/ecnl/pkg"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"net/http"
+	"strings"
+)
+
+var _ = Describe("Errors", func() {
+	Describe("UpstreamStatusError", func() {
+		It("should match ErrNotFound for a 404", func() {
+			// Arrange
+			err := fmt.Errorf("wrapped: %w", &pkg.UpstreamStatusError{Code: http.StatusNotFound, URL: "/x"})
+
+			// Act
+			var statusErr *pkg.UpstreamStatusError
+			found := errors.As(err, &statusErr)
+
+			// Assert
+			Expect(found).To(BeTrue())
+			Expect(statusErr.Code).To(Equal(http.StatusNotFound))
+			Expect(errors.Is(err, pkg.ErrNotFound)).To(BeTrue())
+		})
+
+		It("should not match ErrNotFound for other status codes", func() {
+			// Arrange
+			err := &pkg.UpstreamStatusError{Code: http.StatusBadGateway, URL: "/x"}
+
+			// Act & Assert
+			Expect(errors.Is(err, pkg.ErrNotFound)).To(BeFalse())
+		})
+	})
+
+	Describe("DecodeError", func() {
+		It("should keep a snippet of the body and unwrap the cause", func() {
+			// Arrange
+			cause := errors.New("boom")
+			body := []byte(strings.Repeat("x", 1000))
+
+			// Act
+			err := pkg.NewDecodeError("/x", body, cause)
+
+			// Assert
+			Expect(err.Body).To(HaveLen(256))
+			Expect(errors.Is(err, cause)).To(BeTrue())
+		})
+	})
+})
@@ -0,0 +1,81 @@
+package migrations
+
+import (
+	"context"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// All are the migrations of the database, in the order they were written.
+// A migration is never changed once released, a later one undoes what it got wrong.
+var All = []Migration{
+	{Version: 1, Name: "baseline_indexes", Up: baselineUp, Down: baselineDown},
+	{Version: 2, Name: "unique_natural_keys", Up: naturalKeysUp, Down: naturalKeysDown},
+	{Version: 3, Name: "rpi_snapshots", Up: rpiSnapshotsUp, Down: rpiSnapshotsDown},
+}
+
+// baseline are the indexes the DAOs created before there were migrations, by collection.
+// They are copied here so that the migration does not change when the DAOs do.
+var baseline = map[string][]mongo.IndexModel{
+	"organizations": {
+		{Keys: bson.D{{Key: "id", Value: 1}, {Key: "name", Value: 1}}},
+	},
+	"clubs": {
+		{Keys: bson.D{{Key: "orgid", Value: 1}, {Key: "clubid", Value: 1}, {Key: "name", Value: 1}, {Key: "statecode", Value: 1}}},
+	},
+	"events": {
+		{Keys: bson.D{{Key: "id", Value: 1}, {Key: "orgid", Value: 1}, {Key: "name", Value: 1}, {Key: "orgname", Value: 1}}},
+	},
+	"teams": {
+		{Keys: bson.D{{Key: "id", Value: 1}}},
+		{Keys: bson.D{{Key: "name", Value: 1}}},
+		{Keys: bson.D{{Key: "clubid", Value: 1}}},
+	},
+	"matches": {
+		{Keys: bson.D{{Key: "matchid", Value: 1}, {Key: "hometeamname", Value: 1}, {Key: "awayteamname", Value: 1}, {Key: "division", Value: 1}}},
+	},
+	"rpi_events": {
+		{Keys: bson.D{{Key: "team_id", Value: 1}}},
+		{Keys: bson.D{{Key: "team_name", Value: 1}}},
+	},
+	"match_history": {
+		{Keys: bson.D{{Key: "changedat", Value: -1}}},
+		{Keys: bson.D{{Key: "matchid", Value: 1}, {Key: "changedat", Value: -1}}},
+	},
+	"runs": {
+		{Keys: bson.D{{Key: "id", Value: 1}}, Options: options.Index().SetUnique(true)},
+		{Keys: bson.D{{Key: "command", Value: 1}, {Key: "startedat", Value: -1}}},
+	},
+	"sync_state": {
+		{Keys: bson.D{{Key: "key", Value: 1}}, Options: options.Index().SetUnique(true)},
+	},
+	"leases": {
+		{Keys: bson.D{{Key: "name", Value: 1}}, Options: options.Index().SetUnique(true)},
+	},
+}
+
+// baselineUp creates the baseline indexes, a database that was synced before already has them.
+// An index that a later version of the DAOs created with other options is left for the later migrations.
+func baselineUp(ctx context.Context, db *mongo.Database) error {
+	for collection, indexes := range baseline {
+		for _, index := range indexes {
+			if err := createIndexes(ctx, db, collection, index); err != nil && !hasCode(err, indexOptionsConflict, indexKeySpecsConflict) {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// baselineDown drops the baseline indexes.
+func baselineDown(ctx context.Context, db *mongo.Database) error {
+	for collection, indexes := range baseline {
+		if err := dropIndexes(ctx, db, collection, indexes...); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,85 @@
+package migrations
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"log"
+	"strings"
+)
+
+// The server codes of the errors dropping an index that is already gone
+// and creating one that exists with other options.
+const (
+	namespaceNotFound     = 26
+	indexNotFound         = 27
+	indexOptionsConflict  = 85
+	indexKeySpecsConflict = 86
+)
+
+// createIndexes creates the indexes on the collection, the ones that already exist are left alone.
+func createIndexes(ctx context.Context, db *mongo.Database, collection string, indexes ...mongo.IndexModel) error {
+	names, err := db.Collection(collection).Indexes().CreateMany(ctx, indexes)
+	if err != nil {
+		return fmt.Errorf("error indexing %s: %w", collection, err)
+	}
+
+	for _, name := range names {
+		log.Printf("created index %s on %s collection", name, collection)
+	}
+
+	return nil
+}
+
+// dropIndexes drops the indexes on the collection, the ones that are already gone are skipped.
+func dropIndexes(ctx context.Context, db *mongo.Database, collection string, indexes ...mongo.IndexModel) error {
+	for _, index := range indexes {
+		name := indexName(index)
+
+		if _, err := db.Collection(collection).Indexes().DropOne(ctx, name); err != nil {
+			if hasCode(err, indexNotFound, namespaceNotFound) {
+				continue
+			}
+
+			return fmt.Errorf("error dropping index %s on %s: %w", name, collection, err)
+		}
+
+		log.Printf("dropped index %s on %s collection", name, collection)
+	}
+
+	return nil
+}
+
+// indexName is the name of the index, the one it was given or the one the server gives it by default, e.g. name_1_clubid_1.
+func indexName(index mongo.IndexModel) string {
+	if index.Options != nil && index.Options.Name != nil {
+		return *index.Options.Name
+	}
+
+	var parts []string
+
+	for _, key := range index.Keys.(bson.D) {
+		parts = append(parts, fmt.Sprintf("%s_%v", key.Key, key.Value))
+	}
+
+	return strings.Join(parts, "_")
+}
+
+// hasCode returns true when err is a server error with one of the codes.
+func hasCode(err error, codes ...int32) bool {
+	var commandErr mongo.CommandError
+
+	if !errors.As(err, &commandErr) {
+		return false
+	}
+
+	for _, code := range codes {
+		if commandErr.Code == code {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,76 @@
+package migrations
+
+import (
+	"context"
+	"fmt"
+	"github.com/jedi-knights/ecnl/pkg"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"log"
+)
+
+// Collection is the collection of the ledger in the database.
+const Collection = "schema_migrations"
+
+// MongoLedger keeps the ledger in a collection, one document per applied migration.
+type MongoLedger struct {
+	col *mongo.Collection
+}
+
+// NewMongoLedger creates a ledger backed by col.
+func NewMongoLedger(col *mongo.Collection) *MongoLedger {
+	return &MongoLedger{col: col}
+}
+
+// Index indexes the collection, a version is recorded at most once.
+func (l *MongoLedger) Index(ctx context.Context) error {
+	name, err := l.col.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "version", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+	if err != nil {
+		return err
+	}
+
+	log.Printf("created index %s on %s collection", name, Collection)
+
+	return nil
+}
+
+// Applied gets the records of the applied migrations, in the order of their versions.
+func (l *MongoLedger) Applied(ctx context.Context) ([]Record, error) {
+	var records []Record
+
+	cursor, err := l.col.Find(ctx, bson.M{}, options.Find().SetSort(bson.D{{Key: "version", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+
+	if err = cursor.All(ctx, &records); err != nil {
+		return nil, err
+	}
+
+	return records, nil
+}
+
+// Record records an applied migration.
+func (l *MongoLedger) Record(ctx context.Context, record Record) error {
+	_, err := l.col.InsertOne(ctx, record)
+
+	return err
+}
+
+// Forget deletes the record of a reverted migration.
+func (l *MongoLedger) Forget(ctx context.Context, version int) error {
+	result, err := l.col.DeleteOne(ctx, bson.M{"version": version})
+	if err != nil {
+		return err
+	}
+
+	if result.DeletedCount != 1 {
+		return fmt.Errorf("forget migration %d: %w", version, pkg.ErrNotFound)
+	}
+
+	return nil
+}
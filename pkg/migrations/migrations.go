@@ -0,0 +1,218 @@
+package migrations
+
+import (
+	"context"
+	"fmt"
+	"go.mongodb.org/mongo-driver/mongo"
+	"log"
+	"sort"
+	"time"
+)
+
+// Migration is a versioned change to the schema of the database, e.g. a renamed field or a new index.
+// Migrations are applied in the order of their versions and reverted in the opposite order.
+type Migration struct {
+	Version int
+	Name    string
+	Up      func(ctx context.Context, db *mongo.Database) error
+	// Down reverts Up, it is nil when the migration cannot be reverted.
+	Down func(ctx context.Context, db *mongo.Database) error
+}
+
+func (m Migration) String() string {
+	return fmt.Sprintf("%d %s", m.Version, m.Name)
+}
+
+// Record is the entry of an applied migration in the ledger.
+type Record struct {
+	Version   int       `bson:"version" json:"version"`
+	Name      string    `bson:"name" json:"name"`
+	AppliedAt time.Time `bson:"appliedat" json:"appliedAt"`
+}
+
+// Ledger keeps the records of the applied migrations.
+type Ledger interface {
+	// Applied gets the records of the applied migrations, in the order of their versions.
+	Applied(ctx context.Context) ([]Record, error)
+	Record(ctx context.Context, record Record) error
+	Forget(ctx context.Context, version int) error
+}
+
+// Status is the state of a migration in the database.
+type Status struct {
+	Version   int        `json:"version"`
+	Name      string     `json:"name"`
+	AppliedAt *time.Time `json:"appliedAt,omitempty"`
+	// Unknown is set for a migration the database has applied that this version of ecnl does not know.
+	Unknown bool `json:"unknown,omitempty"`
+}
+
+// Applied returns true when the migration is applied.
+func (s Status) Applied() bool {
+	return s.AppliedAt != nil
+}
+
+// Migrator applies and reverts the migrations and keeps track of them in a ledger.
+type Migrator struct {
+	db         *mongo.Database
+	ledger     Ledger
+	migrations []Migration
+}
+
+// NewMigrator creates a migrator for db that keeps its ledger in ledger.
+// It fails when two migrations share a version.
+func NewMigrator(db *mongo.Database, ledger Ledger, migrations []Migration) (*Migrator, error) {
+	sorted := make([]Migration, len(migrations))
+	copy(sorted, migrations)
+
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Version < sorted[j].Version
+	})
+
+	for i, migration := range sorted {
+		if migration.Version <= 0 {
+			return nil, fmt.Errorf("migration %s: the version must be positive", migration)
+		}
+
+		if i > 0 && sorted[i-1].Version == migration.Version {
+			return nil, fmt.Errorf("migrations %s and %s share a version", sorted[i-1], migration)
+		}
+	}
+
+	return &Migrator{db: db, ledger: ledger, migrations: sorted}, nil
+}
+
+// Status gets the state of every migration, in the order of their versions.
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	var (
+		err      error
+		records  []Record
+		statuses []Status
+	)
+
+	if records, err = m.ledger.Applied(ctx); err != nil {
+		return nil, err
+	}
+
+	applied := make(map[int]Record, len(records))
+	for _, record := range records {
+		applied[record.Version] = record
+	}
+
+	for _, migration := range m.migrations {
+		status := Status{Version: migration.Version, Name: migration.Name}
+
+		if record, ok := applied[migration.Version]; ok {
+			status.AppliedAt = &record.AppliedAt
+			delete(applied, migration.Version)
+		}
+
+		statuses = append(statuses, status)
+	}
+
+	for _, record := range applied {
+		record := record
+		statuses = append(statuses, Status{Version: record.Version, Name: record.Name, AppliedAt: &record.AppliedAt, Unknown: true})
+	}
+
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].Version < statuses[j].Version
+	})
+
+	return statuses, nil
+}
+
+// Up applies the pending migrations up to and including version target, or all of them when target is zero,
+// and returns the ones it applied. It stops at the first migration that fails.
+// It refuses to run against a database that has applied migrations this version of ecnl does not know.
+func (m *Migrator) Up(ctx context.Context, target int) ([]Migration, error) {
+	var (
+		err      error
+		statuses []Status
+		applied  []Migration
+	)
+
+	if statuses, err = m.Status(ctx); err != nil {
+		return nil, err
+	}
+
+	pending := make(map[int]bool)
+
+	for _, status := range statuses {
+		if status.Unknown {
+			return nil, fmt.Errorf("the database has applied migration %d %s which this version of ecnl does not know", status.Version, status.Name)
+		}
+
+		if !status.Applied() {
+			pending[status.Version] = true
+		}
+	}
+
+	for _, migration := range m.migrations {
+		if target > 0 && migration.Version > target {
+			break
+		}
+
+		if !pending[migration.Version] {
+			continue
+		}
+
+		log.Printf("applying migration %s", migration)
+
+		if err = migration.Up(ctx, m.db); err != nil {
+			return applied, fmt.Errorf("error applying migration %s: %w", migration, err)
+		}
+
+		if err = m.ledger.Record(ctx, Record{Version: migration.Version, Name: migration.Name, AppliedAt: time.Now().UTC()}); err != nil {
+			return applied, fmt.Errorf("error recording migration %s: %w", migration, err)
+		}
+
+		applied = append(applied, migration)
+	}
+
+	return applied, nil
+}
+
+// Down reverts the last steps applied migrations, the most recent first, and returns the ones it reverted.
+// It stops at the first migration that fails or cannot be reverted.
+func (m *Migrator) Down(ctx context.Context, steps int) ([]Migration, error) {
+	var (
+		err      error
+		records  []Record
+		reverted []Migration
+	)
+
+	if records, err = m.ledger.Applied(ctx); err != nil {
+		return nil, err
+	}
+
+	known := make(map[int]Migration, len(m.migrations))
+	for _, migration := range m.migrations {
+		known[migration.Version] = migration
+	}
+
+	for i := len(records) - 1; i >= 0 && len(reverted) < steps; i-- {
+		migration, ok := known[records[i].Version]
+
+		switch {
+		case !ok:
+			return reverted, fmt.Errorf("cannot revert migration %d %s which this version of ecnl does not know", records[i].Version, records[i].Name)
+		case migration.Down == nil:
+			return reverted, fmt.Errorf("migration %s cannot be reverted", migration)
+		}
+
+		log.Printf("reverting migration %s", migration)
+
+		if err = migration.Down(ctx, m.db); err != nil {
+			return reverted, fmt.Errorf("error reverting migration %s: %w", migration, err)
+		}
+
+		if err = m.ledger.Forget(ctx, migration.Version); err != nil {
+			return reverted, fmt.Errorf("error forgetting migration %s: %w", migration, err)
+		}
+
+		reverted = append(reverted, migration)
+	}
+
+	return reverted, nil
+}
@@ -0,0 +1,214 @@
+package migrations_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/jedi-knights/ecnl/pkg/migrations"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.mongodb.org/mongo-driver/mongo"
+	"sort"
+)
+
+// ledger is an in memory Ledger.
+type ledger struct {
+	records []migrations.Record
+}
+
+func (l *ledger) Applied(_ context.Context) ([]migrations.Record, error) {
+	return l.records, nil
+}
+
+func (l *ledger) Record(_ context.Context, record migrations.Record) error {
+	l.records = append(l.records, record)
+
+	sort.Slice(l.records, func(i, j int) bool {
+		return l.records[i].Version < l.records[j].Version
+	})
+
+	return nil
+}
+
+func (l *ledger) Forget(_ context.Context, version int) error {
+	for i, record := range l.records {
+		if record.Version == version {
+			l.records = append(l.records[:i], l.records[i+1:]...)
+			return nil
+		}
+	}
+
+	return fmt.Errorf("migration %d is not applied", version)
+}
+
+var _ = Describe("Migrator", func() {
+	var (
+		ctx    context.Context
+		steps  []string
+		book   *ledger
+		all    []migrations.Migration
+		record func(step string) func(context.Context, *mongo.Database) error
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		steps = nil
+		book = &ledger{}
+
+		record = func(step string) func(context.Context, *mongo.Database) error {
+			return func(context.Context, *mongo.Database) error {
+				steps = append(steps, step)
+				return nil
+			}
+		}
+
+		all = []migrations.Migration{
+			{Version: 2, Name: "second", Up: record("up 2"), Down: record("down 2")},
+			{Version: 1, Name: "first", Up: record("up 1"), Down: record("down 1")},
+			{Version: 3, Name: "third", Up: record("up 3"), Down: record("down 3")},
+		}
+	})
+
+	It("should refuse migrations that share a version", func() {
+		// Arrange
+		all = append(all, migrations.Migration{Version: 2, Name: "again", Up: record("up 2")})
+
+		// Act
+		_, err := migrations.NewMigrator(nil, book, all)
+
+		// Assert
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should apply the pending migrations in the order of their versions", func() {
+		// Arrange
+		migrator, err := migrations.NewMigrator(nil, book, all)
+		Expect(err).NotTo(HaveOccurred())
+
+		// Act
+		applied, err := migrator.Up(ctx, 0)
+
+		// Assert
+		Expect(err).NotTo(HaveOccurred())
+		Expect(applied).To(HaveLen(3))
+		Expect(steps).To(Equal([]string{"up 1", "up 2", "up 3"}))
+		Expect(book.records).To(HaveLen(3))
+	})
+
+	It("should not apply a migration twice", func() {
+		// Arrange
+		migrator, err := migrations.NewMigrator(nil, book, all)
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = migrator.Up(ctx, 2)
+		Expect(err).NotTo(HaveOccurred())
+
+		// Act
+		applied, err := migrator.Up(ctx, 0)
+
+		// Assert
+		Expect(err).NotTo(HaveOccurred())
+		Expect(applied).To(HaveLen(1))
+		Expect(steps).To(Equal([]string{"up 1", "up 2", "up 3"}))
+	})
+
+	It("should stop at the first migration that fails", func() {
+		// Arrange
+		all[0].Up = func(context.Context, *mongo.Database) error { return errors.New("boom") }
+
+		migrator, err := migrations.NewMigrator(nil, book, all)
+		Expect(err).NotTo(HaveOccurred())
+
+		// Act
+		applied, err := migrator.Up(ctx, 0)
+
+		// Assert
+		Expect(err).To(MatchError(ContainSubstring("2 second")))
+		Expect(applied).To(HaveLen(1))
+		Expect(book.records).To(HaveLen(1))
+	})
+
+	It("should revert the most recent migrations first", func() {
+		// Arrange
+		migrator, err := migrations.NewMigrator(nil, book, all)
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = migrator.Up(ctx, 0)
+		Expect(err).NotTo(HaveOccurred())
+
+		// Act
+		reverted, err := migrator.Down(ctx, 2)
+
+		// Assert
+		Expect(err).NotTo(HaveOccurred())
+		Expect(reverted).To(HaveLen(2))
+		Expect(steps[3:]).To(Equal([]string{"down 3", "down 2"}))
+		Expect(book.records).To(HaveLen(1))
+		Expect(book.records[0].Version).To(Equal(1))
+	})
+
+	It("should refuse to revert a migration without a down", func() {
+		// Arrange
+		all[2].Down = nil
+
+		migrator, err := migrations.NewMigrator(nil, book, all)
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = migrator.Up(ctx, 0)
+		Expect(err).NotTo(HaveOccurred())
+
+		// Act
+		reverted, err := migrator.Down(ctx, 1)
+
+		// Assert
+		Expect(err).To(HaveOccurred())
+		Expect(reverted).To(BeEmpty())
+		Expect(book.records).To(HaveLen(3))
+	})
+
+	It("should report the applied, pending and unknown migrations", func() {
+		// Arrange
+		migrator, err := migrations.NewMigrator(nil, book, all)
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = migrator.Up(ctx, 1)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(book.Record(ctx, migrations.Record{Version: 7, Name: "newer"})).To(Succeed())
+
+		// Act
+		statuses, err := migrator.Status(ctx)
+
+		// Assert
+		Expect(err).NotTo(HaveOccurred())
+		Expect(statuses).To(HaveLen(4))
+		Expect(statuses[0].Applied()).To(BeTrue())
+		Expect(statuses[1].Applied()).To(BeFalse())
+		Expect(statuses[2].Applied()).To(BeFalse())
+		Expect(statuses[3].Unknown).To(BeTrue())
+	})
+
+	It("should refuse to migrate a database that applied unknown migrations", func() {
+		// Arrange
+		migrator, err := migrations.NewMigrator(nil, book, all)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(book.Record(ctx, migrations.Record{Version: 7, Name: "newer"})).To(Succeed())
+
+		// Act
+		applied, err := migrator.Up(ctx, 0)
+
+		// Assert
+		Expect(err).To(HaveOccurred())
+		Expect(applied).To(BeEmpty())
+		Expect(steps).To(BeEmpty())
+	})
+
+	It("should have migrations with distinct versions", func() {
+		// Act
+		_, err := migrations.NewMigrator(nil, book, migrations.All)
+
+		// Assert
+		Expect(err).NotTo(HaveOccurred())
+	})
+})
@@ -0,0 +1,160 @@
+package migrations
+
+import (
+	"context"
+	"fmt"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"log"
+)
+
+// naturalKey is a collection whose documents are identified by the key fields.
+type naturalKey struct {
+	collection string
+	fields     []string
+	// indexes replace the baseline ones of the collection, the first is the unique index on the key.
+	indexes []mongo.IndexModel
+}
+
+// naturalKeys are the collections that get a unique index on their natural key.
+var naturalKeys = []naturalKey{
+	{
+		collection: "organizations",
+		fields:     []string{"id"},
+		indexes: []mongo.IndexModel{
+			{Keys: bson.D{{Key: "id", Value: 1}}, Options: options.Index().SetUnique(true)},
+			{Keys: bson.D{{Key: "name", Value: 1}}},
+		},
+	},
+	{
+		collection: "clubs",
+		fields:     []string{"clubid"},
+		indexes: []mongo.IndexModel{
+			{Keys: bson.D{{Key: "clubid", Value: 1}}, Options: options.Index().SetUnique(true)},
+			{Keys: bson.D{{Key: "name", Value: 1}}},
+			{Keys: bson.D{{Key: "orgid", Value: 1}}},
+		},
+	},
+	{
+		collection: "events",
+		fields:     []string{"id"},
+		indexes: []mongo.IndexModel{
+			{Keys: bson.D{{Key: "id", Value: 1}}, Options: options.Index().SetUnique(true)},
+			{Keys: bson.D{{Key: "name", Value: 1}}},
+			{Keys: bson.D{{Key: "orgid", Value: 1}}},
+		},
+	},
+	{
+		collection: "teams",
+		fields:     []string{"id"},
+		indexes: []mongo.IndexModel{
+			{Keys: bson.D{{Key: "id", Value: 1}}, Options: options.Index().SetUnique(true)},
+			{Keys: bson.D{{Key: "name", Value: 1}}},
+			{Keys: bson.D{{Key: "clubid", Value: 1}}},
+		},
+	},
+	{
+		collection: "matches",
+		fields:     []string{"matchid"},
+		indexes: []mongo.IndexModel{
+			{Keys: bson.D{{Key: "matchid", Value: 1}}, Options: options.Index().SetUnique(true)},
+			{Keys: bson.D{{Key: "division", Value: 1}, {Key: "flight", Value: 1}}},
+			{Keys: bson.D{{Key: "hometeamid", Value: 1}}},
+			{Keys: bson.D{{Key: "awayteamid", Value: 1}}},
+			{Keys: bson.D{{Key: "hometeamname", Value: 1}}},
+			{Keys: bson.D{{Key: "awayteamname", Value: 1}}},
+		},
+	},
+	{
+		// The events of a ranking share its timestamp and name every team once, a team id is not unique within it.
+		collection: "rpi_events",
+		fields:     []string{"team_name", "timestamp"},
+		indexes: []mongo.IndexModel{
+			{Keys: bson.D{{Key: "team_name", Value: 1}, {Key: "timestamp", Value: 1}}, Options: options.Index().SetUnique(true)},
+			{Keys: bson.D{{Key: "team_id", Value: 1}}},
+		},
+	},
+}
+
+// naturalKeysUp replaces the baseline indexes with unique ones on the natural keys.
+// Documents that share a key are removed first, only the most recently inserted one is kept.
+func naturalKeysUp(ctx context.Context, db *mongo.Database) error {
+	for _, key := range naturalKeys {
+		if err := dropIndexes(ctx, db, key.collection, baseline[key.collection]...); err != nil {
+			return err
+		}
+
+		if err := deduplicate(ctx, db.Collection(key.collection), key.fields); err != nil {
+			return err
+		}
+
+		if err := createIndexes(ctx, db, key.collection, key.indexes...); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// naturalKeysDown restores the baseline indexes, the duplicates that were removed are not restored.
+func naturalKeysDown(ctx context.Context, db *mongo.Database) error {
+	for _, key := range naturalKeys {
+		if err := dropIndexes(ctx, db, key.collection, key.indexes...); err != nil {
+			return err
+		}
+
+		if err := createIndexes(ctx, db, key.collection, baseline[key.collection]...); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// deduplicate deletes the documents of col that share the values of fields with a more recently inserted one.
+func deduplicate(ctx context.Context, col *mongo.Collection, fields []string) error {
+	var duplicates []struct {
+		Ids []any `bson:"ids"`
+	}
+
+	group := bson.M{}
+	for _, field := range fields {
+		group[field] = "$" + field
+	}
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$sort", Value: bson.D{{Key: "_id", Value: -1}}}},
+		{{Key: "$group", Value: bson.M{"_id": group, "ids": bson.M{"$push": "$_id"}, "count": bson.M{"$sum": 1}}}},
+		{{Key: "$match", Value: bson.M{"count": bson.M{"$gt": 1}}}},
+	}
+
+	cursor, err := col.Aggregate(ctx, pipeline, options.Aggregate().SetAllowDiskUse(true))
+	if err != nil {
+		return fmt.Errorf("error finding the duplicates in %s: %w", col.Name(), err)
+	}
+
+	if err = cursor.All(ctx, &duplicates); err != nil {
+		return fmt.Errorf("error finding the duplicates in %s: %w", col.Name(), err)
+	}
+
+	var ids []any
+
+	for _, duplicate := range duplicates {
+		// The ids are sorted the most recent first, the first one is kept.
+		ids = append(ids, duplicate.Ids[1:]...)
+	}
+
+	if len(ids) == 0 {
+		return nil
+	}
+
+	result, err := col.DeleteMany(ctx, bson.M{"_id": bson.M{"$in": ids}})
+	if err != nil {
+		return fmt.Errorf("error deleting the duplicates in %s: %w", col.Name(), err)
+	}
+
+	log.Printf("deleted %d duplicate documents in %s collection", result.DeletedCount, col.Name())
+
+	return nil
+}
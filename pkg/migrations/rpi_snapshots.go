@@ -0,0 +1,140 @@
+package migrations
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"log"
+	"time"
+)
+
+// rpiSnapshotIndexes are the indexes of the RPI snapshots and of the RPI events that are their rows, by collection.
+var rpiSnapshotIndexes = map[string][]mongo.IndexModel{
+	"rpi_snapshots": {
+		{Keys: bson.D{{Key: "id", Value: 1}}, Options: options.Index().SetUnique(true)},
+		{Keys: bson.D{{Key: "agegroup", Value: 1}, {Key: "matchcutoff", Value: -1}, {Key: "computedat", Value: -1}}},
+	},
+	"rpi_events": {
+		{Keys: bson.D{{Key: "snapshot_id", Value: 1}, {Key: "ranking", Value: 1}}},
+	},
+}
+
+// rpiEventKey is the unique key the RPI events had before they were the rows of a snapshot,
+// rpiRowKey the one that replaces it. Rankings of different age groups computed at the same time
+// can name the same team, only the snapshot tells their rows apart.
+var (
+	rpiEventKey = mongo.IndexModel{Keys: bson.D{{Key: "team_name", Value: 1}, {Key: "timestamp", Value: 1}}, Options: options.Index().SetUnique(true)}
+	rpiRowKey   = []mongo.IndexModel{
+		{Keys: bson.D{{Key: "snapshot_id", Value: 1}, {Key: "team_name", Value: 1}, {Key: "timestamp", Value: 1}}, Options: options.Index().SetUnique(true)},
+		{Keys: bson.D{{Key: "team_name", Value: 1}}},
+	}
+)
+
+// rpiSnapshotsUp indexes the RPI snapshots and groups the RPI events generated before there were snapshots
+// into one snapshot per generation. The events of a generation share its timestamp.
+func rpiSnapshotsUp(ctx context.Context, db *mongo.Database) error {
+	for collection, indexes := range rpiSnapshotIndexes {
+		if err := createIndexes(ctx, db, collection, indexes...); err != nil {
+			return err
+		}
+	}
+
+	timestamps, err := db.Collection("rpi_events").Distinct(ctx, "timestamp", bson.M{"snapshot_id": bson.M{"$exists": false}})
+	if err != nil {
+		return fmt.Errorf("error finding the generations of rpi_events: %w", err)
+	}
+
+	for _, value := range timestamps {
+		timestamp, ok := value.(primitive.DateTime)
+		if !ok {
+			continue
+		}
+
+		if err = groupGeneration(ctx, db, timestamp.Time()); err != nil {
+			return err
+		}
+	}
+
+	if err = createIndexes(ctx, db, "rpi_events", rpiRowKey...); err != nil {
+		return err
+	}
+
+	return dropIndexes(ctx, db, "rpi_events", rpiEventKey)
+}
+
+// rpiSnapshotsDown drops the indexes of the RPI snapshots and restores the key of the RPI events.
+// The snapshots and the links of their rows are kept, the earlier versions ignore them.
+func rpiSnapshotsDown(ctx context.Context, db *mongo.Database) error {
+	if err := createIndexes(ctx, db, "rpi_events", rpiEventKey); err != nil {
+		return fmt.Errorf("error restoring the key of rpi_events, rows of snapshots computed at the same time share it: %w", err)
+	}
+
+	if err := dropIndexes(ctx, db, "rpi_events", rpiRowKey...); err != nil {
+		return err
+	}
+
+	for collection, indexes := range rpiSnapshotIndexes {
+		if err := dropIndexes(ctx, db, collection, indexes...); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// groupGeneration creates the snapshot of the RPI events generated at timestamp and links them to it.
+// The age group is the division of the ECNL matches of the teams, a generation whose teams have no matches is left as is.
+func groupGeneration(ctx context.Context, db *mongo.Database, timestamp time.Time) error {
+	var (
+		err error
+		row struct {
+			TeamId int `bson:"team_id"`
+		}
+		match struct {
+			Division string `bson:"division"`
+		}
+	)
+
+	generation := bson.M{"timestamp": timestamp, "snapshot_id": bson.M{"$exists": false}}
+
+	if err = db.Collection("rpi_events").FindOne(ctx, generation).Decode(&row); err != nil {
+		return fmt.Errorf("error reading the rpi_events of %s: %w", timestamp.Format(time.RFC3339), err)
+	}
+
+	teams := bson.M{"flight": "ECNL", "$or": []bson.M{{"hometeamid": row.TeamId}, {"awayteamid": row.TeamId}}}
+
+	if err = db.Collection("matches").FindOne(ctx, teams).Decode(&match); errors.Is(err, mongo.ErrNoDocuments) {
+		log.Printf("left the rpi_events of %s loose, team %d has no matches", timestamp.Format(time.RFC3339), row.TeamId)
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("error finding the age group of the rpi_events of %s: %w", timestamp.Format(time.RFC3339), err)
+	}
+
+	id := fmt.Sprintf("%s-legacy-%d", match.Division, timestamp.UnixMilli())
+
+	snapshot := bson.M{
+		"id":          id,
+		"agegroup":    match.Division,
+		"flight":      "ECNL",
+		"computedat":  timestamp,
+		"matchcutoff": timestamp,
+		"matchcount":  0,
+	}
+
+	if _, err = db.Collection("rpi_snapshots").UpdateOne(ctx, bson.M{"id": id}, bson.M{"$setOnInsert": snapshot}, options.Update().SetUpsert(true)); err != nil {
+		return fmt.Errorf("error creating rpi snapshot %s: %w", id, err)
+	}
+
+	result, err := db.Collection("rpi_events").UpdateMany(ctx, generation, bson.M{"$set": bson.M{"snapshot_id": id}})
+	if err != nil {
+		return fmt.Errorf("error linking the rows of rpi snapshot %s: %w", id, err)
+	}
+
+	log.Printf("grouped %d rpi_events into rpi snapshot %s", result.ModifiedCount, id)
+
+	return nil
+}
@@ -0,0 +1,64 @@
+package models_test
+
+import (
+	"github.com/jedi-knights/ecnl/pkg/models"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// fields returns the names of the fields v is stored under.
+func fields(v any) []string {
+	data, err := bson.Marshal(v)
+	Expect(err).NotTo(HaveOccurred())
+
+	var document bson.D
+	Expect(bson.Unmarshal(data, &document)).To(Succeed())
+
+	var names []string
+	for _, element := range document {
+		names = append(names, element.Key)
+	}
+
+	return names
+}
+
+var _ = Describe("Persistence schema", func() {
+	It("should store match events under the fields the DAOs query and index", func() {
+		// Act
+		names := fields(models.MatchEvent{})
+
+		// Assert
+		Expect(names).To(ContainElements("matchid", "hometeamid", "awayteamid", "hometeamname", "awayteamname", "flight", "division", "gamedate"))
+	})
+
+	It("should store teams and clubs under their natural keys", func() {
+		// Act
+		team := fields(models.Team{})
+		club := fields(models.Club{})
+
+		// Assert
+		Expect(team).To(ContainElements("id", "name", "clubid", "removedat"))
+		Expect(club).To(ContainElements("clubid", "name", "orgid", "removedat"))
+	})
+
+	It("should store events and organizations under their natural keys", func() {
+		// Act
+		event := fields(models.Event{})
+		organization := fields(models.Organization{})
+
+		// Assert
+		Expect(event).To(ContainElements("id", "name", "orgid", "removedat"))
+		Expect(organization).To(ContainElements("id", "name"))
+	})
+
+	It("should store RPI snapshots and their rows under the fields the DAOs query and index", func() {
+		// Act
+		snapshot := fields(models.RPISnapshot{})
+		row := fields(models.RPIEvent{SnapshotId: "G2009-1"})
+
+		// Assert
+		Expect(snapshot).To(ContainElements("id", "agegroup", "matchcutoff", "computedat"))
+		Expect(row).To(ContainElements("snapshot_id", "team_id", "ranking"))
+	})
+})
@@ -1,19 +1,29 @@
 package models
 
-import "fmt"
+import (
+	"fmt"
+	"time"
+)
 
 type Club struct {
-	OrgId       int    `json:"orgID"`
-	OrgSeasonId int    `json:"orgSeasonID"`
-	ClubId      int    `json:"clubID"`
-	Name        string `json:"clubName"`
-	City        string `json:"city"`
-	ClubLogo    string `json:"clubLogo"`
-	StateCode   string `json:"stateCode"`
-	EventId     int    `json:"eventID"`
-	EventCounts int    `json:"eventCounts"`
+	OrgId       int    `bson:"orgid" json:"orgID"`
+	OrgSeasonId int    `bson:"orgseasonid" json:"orgSeasonID"`
+	ClubId      int    `bson:"clubid" json:"clubID"`
+	Name        string `bson:"name" json:"clubName"`
+	City        string `bson:"city" json:"city"`
+	ClubLogo    string `bson:"clublogo" json:"clubLogo"`
+	StateCode   string `bson:"statecode" json:"stateCode"`
+	EventId     int    `bson:"eventid" json:"eventID"`
+	EventCounts int    `bson:"eventcounts" json:"eventCounts"`
+	// RemovedAt is set when sync no longer finds the club upstream.
+	RemovedAt *time.Time `bson:"removedat" json:"removedAt,omitempty"`
 }
 
 func (c *Club) String() string {
 	return fmt.Sprintf("Name: \"%s\", OrgId: %d, OrgSeasonId: %d, ClubId: %d, City: \"%s\", StateCode: %s, EventId: %d, EventCounts: %d", c.Name, c.OrgId, c.OrgSeasonId, c.ClubId, c.City, c.StateCode, c.EventId, c.EventCounts)
 }
+
+// Removed returns true when the club is no longer found upstream.
+func (c *Club) Removed() bool {
+	return c.RemovedAt != nil
+}
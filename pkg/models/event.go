@@ -1,16 +1,26 @@
 package models
 
-import "fmt"
+import (
+	"fmt"
+	"time"
+)
 
 type Event struct {
-	Id            int    `json:"eventID"`
-	Name          string `json:"eventName"`
-	OrgId         int    `json:"orgID"`
-	OrgName       string `json:"orgName"`
-	OrgSeasonId   int    `json:"orgSeasonID"`
-	OrgSeasonName string `json:"orgSeasonName"`
+	Id            int    `bson:"id" json:"eventID"`
+	Name          string `bson:"name" json:"eventName"`
+	OrgId         int    `bson:"orgid" json:"orgID"`
+	OrgName       string `bson:"orgname" json:"orgName"`
+	OrgSeasonId   int    `bson:"orgseasonid" json:"orgSeasonID"`
+	OrgSeasonName string `bson:"orgseasonname" json:"orgSeasonName"`
+	// RemovedAt is set when sync no longer finds the event upstream.
+	RemovedAt *time.Time `bson:"removedat" json:"removedAt,omitempty"`
 }
 
 func (e *Event) String() string {
 	return fmt.Sprintf("Name: \"%s\", Id: %d, OrgId: %d, OrgName: %s, OrgSeasonId: %d, OrgSeasonName: \"%s\"", e.Name, e.Id, e.OrgId, e.OrgName, e.OrgSeasonId, e.OrgSeasonName)
 }
+
+// Removed returns true when the event is no longer found upstream.
+func (e *Event) Removed() bool {
+	return e.RemovedAt != nil
+}
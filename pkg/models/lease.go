@@ -0,0 +1,11 @@
+package models
+
+import "time"
+
+// Lease is held by the instance running a scheduled job, so that no other instance runs it at the same time.
+type Lease struct {
+	Name      string    `bson:"name" json:"name"`
+	Holder    string    `bson:"holder" json:"holder"`
+	RenewedAt time.Time `bson:"renewedat" json:"renewedAt"`
+	ExpiresAt time.Time `bson:"expiresat" json:"expiresAt"`
+}
@@ -1,25 +1,36 @@
 package models
 
-import "fmt"
+import (
+	"fmt"
+	"time"
+)
+
+// GameDateLayout is the layout of the game date of a match, in the local time of the venue.
+const GameDateLayout = "2006-01-02T15:04:05"
 
 type MatchEvent struct {
-	MatchId        int    `json:"matchID"`
-	GameDate       string `json:"gameDate"`
-	HomeTeamId     int    `json:"homeTeamID"`
-	HomeTeamName   string `json:"homeTeam"`
-	HomeTeamClubId int    `json:"homeTeamClubID"`
-	HomeTeamScore  int    `json:"homeTeamScore"`
-	AwayTeamId     int    `json:"awayTeamID"`
-	AwayTeamName   string `json:"awayTeam"`
-	AwayTeamClubId int    `json:"awayTeamClubID"`
-	AwayTeamScore  int    `json:"awayTeamScore"`
-	Flight         string `json:"flight"`
-	Division       string `json:"division"`
-	EventName      string `json:"eventName"`
-	Complex        string `json:"complex"`
-	Venue          string `json:"venue"`
+	MatchId        int    `bson:"matchid" json:"matchID"`
+	GameDate       string `bson:"gamedate" json:"gameDate"`
+	HomeTeamId     int    `bson:"hometeamid" json:"homeTeamID"`
+	HomeTeamName   string `bson:"hometeamname" json:"homeTeam"`
+	HomeTeamClubId int    `bson:"hometeamclubid" json:"homeTeamClubID"`
+	HomeTeamScore  int    `bson:"hometeamscore" json:"homeTeamScore"`
+	AwayTeamId     int    `bson:"awayteamid" json:"awayTeamID"`
+	AwayTeamName   string `bson:"awayteamname" json:"awayTeam"`
+	AwayTeamClubId int    `bson:"awayteamclubid" json:"awayTeamClubID"`
+	AwayTeamScore  int    `bson:"awayteamscore" json:"awayTeamScore"`
+	Flight         string `bson:"flight" json:"flight"`
+	Division       string `bson:"division" json:"division"`
+	EventName      string `bson:"eventname" json:"eventName"`
+	Complex        string `bson:"complex" json:"complex"`
+	Venue          string `bson:"venue" json:"venue"`
 }
 
 func (m MatchEvent) String() string {
 	return fmt.Sprintf("'%s' vs '%s' at '%s'", m.HomeTeamName, m.AwayTeamName, m.GameDate)
 }
+
+// PlayedAt parses the game date of the match.
+func (m MatchEvent) PlayedAt() (time.Time, error) {
+	return time.ParseInLocation(GameDateLayout, m.GameDate, time.Local)
+}
@@ -0,0 +1,78 @@
+package models
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// FieldChange is the old and new value of a match field that changed upstream.
+type FieldChange struct {
+	Field string `bson:"field" json:"field"`
+	Old   string `bson:"old" json:"old"`
+	New   string `bson:"new" json:"new"`
+}
+
+// MatchHistory records the changes found in a match when it was synced again, typically a late or corrected score.
+type MatchHistory struct {
+	MatchId      int           `bson:"matchid" json:"matchID"`
+	HomeTeamName string        `bson:"hometeamname" json:"homeTeam"`
+	AwayTeamName string        `bson:"awayteamname" json:"awayTeam"`
+	Division     string        `bson:"division" json:"division"`
+	EventName    string        `bson:"eventname" json:"eventName"`
+	ChangedAt    time.Time     `bson:"changedat" json:"changedAt"`
+	Changes      []FieldChange `bson:"changes" json:"changes"`
+}
+
+// NewMatchHistory creates the history record of the changes between the stored and the current version of a match.
+// It returns nil when nothing that is tracked changed.
+func NewMatchHistory(stored, current MatchEvent, changedAt time.Time) *MatchHistory {
+	changes := DiffMatchEvents(stored, current)
+	if len(changes) == 0 {
+		return nil
+	}
+
+	return &MatchHistory{
+		MatchId:      current.MatchId,
+		HomeTeamName: current.HomeTeamName,
+		AwayTeamName: current.AwayTeamName,
+		Division:     current.Division,
+		EventName:    current.EventName,
+		ChangedAt:    changedAt,
+		Changes:      changes,
+	}
+}
+
+// DiffMatchEvents returns the tracked fields whose values differ between two versions of a match.
+func DiffMatchEvents(old, new MatchEvent) []FieldChange {
+	var changes []FieldChange
+
+	compare := func(field, oldValue, newValue string) {
+		if oldValue != newValue {
+			changes = append(changes, FieldChange{Field: field, Old: oldValue, New: newValue})
+		}
+	}
+
+	compare("gameDate", old.GameDate, new.GameDate)
+	compare("homeTeamScore", strconv.Itoa(old.HomeTeamScore), strconv.Itoa(new.HomeTeamScore))
+	compare("awayTeamScore", strconv.Itoa(old.AwayTeamScore), strconv.Itoa(new.AwayTeamScore))
+	compare("homeTeamID", strconv.Itoa(old.HomeTeamId), strconv.Itoa(new.HomeTeamId))
+	compare("awayTeamID", strconv.Itoa(old.AwayTeamId), strconv.Itoa(new.AwayTeamId))
+	compare("complex", old.Complex, new.Complex)
+	compare("venue", old.Venue, new.Venue)
+	compare("flight", old.Flight, new.Flight)
+	compare("division", old.Division, new.Division)
+
+	return changes
+}
+
+func (h MatchHistory) String() string {
+	var changes []string
+
+	for _, change := range h.Changes {
+		changes = append(changes, fmt.Sprintf("%s: '%s' -> '%s'", change.Field, change.Old, change.New))
+	}
+
+	return fmt.Sprintf("%s match %d '%s' vs '%s' (%s): %s", h.ChangedAt.Format(time.RFC3339), h.MatchId, h.HomeTeamName, h.AwayTeamName, h.Division, strings.Join(changes, ", "))
+}
@@ -0,0 +1,62 @@
+package models_test
+
+import (
+	"github.com/jedi-knights/ecnl/pkg/models"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"time"
+)
+
+var _ = Describe("MatchHistory", func() {
+	var stored models.MatchEvent
+
+	BeforeEach(func() {
+		stored = models.MatchEvent{
+			MatchId:       9001,
+			GameDate:      "2023-09-09T12:00:00",
+			HomeTeamName:  "Concorde Fire Premier ECNL G09",
+			HomeTeamScore: 1,
+			AwayTeamName:  "Alabama FC ECNL G09",
+			AwayTeamScore: 0,
+			Venue:         "Field 1",
+			Division:      "G2009",
+		}
+	})
+
+	It("should not record anything when nothing changed", func() {
+		// Act
+		history := models.NewMatchHistory(stored, stored, time.Now())
+
+		// Assert
+		Expect(history).To(BeNil())
+	})
+
+	It("should record a corrected score", func() {
+		// Arrange
+		current := stored
+		current.AwayTeamScore = 2
+
+		// Act
+		history := models.NewMatchHistory(stored, current, time.Now())
+
+		// Assert
+		Expect(history).NotTo(BeNil())
+		Expect(history.MatchId).To(Equal(9001))
+		Expect(history.Changes).To(ConsistOf(models.FieldChange{Field: "awayTeamScore", Old: "0", New: "2"}))
+	})
+
+	It("should record every changed field", func() {
+		// Arrange
+		current := stored
+		current.GameDate = "2023-09-10T12:00:00"
+		current.Venue = "Field 2"
+
+		// Act
+		changes := models.DiffMatchEvents(stored, current)
+
+		// Assert
+		Expect(changes).To(HaveLen(2))
+		Expect(changes[0].Field).To(Equal("gameDate"))
+		Expect(changes[1].Field).To(Equal("venue"))
+	})
+})
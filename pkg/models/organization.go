@@ -3,10 +3,10 @@ package models
 import "fmt"
 
 type Organization struct {
-	Id            int    `json:"orgID"`
-	SeasonId      int    `json:"orgSeasonID"`
-	Name          string `json:"orgName"`
-	SeasonGroupId int    `json:"orgSeasonGroupID"`
+	Id            int    `bson:"id" json:"orgID"`
+	SeasonId      int    `bson:"seasonid" json:"orgSeasonID"`
+	Name          string `bson:"name" json:"orgName"`
+	SeasonGroupId int    `bson:"seasongroupid" json:"orgSeasonGroupID"`
 }
 
 type OrganiationDivision struct {
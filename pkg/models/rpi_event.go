@@ -12,6 +12,8 @@ type RPIEvent struct {
 	Timestamp time.Time `bson:"timestamp" json:"timestamp"`
 	Ranking   int       `bson:"ranking" json:"ranking"`
 	Value     float64   `bson:"rpi" json:"rpi"`
+	// SnapshotId is the id of the RPISnapshot the event is a row of.
+	SnapshotId string `bson:"snapshot_id,omitempty" json:"snapshot_id,omitempty"`
 }
 
 func NewRPIEvent(timestamp time.Time, data RPIRankingData) *RPIEvent {
@@ -0,0 +1,63 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// RPIMove is how the ranking of a team changed between two RPI snapshots.
+// The ranking and RPI of a snapshot the team is not ranked in are zero.
+type RPIMove struct {
+	TeamId      int     `json:"teamId"`
+	TeamName    string  `json:"teamName"`
+	FromRanking int     `json:"fromRanking"`
+	ToRanking   int     `json:"toRanking"`
+	FromRPI     float64 `json:"fromRpi"`
+	ToRPI       float64 `json:"toRpi"`
+}
+
+// RankDelta is the number of places the team moved, up when positive.
+func (m RPIMove) RankDelta() int {
+	if m.FromRanking == 0 || m.ToRanking == 0 {
+		return 0
+	}
+
+	return m.FromRanking - m.ToRanking
+}
+
+// RPIDelta is the change of the RPI of the team.
+func (m RPIMove) RPIDelta() float64 {
+	if m.FromRanking == 0 || m.ToRanking == 0 {
+		return 0
+	}
+
+	return m.ToRPI - m.FromRPI
+}
+
+func (m RPIMove) String() string {
+	return fmt.Sprintf("#%d (%+d): '%s' (%f, %+f)", m.ToRanking, m.RankDelta(), m.TeamName, m.ToRPI, m.RPIDelta())
+}
+
+// RPIMovers compares the rankings of two RPI snapshots of an age group.
+type RPIMovers struct {
+	From RPISnapshot `json:"from"`
+	To   RPISnapshot `json:"to"`
+	// Moves are the teams ranked in both snapshots, Entries the ones only ranked in To
+	// and DropOuts the ones only ranked in From.
+	Moves    []RPIMove `json:"moves"`
+	Entries  []RPIMove `json:"entries"`
+	DropOuts []RPIMove `json:"dropOuts"`
+}
+
+// RPIHistoryPoint is the ranking of a team in an RPI snapshot.
+// Shared is set when the team id is ranked under more than one name in the snapshot, each name has a point of its own.
+type RPIHistoryPoint struct {
+	SnapshotId  string    `json:"snapshotId,omitempty"`
+	AgeGroup    string    `json:"ageGroup,omitempty"`
+	ComputedAt  time.Time `json:"computedAt"`
+	MatchCutoff time.Time `json:"matchCutoff"`
+	TeamName    string    `json:"teamName"`
+	Ranking     int       `json:"ranking"`
+	RPI         float64   `json:"rpi"`
+	Shared      bool      `json:"shared,omitempty"`
+}
@@ -0,0 +1,27 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// RPISnapshot is the header of the RPI rankings of an age group, computed at a point in time.
+// The rankings are the RPI events that carry its id.
+type RPISnapshot struct {
+	Id       string `bson:"id" json:"id"`
+	AgeGroup string `bson:"agegroup" json:"ageGroup"`
+	Flight   string `bson:"flight" json:"flight"`
+	// ComputedAt is when the rankings were computed and MatchCutoff the time up to which matches were played,
+	// later matches are left out.
+	ComputedAt  time.Time `bson:"computedat" json:"computedAt"`
+	MatchCutoff time.Time `bson:"matchcutoff" json:"matchCutoff"`
+	MatchCount  int       `bson:"matchcount" json:"matchCount"`
+	// Fingerprint identifies the matches the rankings were computed from.
+	Fingerprint string `bson:"fingerprint" json:"fingerprint,omitempty"`
+	// RunId is the id of the run that computed the rankings.
+	RunId string `bson:"runid" json:"runId,omitempty"`
+}
+
+func (s RPISnapshot) String() string {
+	return fmt.Sprintf("%s: %s %s as of %s from %d matches", s.Id, s.AgeGroup, s.Flight, s.MatchCutoff.Format(time.RFC3339), s.MatchCount)
+}
@@ -0,0 +1,61 @@
+package models
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// The states of a run.
+const (
+	RunRunning   = "running"
+	RunSucceeded = "succeeded"
+	RunFailed    = "failed"
+)
+
+// EntityCounts counts what a run did with one type of entity.
+type EntityCounts struct {
+	Fetched   int `bson:"fetched" json:"fetched"`
+	Inserted  int `bson:"inserted" json:"inserted"`
+	Updated   int `bson:"updated" json:"updated"`
+	Unchanged int `bson:"unchanged" json:"unchanged"`
+	Skipped   int `bson:"skipped" json:"skipped"`
+	// Removed counts the records marked as no longer found upstream.
+	Removed int `bson:"removed" json:"removed"`
+}
+
+// Run is the ledger entry of a command that loads data, such as sync or rpigen.
+type Run struct {
+	Id         string                  `bson:"id" json:"id"`
+	Command    string                  `bson:"command" json:"command"`
+	Params     map[string]string       `bson:"params" json:"params,omitempty"`
+	Status     string                  `bson:"status" json:"status"`
+	StartedAt  time.Time               `bson:"startedat" json:"startedAt"`
+	FinishedAt time.Time               `bson:"finishedat" json:"finishedAt,omitempty"`
+	Entities   map[string]EntityCounts `bson:"entities" json:"entities,omitempty"`
+	HttpCalls  int64                   `bson:"httpcalls" json:"httpCalls"`
+	Errors     []string                `bson:"errors" json:"errors,omitempty"`
+}
+
+// Duration returns how long the run took, or has been running for when it did not finish.
+func (r Run) Duration() time.Duration {
+	if r.FinishedAt.IsZero() {
+		return time.Since(r.StartedAt)
+	}
+
+	return r.FinishedAt.Sub(r.StartedAt)
+}
+
+func (r Run) String() string {
+	var entities []string
+
+	for name, counts := range r.Entities {
+		entities = append(entities, fmt.Sprintf("%s: %d fetched %d inserted %d updated %d skipped", name, counts.Fetched, counts.Inserted, counts.Updated, counts.Skipped))
+	}
+
+	sort.Strings(entities)
+
+	return fmt.Sprintf("Id: %s, Command: %s, Status: %s, StartedAt: %s, Duration: %s, HttpCalls: %d, Errors: %d, Entities: [%s]",
+		r.Id, r.Command, r.Status, r.StartedAt.Format(time.RFC3339), r.Duration().Round(time.Second), r.HttpCalls, len(r.Errors), strings.Join(entities, ", "))
+}
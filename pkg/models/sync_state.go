@@ -0,0 +1,51 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// SyncRunKey is the key of the sync state that describes the most recent sync run.
+const SyncRunKey = "run"
+
+// SyncState is a checkpoint recorded by the sync for a unit of work.
+// Units are the run itself, organizations, events (teams) and club/event pairs (matches).
+// Complete is set when the last sync of an event or club/event pair stored everything upstream returned for it.
+type SyncState struct {
+	Key         string    `bson:"key" json:"key"`
+	RunId       string    `bson:"runid" json:"runId"`
+	OrgId       int       `bson:"orgid" json:"orgId,omitempty"`
+	EventId     int       `bson:"eventid" json:"eventId,omitempty"`
+	ClubId      int       `bson:"clubid" json:"clubId,omitempty"`
+	Count       int       `bson:"count" json:"count"`
+	Fingerprint string    `bson:"fingerprint" json:"fingerprint,omitempty"`
+	Complete    bool      `bson:"complete" json:"complete,omitempty"`
+	StartedAt   time.Time `bson:"startedat" json:"startedAt,omitempty"`
+	FinishedAt  time.Time `bson:"finishedat" json:"finishedAt,omitempty"`
+	SyncedAt    time.Time `bson:"syncedat" json:"syncedAt"`
+	ChangedAt   time.Time `bson:"changedat" json:"changedAt"`
+}
+
+// OrganizationSyncKey returns the sync state key of an organization.
+func OrganizationSyncKey(orgId int) string {
+	return fmt.Sprintf("org:%d", orgId)
+}
+
+// EventSyncKey returns the sync state key of the teams of an event.
+func EventSyncKey(eventId int) string {
+	return fmt.Sprintf("event:%d", eventId)
+}
+
+// ClubEventSyncKey returns the sync state key of the matches of a club in an event.
+func ClubEventSyncKey(clubId, eventId int) string {
+	return fmt.Sprintf("club:%d:event:%d", clubId, eventId)
+}
+
+// Finished returns true when the state describes a run that completed.
+func (s SyncState) Finished() bool {
+	return !s.FinishedAt.IsZero()
+}
+
+func (s SyncState) String() string {
+	return fmt.Sprintf("Key: \"%s\", RunId: \"%s\", Count: %d, SyncedAt: %s, ChangedAt: %s", s.Key, s.RunId, s.Count, s.SyncedAt.Format(time.RFC3339), s.ChangedAt.Format(time.RFC3339))
+}
@@ -1,18 +1,28 @@
 package models
 
-import "fmt"
+import (
+	"fmt"
+	"time"
+)
 
 type Team struct {
-	Id          int    `json:"teamID"`
-	Name        string `json:"teamName"`
-	ClubId      int    `json:"clubID"`
-	InitialSeed int    `json:"initialSeed"`
-	ClubLogo    string `json:"clubLogo"`
-	FirstName   string `json:"firstName"`
-	LastName    string `json:"lastName"`
-	AgeGroup    string `json:"ageGroup"`
+	Id          int    `bson:"id" json:"teamID"`
+	Name        string `bson:"name" json:"teamName"`
+	ClubId      int    `bson:"clubid" json:"clubID"`
+	InitialSeed int    `bson:"initialseed" json:"initialSeed"`
+	ClubLogo    string `bson:"clublogo" json:"clubLogo"`
+	FirstName   string `bson:"firstname" json:"firstName"`
+	LastName    string `bson:"lastname" json:"lastName"`
+	AgeGroup    string `bson:"agegroup" json:"ageGroup"`
+	// RemovedAt is set when sync no longer finds the team upstream.
+	RemovedAt *time.Time `bson:"removedat" json:"removedAt,omitempty"`
 }
 
 func (t *Team) String() string {
 	return fmt.Sprintf("Name: \"%s\", Id: %d, ClubId: %d", t.Name, t.Id, t.ClubId)
 }
+
+// Removed returns true when the team is no longer found upstream.
+func (t *Team) Removed() bool {
+	return t.RemovedAt != nil
+}
@@ -0,0 +1,48 @@
+package responses
+
+import (
+	"github.com/jedi-knights/ecnl/pkg/models"
+	"time"
+)
+
+// FieldChangeResponse is the old and new value of a match field that changed upstream.
+type FieldChangeResponse struct {
+	Field string `json:"field"`
+	Old   string `json:"old"`
+	New   string `json:"new"`
+}
+
+// MatchHistoryResponse is the changes found in a match when it was synced again.
+type MatchHistoryResponse struct {
+	MatchId   int                   `json:"matchId"`
+	HomeTeam  string                `json:"homeTeam"`
+	AwayTeam  string                `json:"awayTeam"`
+	Division  string                `json:"division"`
+	EventName string                `json:"eventName"`
+	ChangedAt time.Time             `json:"changedAt"`
+	Changes   []FieldChangeResponse `json:"changes"`
+}
+
+// NewMatchHistoryResponses converts the history records of matches.
+func NewMatchHistoryResponses(histories []models.MatchHistory) []MatchHistoryResponse {
+	responses := make([]MatchHistoryResponse, 0, len(histories))
+
+	for _, history := range histories {
+		changes := make([]FieldChangeResponse, 0, len(history.Changes))
+		for _, change := range history.Changes {
+			changes = append(changes, FieldChangeResponse{Field: change.Field, Old: change.Old, New: change.New})
+		}
+
+		responses = append(responses, MatchHistoryResponse{
+			MatchId:   history.MatchId,
+			HomeTeam:  history.HomeTeamName,
+			AwayTeam:  history.AwayTeamName,
+			Division:  history.Division,
+			EventName: history.EventName,
+			ChangedAt: history.ChangedAt,
+			Changes:   changes,
+		})
+	}
+
+	return responses
+}
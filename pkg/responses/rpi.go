@@ -0,0 +1,170 @@
+package responses
+
+import (
+	"github.com/jedi-knights/ecnl/pkg/models"
+	"time"
+)
+
+// RPIRankingResponse is the RPI ranking of a team.
+type RPIRankingResponse struct {
+	Ranking  int     `json:"ranking"`
+	TeamId   int     `json:"teamId"`
+	TeamName string  `json:"teamName"`
+	RPI      float64 `json:"rpi"`
+}
+
+// RPISnapshotResponse is the RPI rankings of an age group as they were computed at a point in time.
+type RPISnapshotResponse struct {
+	Id          string               `json:"id"`
+	AgeGroup    string               `json:"ageGroup"`
+	Flight      string               `json:"flight"`
+	ComputedAt  time.Time            `json:"computedAt"`
+	MatchCutoff time.Time            `json:"matchCutoff"`
+	MatchCount  int                  `json:"matchCount"`
+	RunId       string               `json:"runId,omitempty"`
+	Rankings    []RPIRankingResponse `json:"rankings"`
+}
+
+// NewRPIRankingResponses converts the ranking data of the teams.
+func NewRPIRankingResponses(rankings []models.RPIRankingData) []RPIRankingResponse {
+	responses := make([]RPIRankingResponse, 0, len(rankings))
+
+	for _, ranking := range rankings {
+		responses = append(responses, RPIRankingResponse{
+			Ranking:  ranking.Ranking,
+			TeamId:   ranking.TeamId,
+			TeamName: ranking.TeamName,
+			RPI:      ranking.RPI,
+		})
+	}
+
+	return responses
+}
+
+// NewRPISnapshotResponse converts a snapshot and its rows.
+func NewRPISnapshotResponse(snapshot models.RPISnapshot, rows []models.RPIEvent) RPISnapshotResponse {
+	response := RPISnapshotResponse{
+		Id:          snapshot.Id,
+		AgeGroup:    snapshot.AgeGroup,
+		Flight:      snapshot.Flight,
+		ComputedAt:  snapshot.ComputedAt,
+		MatchCutoff: snapshot.MatchCutoff,
+		MatchCount:  snapshot.MatchCount,
+		RunId:       snapshot.RunId,
+		Rankings:    make([]RPIRankingResponse, 0, len(rows)),
+	}
+
+	for _, row := range rows {
+		response.Rankings = append(response.Rankings, RPIRankingResponse{
+			Ranking:  row.Ranking,
+			TeamId:   row.TeamId,
+			TeamName: row.TeamName,
+			RPI:      row.Value,
+		})
+	}
+
+	return response
+}
+
+// RPIMoveResponse is how the ranking of a team changed between two snapshots.
+type RPIMoveResponse struct {
+	TeamId      int     `json:"teamId"`
+	TeamName    string  `json:"teamName"`
+	FromRanking int     `json:"fromRanking,omitempty"`
+	ToRanking   int     `json:"toRanking,omitempty"`
+	RankDelta   int     `json:"rankDelta"`
+	FromRPI     float64 `json:"fromRpi,omitempty"`
+	ToRPI       float64 `json:"toRpi,omitempty"`
+	RPIDelta    float64 `json:"rpiDelta"`
+}
+
+// RPISnapshotSummaryResponse is a snapshot without its rankings.
+type RPISnapshotSummaryResponse struct {
+	Id          string    `json:"id"`
+	ComputedAt  time.Time `json:"computedAt"`
+	MatchCutoff time.Time `json:"matchCutoff"`
+	MatchCount  int       `json:"matchCount"`
+}
+
+// RPIMoversResponse compares the RPI rankings of an age group in two snapshots.
+type RPIMoversResponse struct {
+	AgeGroup string                     `json:"ageGroup"`
+	From     RPISnapshotSummaryResponse `json:"from"`
+	To       RPISnapshotSummaryResponse `json:"to"`
+	Moves    []RPIMoveResponse          `json:"moves"`
+	Entries  []RPIMoveResponse          `json:"entries"`
+	DropOuts []RPIMoveResponse          `json:"dropOuts"`
+}
+
+// RPIHistoryPointResponse is the RPI ranking of a team in a snapshot.
+// Shared is set when the team id is ranked under more than one name in the snapshot.
+type RPIHistoryPointResponse struct {
+	SnapshotId  string    `json:"snapshotId,omitempty"`
+	AgeGroup    string    `json:"ageGroup,omitempty"`
+	ComputedAt  time.Time `json:"computedAt"`
+	MatchCutoff time.Time `json:"matchCutoff"`
+	TeamName    string    `json:"teamName"`
+	Ranking     int       `json:"ranking"`
+	RPI         float64   `json:"rpi"`
+	Shared      bool      `json:"shared,omitempty"`
+}
+
+// NewRPIMoversResponse converts the comparison of two snapshots.
+func NewRPIMoversResponse(movers models.RPIMovers) RPIMoversResponse {
+	return RPIMoversResponse{
+		AgeGroup: movers.To.AgeGroup,
+		From:     newRPISnapshotSummaryResponse(movers.From),
+		To:       newRPISnapshotSummaryResponse(movers.To),
+		Moves:    newRPIMoveResponses(movers.Moves),
+		Entries:  newRPIMoveResponses(movers.Entries),
+		DropOuts: newRPIMoveResponses(movers.DropOuts),
+	}
+}
+
+// NewRPIHistoryResponses converts the rankings of a team over time.
+func NewRPIHistoryResponses(points []models.RPIHistoryPoint) []RPIHistoryPointResponse {
+	responses := make([]RPIHistoryPointResponse, 0, len(points))
+
+	for _, point := range points {
+		responses = append(responses, RPIHistoryPointResponse{
+			SnapshotId:  point.SnapshotId,
+			AgeGroup:    point.AgeGroup,
+			ComputedAt:  point.ComputedAt,
+			MatchCutoff: point.MatchCutoff,
+			TeamName:    point.TeamName,
+			Ranking:     point.Ranking,
+			RPI:         point.RPI,
+			Shared:      point.Shared,
+		})
+	}
+
+	return responses
+}
+
+func newRPISnapshotSummaryResponse(snapshot models.RPISnapshot) RPISnapshotSummaryResponse {
+	return RPISnapshotSummaryResponse{
+		Id:          snapshot.Id,
+		ComputedAt:  snapshot.ComputedAt,
+		MatchCutoff: snapshot.MatchCutoff,
+		MatchCount:  snapshot.MatchCount,
+	}
+}
+
+func newRPIMoveResponses(moves []models.RPIMove) []RPIMoveResponse {
+	responses := make([]RPIMoveResponse, 0, len(moves))
+
+	for _, move := range moves {
+		responses = append(responses, RPIMoveResponse{
+			TeamId:      move.TeamId,
+			TeamName:    move.TeamName,
+			FromRanking: move.FromRanking,
+			ToRanking:   move.ToRanking,
+			RankDelta:   move.RankDelta(),
+			FromRPI:     move.FromRPI,
+			ToRPI:       move.ToRPI,
+			RPIDelta:    move.RPIDelta(),
+		})
+	}
+
+	return responses
+}
@@ -0,0 +1,67 @@
+package responses
+
+import (
+	"github.com/jedi-knights/ecnl/pkg/models"
+	"time"
+)
+
+// EntityCountsResponse counts what a run did with one type of entity.
+type EntityCountsResponse struct {
+	Fetched   int `json:"fetched"`
+	Inserted  int `json:"inserted"`
+	Updated   int `json:"updated"`
+	Unchanged int `json:"unchanged"`
+	Skipped   int `json:"skipped"`
+	Removed   int `json:"removed"`
+}
+
+// RunResponse is the ledger entry of a command that loads data.
+type RunResponse struct {
+	Id         string                          `json:"id"`
+	Command    string                          `json:"command"`
+	Params     map[string]string               `json:"params,omitempty"`
+	Status     string                          `json:"status"`
+	StartedAt  time.Time                       `json:"startedAt"`
+	FinishedAt *time.Time                      `json:"finishedAt,omitempty"`
+	Entities   map[string]EntityCountsResponse `json:"entities,omitempty"`
+	HttpCalls  int64                           `json:"httpCalls"`
+	Errors     []string                        `json:"errors,omitempty"`
+}
+
+// NewRunResponse converts a run, FinishedAt is left out while it is running.
+func NewRunResponse(run models.Run) RunResponse {
+	response := RunResponse{
+		Id:        run.Id,
+		Command:   run.Command,
+		Params:    run.Params,
+		Status:    run.Status,
+		StartedAt: run.StartedAt,
+		HttpCalls: run.HttpCalls,
+		Errors:    run.Errors,
+	}
+
+	if !run.FinishedAt.IsZero() {
+		response.FinishedAt = &run.FinishedAt
+	}
+
+	if len(run.Entities) > 0 {
+		response.Entities = make(map[string]EntityCountsResponse, len(run.Entities))
+
+		for name, counts := range run.Entities {
+			response.Entities[name] = EntityCountsResponse(counts)
+		}
+	}
+
+	return response
+}
+
+// NewRunResponses converts the runs.
+func NewRunResponses(runs []models.Run) []RunResponse {
+	responses := make([]RunResponse, 0, len(runs))
+
+	for _, run := range runs {
+		responses = append(responses, NewRunResponse(run))
+	}
+
+	return responses
+}
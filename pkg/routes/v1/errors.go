@@ -0,0 +1,28 @@
+package v1
+
+import (
+	"errors"
+	"github.com/jedi-knights/ecnl/pkg"
+	"net/http"
+)
+
+// statusFor maps an error to the HTTP status code returned to the client.
+func statusFor(err error) int {
+	var (
+		statusErr *pkg.UpstreamStatusError
+		decodeErr *pkg.DecodeError
+	)
+
+	switch {
+	case errors.Is(err, pkg.ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, pkg.ErrDuplicate):
+		return http.StatusConflict
+	case errors.Is(err, pkg.ErrInvalid):
+		return http.StatusBadRequest
+	case errors.As(err, &statusErr), errors.As(err, &decodeErr), errors.Is(err, pkg.ErrUpstreamFailure):
+		return http.StatusBadGateway
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,22 @@
+package v1
+
+import (
+	"github.com/jedi-knights/ecnl/pkg/controllers"
+	"github.com/jedi-knights/ecnl/pkg/dal"
+)
+
+// Handler serves the routes that read stored data, through the controllers it is created with.
+type Handler struct {
+	rpi          controllers.RIPer
+	matchHistory controllers.MatchHistorier
+	runs         controllers.Runner
+}
+
+// NewHandler creates a handler whose controllers read from repos.
+func NewHandler(repos *dal.Repositories) *Handler {
+	return &Handler{
+		rpi:          controllers.NewRPI(repos.Matches, repos.RPISnapshots, repos.RPIEvents),
+		matchHistory: controllers.NewMatchHistory(repos.MatchHistory),
+		runs:         controllers.NewRuns(repos.Runs),
+	}
+}
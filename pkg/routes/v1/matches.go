@@ -0,0 +1,81 @@
+package v1
+
+import (
+	"github.com/jedi-knights/ecnl/pkg"
+	"github.com/jedi-knights/ecnl/pkg/models"
+	"github.com/jedi-knights/ecnl/pkg/responses"
+	"github.com/labstack/echo/v4"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// HandleGetMatchCorrections godoc
+// @Summary Lists recent match corrections
+// @Description Lists the changes found in matches that were synced again, such as late or corrected scores
+// @Tags Matches
+// @Accept json
+// @Produce json
+// @Param since query string false "A duration (e.g. 72h), a date or an RFC3339 time" default(168h)
+// @Param limit query int false "The maximum number of corrections" default(100)
+// @Success 200 {array} responses.MatchHistoryResponse
+// @Router /v1/matches/corrections [get]
+func (h *Handler) HandleGetMatchCorrections(c echo.Context) error {
+	var (
+		err         error
+		since       time.Time
+		limit       = 100
+		corrections []models.MatchHistory
+	)
+
+	if since, err = pkg.ParseSince(c.QueryParam("since")); err != nil {
+		return c.JSON(http.StatusBadRequest, err.Error())
+	}
+
+	if since.IsZero() {
+		since = time.Now().Add(-7 * 24 * time.Hour)
+	}
+
+	if value := c.QueryParam("limit"); value != "" {
+		if limit, err = strconv.Atoi(value); err != nil || limit < 0 {
+			return c.JSON(http.StatusBadRequest, "limit must be a positive number")
+		}
+	}
+
+	if corrections, err = h.matchHistory.Recent(c.Request().Context(), since, limit); err != nil {
+		return c.JSON(statusFor(err), err.Error())
+	}
+
+	c.Response().Header().Set("X-Element-Count", strconv.Itoa(len(corrections)))
+
+	return c.JSON(http.StatusOK, responses.NewMatchHistoryResponses(corrections))
+}
+
+// HandleGetMatchHistory godoc
+// @Summary Lists the corrections of a match
+// @Description Lists the changes found in a match each time it was synced again
+// @Tags Matches
+// @Accept json
+// @Produce json
+// @Param id path int true "Match ID"
+// @Success 200 {array} responses.MatchHistoryResponse
+// @Router /v1/matches/{id}/history [get]
+func (h *Handler) HandleGetMatchHistory(c echo.Context) error {
+	var (
+		err     error
+		id      int
+		history []models.MatchHistory
+	)
+
+	if id, err = strconv.Atoi(c.Param("id")); err != nil {
+		return c.JSON(http.StatusBadRequest, "id must be a number")
+	}
+
+	if history, err = h.matchHistory.ByMatchId(c.Request().Context(), id); err != nil {
+		return c.JSON(statusFor(err), err.Error())
+	}
+
+	c.Response().Header().Set("X-Element-Count", strconv.Itoa(len(history)))
+
+	return c.JSON(http.StatusOK, responses.NewMatchHistoryResponses(history))
+}
@@ -1,27 +1,33 @@
 package v1
 
 import (
-	"github.com/jedi-knights/ecnl/pkg/controllers"
+	"github.com/jedi-knights/ecnl/pkg"
 	"github.com/jedi-knights/ecnl/pkg/models"
+	"github.com/jedi-knights/ecnl/pkg/responses"
 	"github.com/labstack/echo/v4"
 	"net/http"
 	"net/url"
 	"strconv"
+	"time"
 )
 
 // HandleGetRPIRankings godoc
-// @Summary Examines the schedule and calculates the RPI rankings for all teams
-// @Description Calculates the RPI rankings for all teams
+// @Summary Gets the stored RPI rankings of an age group
+// @Description Gets the RPI rankings of the latest snapshot generated by rpigen, or of the snapshot that was current at asOf
 // @Tags RPI
 // @Accept json
 // @Produce json
 // @Param division path string true "Division" Enums(G2006/2005,G2008,G2009,G2010,G2011,B2006/2005,B2008,B2009,B2010,B2011)
-// @Success 200 {array} models.RPIRankingData
+// @Param asOf query string false "A duration before now (e.g. 72h), a date or an RFC3339 time"
+// @Success 200 {object} responses.RPISnapshotResponse
 // @Router /v1/rpi/{division} [get]
-func HandleGetRPIRankings(c echo.Context) error {
-	// read the query parameters
-	var err error
-	var rankingData []models.RPIRankingData
+func (h *Handler) HandleGetRPIRankings(c echo.Context) error {
+	var (
+		err      error
+		asOf     time.Time
+		snapshot *models.RPISnapshot
+		rows     []models.RPIEvent
+	)
 
 	// read path parameters
 	division := c.Param("division")
@@ -30,13 +36,58 @@ func HandleGetRPIRankings(c echo.Context) error {
 		return c.JSON(http.StatusBadRequest, err.Error())
 	}
 
-	rpiController := controllers.NewRPI()
+	if asOf, err = pkg.ParseAsOf(c.QueryParam("asOf")); err != nil {
+		return c.JSON(http.StatusBadRequest, err.Error())
+	}
+
+	if snapshot, rows, err = h.rpi.Rankings(c.Request().Context(), division, asOf); err != nil {
+		return c.JSON(statusFor(err), err.Error())
+	}
+
+	c.Response().Header().Set("X-Element-Count", strconv.Itoa(len(rows)))
+
+	return c.JSON(http.StatusOK, responses.NewRPISnapshotResponse(*snapshot, rows))
+}
+
+// HandleGetRPIMovers godoc
+// @Summary Compares the stored RPI rankings of an age group at two points in time
+// @Description Lists how the rankings moved between the snapshot current at from and the one current at to, with the teams that entered and dropped out.
+// @Description Without to the latest snapshot is compared, without from the snapshot before it.
+// @Tags RPI
+// @Accept json
+// @Produce json
+// @Param division path string true "Division" Enums(G2006/2005,G2008,G2009,G2010,G2011,B2006/2005,B2008,B2009,B2010,B2011)
+// @Param from query string false "A duration before now (e.g. 168h), a date or an RFC3339 time"
+// @Param to query string false "A duration before now (e.g. 72h), a date or an RFC3339 time"
+// @Success 200 {object} responses.RPIMoversResponse
+// @Router /v1/rpi/{division}/movers [get]
+func (h *Handler) HandleGetRPIMovers(c echo.Context) error {
+	var (
+		err      error
+		from, to time.Time
+		movers   *models.RPIMovers
+	)
+
+	// read path parameters
+	division := c.Param("division")
+
+	if division, err = url.QueryUnescape(division); err != nil {
+		return c.JSON(http.StatusBadRequest, err.Error())
+	}
+
+	if from, err = pkg.ParseAsOf(c.QueryParam("from")); err != nil {
+		return c.JSON(http.StatusBadRequest, err.Error())
+	}
+
+	if to, err = pkg.ParseAsOf(c.QueryParam("to")); err != nil {
+		return c.JSON(http.StatusBadRequest, err.Error())
+	}
 
-	if rankingData, err = rpiController.GenerateRankings(division); err != nil {
-		return c.JSON(http.StatusInternalServerError, err.Error())
+	if movers, err = h.rpi.Movers(c.Request().Context(), division, from, to); err != nil {
+		return c.JSON(statusFor(err), err.Error())
 	}
 
-	c.Response().Header().Set("X-Element-Count", strconv.Itoa(len(rankingData)))
+	c.Response().Header().Set("X-Element-Count", strconv.Itoa(len(movers.Moves)+len(movers.Entries)+len(movers.DropOuts)))
 
-	return c.JSON(200, rankingData)
+	return c.JSON(http.StatusOK, responses.NewRPIMoversResponse(*movers))
 }
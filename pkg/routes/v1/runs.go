@@ -0,0 +1,63 @@
+package v1
+
+import (
+	"github.com/jedi-knights/ecnl/pkg/models"
+	"github.com/jedi-knights/ecnl/pkg/responses"
+	"github.com/labstack/echo/v4"
+	"net/http"
+	"strconv"
+)
+
+// HandleGetRuns godoc
+// @Summary Lists recent runs
+// @Description Lists the runs of the commands that load data, such as sync and rpigen, the most recent first
+// @Tags Runs
+// @Accept json
+// @Produce json
+// @Param command query string false "Only list the runs of this command (e.g. sync)"
+// @Param limit query int false "The maximum number of runs" default(20)
+// @Success 200 {array} responses.RunResponse
+// @Router /v1/runs [get]
+func (h *Handler) HandleGetRuns(c echo.Context) error {
+	var (
+		err   error
+		limit = 20
+		runs  []models.Run
+	)
+
+	if value := c.QueryParam("limit"); value != "" {
+		if limit, err = strconv.Atoi(value); err != nil || limit < 0 {
+			return c.JSON(http.StatusBadRequest, "limit must be a positive number")
+		}
+	}
+
+	if runs, err = h.runs.Recent(c.Request().Context(), c.QueryParam("command"), limit); err != nil {
+		return c.JSON(statusFor(err), err.Error())
+	}
+
+	c.Response().Header().Set("X-Element-Count", strconv.Itoa(len(runs)))
+
+	return c.JSON(http.StatusOK, responses.NewRunResponses(runs))
+}
+
+// HandleGetRun godoc
+// @Summary Gets a run
+// @Description Gets a run with its parameters, counts and errors
+// @Tags Runs
+// @Accept json
+// @Produce json
+// @Param id path string true "Run ID"
+// @Success 200 {object} responses.RunResponse
+// @Router /v1/runs/{id} [get]
+func (h *Handler) HandleGetRun(c echo.Context) error {
+	var (
+		err error
+		run *models.Run
+	)
+
+	if run, err = h.runs.ById(c.Request().Context(), c.Param("id")); err != nil {
+		return c.JSON(statusFor(err), err.Error())
+	}
+
+	return c.JSON(http.StatusOK, responses.NewRunResponse(*run))
+}
@@ -0,0 +1,39 @@
+package v1
+
+import (
+	"github.com/jedi-knights/ecnl/pkg/models"
+	"github.com/jedi-knights/ecnl/pkg/responses"
+	"github.com/labstack/echo/v4"
+	"net/http"
+	"strconv"
+)
+
+// HandleGetTeamRPIHistory godoc
+// @Summary Lists the RPI rankings of a team over time
+// @Description Lists the ranking and RPI of a team in each stored snapshot it is ranked in, the oldest first.
+// @Description A team ranked under several names in a snapshot has a point for each name, flagged as shared. Id 0, shared by unknown teams, is rejected.
+// @Tags Teams
+// @Accept json
+// @Produce json
+// @Param id path int true "Team ID"
+// @Success 200 {array} responses.RPIHistoryPointResponse
+// @Router /v1/teams/{id}/rpi-history [get]
+func (h *Handler) HandleGetTeamRPIHistory(c echo.Context) error {
+	var (
+		err     error
+		id      int
+		history []models.RPIHistoryPoint
+	)
+
+	if id, err = strconv.Atoi(c.Param("id")); err != nil {
+		return c.JSON(http.StatusBadRequest, "id must be a number")
+	}
+
+	if history, err = h.rpi.History(c.Request().Context(), id); err != nil {
+		return c.JSON(statusFor(err), err.Error())
+	}
+
+	c.Response().Header().Set("X-Element-Count", strconv.Itoa(len(history)))
+
+	return c.JSON(http.StatusOK, responses.NewRPIHistoryResponses(history))
+}
@@ -0,0 +1,66 @@
+package scheduler
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SplitArgs splits a command line into its arguments the way a shell does, without expanding anything.
+// Single quotes keep what they enclose as is, double quotes too except that a backslash escapes " and \,
+// and outside of quotes a backslash escapes the next character. For example:
+//
+//	sync --org "ECNL Girls" --only matches
+//
+// splits into sync, --org, ECNL Girls, --only and matches.
+func SplitArgs(line string) ([]string, error) {
+	var (
+		args    []string
+		current strings.Builder
+		inArg   bool
+		quote   rune
+		escaped bool
+	)
+
+	for _, r := range line {
+		switch {
+		case escaped:
+			if quote == '"' && r != '"' && r != '\\' {
+				current.WriteRune('\\')
+			}
+
+			current.WriteRune(r)
+			escaped = false
+		case r == '\\' && quote != '\'':
+			escaped, inArg = true, true
+		case quote != 0 && r == quote:
+			quote = 0
+		case quote != 0:
+			current.WriteRune(r)
+		case r == '\'' || r == '"':
+			quote, inArg = r, true
+		case r == ' ' || r == '\t' || r == '\n':
+			if inArg {
+				args = append(args, current.String())
+				current.Reset()
+				inArg = false
+			}
+		default:
+			current.WriteRune(r)
+			inArg = true
+		}
+	}
+
+	if escaped {
+		return nil, fmt.Errorf("command line ends with a backslash: %s", line)
+	}
+
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated %c quote in command line: %s", quote, line)
+	}
+
+	if inArg {
+		args = append(args, current.String())
+	}
+
+	return args, nil
+}
@@ -0,0 +1,222 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/jedi-knights/ecnl/pkg"
+	"github.com/robfig/cron/v3"
+	"log"
+	"math/rand"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ErrLeaseHeld is returned when another instance holds the lease of a job.
+var ErrLeaseHeld = errors.New("lease held by another instance")
+
+// Job is a unit of work run on a cron schedule.
+type Job struct {
+	Name string
+	// Schedule is a standard cron expression (e.g. "0 6 * * 1") or a descriptor such as @daily or @every 1h.
+	Schedule string
+	Run      func(ctx context.Context) error
+}
+
+// Leaser hands out leases, so that a job only runs on one instance at a time.
+type Leaser interface {
+	// Acquire takes or extends the lease on name for holder, it returns false when another holder has it.
+	Acquire(ctx context.Context, name, holder string, ttl time.Duration) (bool, error)
+	Release(ctx context.Context, name, holder string) error
+}
+
+// Options tune the scheduler.
+type Options struct {
+	// Jitter is the upper bound of the random delay added to every scheduled run,
+	// it keeps instances and jobs from hitting the backend at the same instant.
+	Jitter time.Duration
+	// LeaseTTL is how long a lease is held without being renewed, it is renewed while the job runs.
+	LeaseTTL time.Duration
+	// Holder identifies this instance in the leases.
+	Holder string
+}
+
+// DefaultOptions returns the options used when none are configured.
+func DefaultOptions() Options {
+	return Options{
+		Jitter:   5 * time.Minute,
+		LeaseTTL: 10 * time.Minute,
+		Holder:   "ecnl",
+	}
+}
+
+type entry struct {
+	job      Job
+	schedule cron.Schedule
+}
+
+// Scheduler runs jobs on their schedules.
+type Scheduler struct {
+	entries map[string]entry
+	lease   Leaser
+	opts    Options
+}
+
+// New creates a scheduler, it fails when a job has no name, a duplicate name or an invalid schedule.
+func New(jobs []Job, lease Leaser, opts Options) (*Scheduler, error) {
+	if opts.LeaseTTL <= 0 {
+		opts.LeaseTTL = DefaultOptions().LeaseTTL
+	}
+
+	s := &Scheduler{entries: make(map[string]entry), lease: lease, opts: opts}
+
+	for _, job := range jobs {
+		if job.Name == "" {
+			return nil, errors.New("a scheduled job needs a name")
+		}
+
+		if _, ok := s.entries[job.Name]; ok {
+			return nil, fmt.Errorf("the job '%s' is scheduled more than once", job.Name)
+		}
+
+		schedule, err := cron.ParseStandard(job.Schedule)
+		if err != nil {
+			return nil, fmt.Errorf("invalid schedule '%s' for job '%s': %w", job.Schedule, job.Name, err)
+		}
+
+		s.entries[job.Name] = entry{job: job, schedule: schedule}
+	}
+
+	return s, nil
+}
+
+// Names returns the names of the jobs in alphabetical order.
+func (s *Scheduler) Names() []string {
+	names := make([]string, 0, len(s.entries))
+	for name := range s.entries {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+// Next returns the next time the job is scheduled after now, before jitter is added.
+func (s *Scheduler) Next(name string, now time.Time) (time.Time, error) {
+	e, ok := s.entries[name]
+	if !ok {
+		return time.Time{}, fmt.Errorf("job '%s': %w", name, pkg.ErrNotFound)
+	}
+
+	return e.schedule.Next(now), nil
+}
+
+// Run runs every job on its schedule until ctx is done.
+// A failing run is logged and the job runs again at its next scheduled time.
+func (s *Scheduler) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+
+	for _, name := range s.Names() {
+		wg.Add(1)
+
+		go func(e entry) {
+			defer wg.Done()
+			s.loop(ctx, e)
+		}(s.entries[name])
+	}
+
+	wg.Wait()
+}
+
+// RunNow runs a job immediately, provided no other instance is running it.
+func (s *Scheduler) RunNow(ctx context.Context, name string) error {
+	e, ok := s.entries[name]
+	if !ok {
+		return fmt.Errorf("job '%s': %w", name, pkg.ErrNotFound)
+	}
+
+	return s.run(ctx, e.job)
+}
+
+func (s *Scheduler) loop(ctx context.Context, e entry) {
+	for {
+		next := e.schedule.Next(time.Now()).Add(s.jitter())
+
+		log.Printf("Job '%s' is scheduled to run at %s", e.job.Name, next.Format(time.RFC3339))
+
+		timer := time.NewTimer(time.Until(next))
+
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		switch err := s.run(ctx, e.job); {
+		case errors.Is(err, ErrLeaseHeld):
+			log.Printf("Skipped job '%s': %v", e.job.Name, err)
+		case err != nil:
+			log.Printf("Job '%s' failed: %v", e.job.Name, err)
+		}
+	}
+}
+
+// run runs the job while holding its lease, renewing the lease until the job returns.
+func (s *Scheduler) run(ctx context.Context, job Job) error {
+	acquired, err := s.lease.Acquire(ctx, job.Name, s.opts.Holder, s.opts.LeaseTTL)
+	if err != nil {
+		return fmt.Errorf("error acquiring the lease of job '%s': %w", job.Name, err)
+	}
+
+	if !acquired {
+		return fmt.Errorf("job '%s': %w", job.Name, ErrLeaseHeld)
+	}
+
+	// The lease is released even when the job was cancelled, the next holder need not wait for it to expire.
+	defer func() {
+		if err := s.lease.Release(context.WithoutCancel(ctx), job.Name, s.opts.Holder); err != nil {
+			log.Printf("error releasing the lease of job '%s': %v", job.Name, err)
+		}
+	}()
+
+	done := make(chan struct{})
+	defer close(done)
+
+	go s.renew(ctx, job.Name, done)
+
+	log.Printf("Running job '%s'", job.Name)
+
+	start := time.Now()
+	err = job.Run(ctx)
+
+	log.Printf("Job '%s' finished in %s", job.Name, time.Since(start).Round(time.Second))
+
+	return err
+}
+
+func (s *Scheduler) renew(ctx context.Context, name string, done <-chan struct{}) {
+	ticker := time.NewTicker(s.opts.LeaseTTL / 2)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+			if acquired, err := s.lease.Acquire(ctx, name, s.opts.Holder, s.opts.LeaseTTL); err != nil || !acquired {
+				log.Printf("error renewing the lease of job '%s': acquired %t, %v", name, acquired, err)
+			}
+		}
+	}
+}
+
+func (s *Scheduler) jitter() time.Duration {
+	if s.opts.Jitter <= 0 {
+		return 0
+	}
+
+	return time.Duration(rand.Int63n(int64(s.opts.Jitter)))
+}
@@ -0,0 +1,191 @@
+package scheduler_test
+
+import (
+	"context"
+	"github.com/jedi-knights/ecnl/pkg"
+	"github.com/jedi-knights/ecnl/pkg/scheduler"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// leases is an in memory Leaser.
+type leases struct {
+	mu      sync.Mutex
+	holders map[string]string
+}
+
+func (l *leases) Acquire(_ context.Context, name, holder string, ttl time.Duration) (bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if current, ok := l.holders[name]; ok && current != holder {
+		return false, nil
+	}
+
+	l.holders[name] = holder
+
+	return true, nil
+}
+
+func (l *leases) Release(_ context.Context, name, holder string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.holders[name] == holder {
+		delete(l.holders, name)
+	}
+
+	return nil
+}
+
+var _ = Describe("Scheduler", func() {
+	var (
+		lease *leases
+		runs  atomic.Int32
+		opts  scheduler.Options
+		job   scheduler.Job
+	)
+
+	BeforeEach(func() {
+		lease = &leases{holders: make(map[string]string)}
+		runs.Store(0)
+		opts = scheduler.Options{LeaseTTL: time.Minute, Holder: "test"}
+		job = scheduler.Job{
+			Name:     "sync",
+			Schedule: "0 6 * * 1",
+			Run: func(ctx context.Context) error {
+				runs.Add(1)
+				return nil
+			},
+		}
+	})
+
+	It("should reject an invalid schedule", func() {
+		// Arrange
+		job.Schedule = "every monday"
+
+		// Act
+		_, err := scheduler.New([]scheduler.Job{job}, lease, opts)
+
+		// Assert
+		Expect(err).To(MatchError(ContainSubstring("invalid schedule")))
+	})
+
+	It("should reject a job scheduled twice", func() {
+		// Act
+		_, err := scheduler.New([]scheduler.Job{job, job}, lease, opts)
+
+		// Assert
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should compute the next run from the cron expression", func() {
+		// Arrange
+		s, err := scheduler.New([]scheduler.Job{job}, lease, opts)
+		Expect(err).NotTo(HaveOccurred())
+		saturday := time.Date(2023, 10, 7, 12, 0, 0, 0, time.UTC)
+
+		// Act
+		next, err := s.Next("sync", saturday)
+
+		// Assert
+		Expect(err).NotTo(HaveOccurred())
+		Expect(next).To(Equal(time.Date(2023, 10, 9, 6, 0, 0, 0, time.UTC)))
+	})
+
+	It("should run a job ad hoc and release its lease", func() {
+		// Arrange
+		s, err := scheduler.New([]scheduler.Job{job}, lease, opts)
+		Expect(err).NotTo(HaveOccurred())
+
+		// Act
+		err = s.RunNow(context.Background(), "sync")
+
+		// Assert
+		Expect(err).NotTo(HaveOccurred())
+		Expect(runs.Load()).To(Equal(int32(1)))
+		Expect(lease.holders).To(BeEmpty())
+	})
+
+	It("should not run a job while another instance holds its lease", func() {
+		// Arrange
+		s, err := scheduler.New([]scheduler.Job{job}, lease, opts)
+		Expect(err).NotTo(HaveOccurred())
+		lease.holders["sync"] = "other"
+
+		// Act
+		err = s.RunNow(context.Background(), "sync")
+
+		// Assert
+		Expect(err).To(MatchError(scheduler.ErrLeaseHeld))
+		Expect(runs.Load()).To(BeZero())
+		Expect(lease.holders["sync"]).To(Equal("other"))
+	})
+
+	It("should fail to run an unknown job", func() {
+		// Arrange
+		s, err := scheduler.New([]scheduler.Job{job}, lease, opts)
+		Expect(err).NotTo(HaveOccurred())
+
+		// Act
+		err = s.RunNow(context.Background(), "rpigen")
+
+		// Assert
+		Expect(err).To(MatchError(pkg.ErrNotFound))
+	})
+
+	It("should run jobs on their schedule until cancelled", func() {
+		// Arrange
+		job.Schedule = "@every 1s"
+		s, err := scheduler.New([]scheduler.Job{job}, lease, opts)
+		Expect(err).NotTo(HaveOccurred())
+		ctx, cancel := context.WithTimeout(context.Background(), 2500*time.Millisecond)
+		defer cancel()
+
+		// Act
+		s.Run(ctx)
+
+		// Assert
+		Expect(runs.Load()).To(BeNumerically(">=", 1))
+	})
+})
+
+var _ = Describe("SplitArgs", func() {
+	It("should keep an argument that contains a space together when quoted", func() {
+		// Act
+		args, err := scheduler.SplitArgs(`sync --org "ECNL Girls" --only  matches`)
+
+		// Assert
+		Expect(err).NotTo(HaveOccurred())
+		Expect(args).To(Equal([]string{"sync", "--org", "ECNL Girls", "--only", "matches"}))
+	})
+
+	It("should honor single quotes and escapes", func() {
+		// Act
+		args, err := scheduler.SplitArgs(`sync --club 'Club "A"' --event ECNL\ Girls --name "say \"hi\""`)
+
+		// Assert
+		Expect(err).NotTo(HaveOccurred())
+		Expect(args).To(Equal([]string{"sync", "--club", `Club "A"`, "--event", "ECNL Girls", "--name", `say "hi"`}))
+	})
+
+	It("should keep an empty quoted argument", func() {
+		// Act
+		args, err := scheduler.SplitArgs(`sync --since ""`)
+
+		// Assert
+		Expect(err).NotTo(HaveOccurred())
+		Expect(args).To(Equal([]string{"sync", "--since", ""}))
+	})
+
+	It("should reject an unterminated quote", func() {
+		// Act
+		_, err := scheduler.SplitArgs(`sync --org "ECNL Girls`)
+
+		// Assert
+		Expect(err).To(HaveOccurred())
+	})
+})
@@ -0,0 +1,183 @@
+package services
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sync"
+)
+
+// Interaction is a single recorded request and the response it received.
+type Interaction struct {
+	Method string      `json:"method"`
+	Url    string      `json:"url"`
+	Status int         `json:"status"`
+	Header http.Header `json:"header,omitempty"`
+	Body   string      `json:"body"`
+}
+
+// key identifies the interaction independently of the host it was recorded against,
+// so a cassette recorded against the live site can be replayed against any base url.
+func (i Interaction) key() string {
+	return interactionKey(i.Method, i.Url)
+}
+
+func interactionKey(method, rawUrl string) string {
+	if parsed, err := url.Parse(rawUrl); err == nil {
+		return method + " " + parsed.RequestURI()
+	}
+
+	return method + " " + rawUrl
+}
+
+// Recorder is an http.RoundTripper that appends every interaction to a cassette file.
+// The cassette is newline delimited JSON, one interaction per line.
+type Recorder struct {
+	base http.RoundTripper
+	mu   sync.Mutex
+	file *os.File
+}
+
+// NewRecorder creates a recorder that forwards requests to base and appends them to the cassette at path.
+func NewRecorder(base http.RoundTripper, path string) (*Recorder, error) {
+	var (
+		err  error
+		file *os.File
+	)
+
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	if file, err = os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644); err != nil {
+		return nil, fmt.Errorf("error opening cassette '%s': %w", path, err)
+	}
+
+	return &Recorder{base: base, file: file}, nil
+}
+
+// RoundTrip implements http.RoundTripper.
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	var (
+		err  error
+		body []byte
+		res  *http.Response
+		line []byte
+	)
+
+	if res, err = r.base.RoundTrip(req); err != nil {
+		return nil, err
+	}
+
+	body, err = io.ReadAll(res.Body)
+	_ = res.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	res.Body = io.NopCloser(bytes.NewReader(body))
+
+	// Only the content type is kept, the remaining headers are noise in a cassette.
+	header := make(http.Header)
+	if contentType := res.Header.Get("Content-Type"); contentType != "" {
+		header.Set("Content-Type", contentType)
+	}
+
+	if line, err = json.Marshal(Interaction{
+		Method: req.Method,
+		Url:    req.URL.String(),
+		Status: res.StatusCode,
+		Header: header,
+		Body:   string(body),
+	}); err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, err = r.file.Write(append(line, '\n')); err != nil {
+		return nil, fmt.Errorf("error writing cassette: %w", err)
+	}
+
+	return res, nil
+}
+
+// Close closes the cassette file.
+func (r *Recorder) Close() error {
+	return r.file.Close()
+}
+
+// Replayer is an http.RoundTripper that answers requests from a recorded cassette without touching the network.
+type Replayer struct {
+	interactions map[string]Interaction
+}
+
+// NewReplayer loads the cassette at path.
+// When the same request was recorded more than once the last recording wins.
+func NewReplayer(path string) (*Replayer, error) {
+	var (
+		err  error
+		file *os.File
+	)
+
+	if file, err = os.Open(path); err != nil {
+		return nil, fmt.Errorf("error opening cassette '%s': %w", path, err)
+	}
+	defer file.Close()
+
+	replayer := &Replayer{interactions: make(map[string]Interaction)}
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 64*1024*1024)
+
+	for scanner.Scan() {
+		var interaction Interaction
+
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+
+		if err = json.Unmarshal(scanner.Bytes(), &interaction); err != nil {
+			return nil, fmt.Errorf("error reading cassette '%s': %w", path, err)
+		}
+
+		replayer.interactions[interaction.key()] = interaction
+	}
+
+	if err = scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading cassette '%s': %w", path, err)
+	}
+
+	return replayer, nil
+}
+
+// RoundTrip implements http.RoundTripper.
+func (r *Replayer) RoundTrip(req *http.Request) (*http.Response, error) {
+	interaction, ok := r.interactions[interactionKey(req.Method, req.URL.String())]
+	if !ok {
+		return nil, fmt.Errorf("no recorded interaction for %s %s", req.Method, req.URL.RequestURI())
+	}
+
+	header := interaction.Header.Clone()
+	if header == nil {
+		header = make(http.Header)
+	}
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", interaction.Status, http.StatusText(interaction.Status)),
+		StatusCode:    interaction.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader([]byte(interaction.Body))),
+		ContentLength: int64(len(interaction.Body)),
+		Request:       req,
+	}, nil
+}
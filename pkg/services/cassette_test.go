@@ -0,0 +1,68 @@
+package services_test
+
+import (
+	"github.com/jedi-knights/ecnl/pkg/services"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+)
+
+var _ = Describe("Cassette", func() {
+	var (
+		path   string
+		server *httptest.Server
+	)
+
+	BeforeEach(func() {
+		path = filepath.Join(GinkgoT().TempDir(), "test.cassette")
+
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"result":"success","data":"` + r.URL.Path + `"}`))
+		}))
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("should replay what was recorded regardless of the host", func() {
+		// Arrange
+		recorder, err := services.NewRecorder(nil, path)
+		Expect(err).NotTo(HaveOccurred())
+
+		res, err := (&http.Client{Transport: recorder}).Get(server.URL + "/api/Mobile/get-event-types")
+		Expect(err).NotTo(HaveOccurred())
+		recorded, _ := io.ReadAll(res.Body)
+		Expect(res.Body.Close()).To(Succeed())
+		Expect(recorder.Close()).To(Succeed())
+		server.Close()
+
+		// Act
+		replayer, err := services.NewReplayer(path)
+		Expect(err).NotTo(HaveOccurred())
+		res, err = (&http.Client{Transport: replayer}).Get("https://example.com/api/Mobile/get-event-types")
+
+		// Assert
+		Expect(err).NotTo(HaveOccurred())
+		replayed, _ := io.ReadAll(res.Body)
+		Expect(res.StatusCode).To(Equal(http.StatusOK))
+		Expect(res.Header.Get("Content-Type")).To(Equal("application/json"))
+		Expect(replayed).To(Equal(recorded))
+	})
+
+	It("should fail for requests that were never recorded", func() {
+		// Arrange
+		replayer, err := services.NewReplayer(cassettePath)
+		Expect(err).NotTo(HaveOccurred())
+
+		// Act
+		_, err = (&http.Client{Transport: replayer}).Get("https://example.com/api/Unknown")
+
+		// Assert
+		Expect(err).To(MatchError(ContainSubstring("no recorded interaction")))
+	})
+})
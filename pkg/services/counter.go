@@ -0,0 +1,33 @@
+package services
+
+import (
+	"net/http"
+	"sync/atomic"
+)
+
+// CallCounter is an http.RoundTripper that counts the requests sent through it.
+type CallCounter struct {
+	base  http.RoundTripper
+	calls atomic.Int64
+}
+
+// NewCallCounter creates a counter that forwards requests to base.
+func NewCallCounter(base http.RoundTripper) *CallCounter {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	return &CallCounter{base: base}
+}
+
+// RoundTrip implements http.RoundTripper.
+func (c *CallCounter) RoundTrip(req *http.Request) (*http.Response, error) {
+	c.calls.Add(1)
+
+	return c.base.RoundTrip(req)
+}
+
+// Calls returns the number of requests sent so far.
+func (c *CallCounter) Calls() int64 {
+	return c.calls.Load()
+}
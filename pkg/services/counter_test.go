@@ -0,0 +1,32 @@
+package services_test
+
+import (
+	"github.com/jedi-knights/ecnl/pkg/services"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"net/http"
+	"net/http/httptest"
+)
+
+var _ = Describe("CallCounter", func() {
+	It("should count every request sent through it", func() {
+		// Arrange
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNotFound)
+		}))
+		defer server.Close()
+
+		counter := services.NewCallCounter(nil)
+		client := &http.Client{Transport: counter}
+
+		// Act
+		for i := 0; i < 3; i++ {
+			res, err := client.Get(server.URL)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(res.Body.Close()).To(Succeed())
+		}
+
+		// Assert
+		Expect(counter.Calls()).To(Equal(int64(3)))
+	})
+})
@@ -0,0 +1,69 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/jedi-knights/ecnl/pkg"
+	"io"
+	"net/http"
+	"net/url"
+)
+
+// envelope is the wrapper every TGS endpoint puts around its payload.
+type envelope[T any] struct {
+	Result string `json:"result"`
+	Data   T      `json:"data"`
+}
+
+// getEnvelope issues a GET for the path relative to the service url and stream-decodes the response envelope.
+// It returns the data of the envelope once both the status code and the result field indicate success.
+// Go does not allow type parameters on methods so the service is passed explicitly.
+func getEnvelope[T any](ctx context.Context, s *GlobalService, path string) (T, error) {
+	var (
+		err       error
+		zero      T
+		targetUrl string
+		pResponse *http.Response
+		head      snippetWriter
+		output    envelope[T]
+	)
+
+	if targetUrl, err = url.JoinPath(s.Url(), path); err != nil {
+		return zero, err
+	}
+
+	if pResponse, err = s.get(ctx, targetUrl); err != nil {
+		return zero, fmt.Errorf("error getting %s: %w", targetUrl, err)
+	}
+	defer pResponse.Body.Close()
+
+	if pResponse.StatusCode != http.StatusOK {
+		return zero, &pkg.UpstreamStatusError{Code: pResponse.StatusCode, URL: targetUrl}
+	}
+
+	// The first bytes of the body are kept on the side so a decode failure can show what was received.
+	if err = json.NewDecoder(io.TeeReader(pResponse.Body, &head)).Decode(&output); err != nil {
+		return zero, pkg.NewDecodeError(targetUrl, head.data, err)
+	}
+
+	if output.Result != "success" {
+		return zero, fmt.Errorf("%s responded with result '%s': %w", targetUrl, output.Result, pkg.ErrUpstreamFailure)
+	}
+
+	return output.Data, nil
+}
+
+// snippetWriter keeps the first pkg.SnippetLength bytes written to it and discards the rest.
+type snippetWriter struct {
+	data []byte
+}
+
+// Write implements io.Writer.
+func (w *snippetWriter) Write(p []byte) (int, error) {
+	if remaining := pkg.SnippetLength - len(w.data); remaining > 0 {
+		w.data = append(w.data, p[:min(remaining, len(p))]...)
+	}
+
+	return len(p), nil
+}
@@ -0,0 +1,69 @@
+package services_test
+
+import (
+	"context"
+	"errors"
+	"github.com/jedi-knights/ecnl/pkg"
+	"github.com/jedi-knights/ecnl/pkg/services"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"net/http"
+	"net/http/httptest"
+)
+
+var _ = Describe("Envelope", func() {
+	var (
+		body   string
+		server *httptest.Server
+		svc    *services.GlobalService
+	)
+
+	BeforeEach(func() {
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(body))
+		}))
+
+		svc = services.NewTGSServiceWithClient(server.Client())
+		svc.BaseUrl = server.URL
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("should decode the data of a successful response", func() {
+		// Arrange
+		body = `{"result":"success","data":[{"stateID":1,"stateName":"Alabama"}]}`
+
+		// Act
+		states, err := svc.States(context.Background())
+
+		// Assert
+		Expect(err).NotTo(HaveOccurred())
+		Expect(states).To(HaveLen(1))
+	})
+
+	It("should fail when the result is not success", func() {
+		// Arrange
+		body = `{"result":"error","data":null}`
+
+		// Act
+		_, err := svc.States(context.Background())
+
+		// Assert
+		Expect(err).To(MatchError(pkg.ErrUpstreamFailure))
+	})
+
+	It("should return a decode error holding the start of the body", func() {
+		// Arrange
+		body = `<html>maintenance</html>`
+
+		// Act
+		_, err := svc.Countries(context.Background())
+
+		// Assert
+		var decodeErr *pkg.DecodeError
+		Expect(errors.As(err, &decodeErr)).To(BeTrue())
+		Expect(decodeErr.Body).To(ContainSubstring("maintenance"))
+	})
+})
@@ -1,38 +1,76 @@
 package services
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
 	"github.com/jedi-knights/ecnl/pkg"
+	"github.com/jedi-knights/ecnl/pkg/cache"
 	"github.com/jedi-knights/ecnl/pkg/models"
 	"github.com/jedi-knights/rpi/pkg/match"
 	"github.com/jedi-knights/rpi/pkg/schedule"
-	"io"
 	"log"
 	"net/http"
-	"net/url"
 	"slices"
 	"sort"
 	"strings"
 	"time"
 )
 
-var organizationClubMap map[int][]models.Club
+// Cache keys used by the service.
+const (
+	organizationsKey     = "organizations"
+	clubsByOrgKeyFormat  = "clubs:org:%d"
+	clubByNameKeyFormat  = "club:name:%s"
+	eventKeyFormat       = "event:%d"
+	eventsByOrgKeyFormat = "events:org:%d"
+)
+
+// organizationDivisionList is the payload of /api/Event/get-org-division-list.
+type organizationDivisionList struct {
+	OrgId        int                          `json:"orgID"`
+	OrgSeasonId  int                          `json:"orgSeasonID"`
+	DivisionList []models.OrganiationDivision `json:"divisionList"`
+}
+
+// scheduleList is the payload of /api/Club/get-score-reporting-schedule-list.
+type scheduleList struct {
+	ReportedScore   int                 `json:"reportedScore"`
+	UnReportedScore int                 `json:"unReportedScore"`
+	MatchEvents     []models.MatchEvent `json:"eventPastScheduleList"`
+}
+
+// teamList is the payload of /api/Event/get-event-division-teams.
+type teamList struct {
+	TeamList []models.Team `json:"teamList"`
+}
 
 type GlobalServicer interface {
 	Url() string
 	Client() *http.Client
-	Organizations(ecnlOnly bool) ([]models.Organization, error)
-	OrganizationByName(name string) (*models.Organization, error)
-	OrganizationById(id int) (*models.Organization, error)
+	Organizations(ctx context.Context, ecnlOnly bool) ([]models.Organization, error)
+	OrganizationByName(ctx context.Context, name string) (*models.Organization, error)
+	OrganizationById(ctx context.Context, id int) (*models.Organization, error)
 }
 
 type GlobalService struct {
 	HttpClient *http.Client
+	// BaseUrl is the root of the TGS API (e.g. https://public.totalglobalsports.com).
+	BaseUrl string
+	// Cache holds upstream responses for the lifetime of the service. A nil cache disables caching.
+	Cache cache.Cacher
 }
 
+// NewTGSService creates a service using the default transport options.
 func NewTGSService() *GlobalService {
-	pHttpClient := &http.Client{}
+	return NewTGSServiceWithOptions(DefaultTransportOptions())
+}
+
+// NewTGSServiceWithOptions creates a service whose client retries and rate limits
+// requests according to the given transport options.
+func NewTGSServiceWithOptions(opts TransportOptions) *GlobalService {
+	pHttpClient := &http.Client{
+		Transport: NewTransport(http.DefaultTransport, opts),
+	}
 
 	return NewTGSServiceWithClient(pHttpClient)
 }
@@ -40,137 +78,82 @@ func NewTGSService() *GlobalService {
 func NewTGSServiceWithClient(pHttpClient *http.Client) *GlobalService {
 	return &GlobalService{
 		HttpClient: pHttpClient,
+		BaseUrl:    pkg.TgsPrefix,
+		Cache:      cache.NewMemory(0, 0),
 	}
 }
 
 func (s *GlobalService) Url() string {
-	return pkg.TgsPrefix
+	if s.BaseUrl == "" {
+		return pkg.TgsPrefix
+	}
+
+	return s.BaseUrl
 }
 
 func (s *GlobalService) Client() *http.Client {
 	return s.HttpClient
 }
 
-func (s *GlobalService) States() ([]models.State, error) {
-	var err error
-	var data []byte
-	var targetUrl string
-	var pResponse *http.Response
-	var output struct {
-		Result string         `json:"result"`
-		States []models.State `json:"data"`
-	}
-
-	if targetUrl, err = url.JoinPath(s.Url(), "/api/Association", "/get-all-states"); err != nil {
-		return nil, err
-	}
-
-	if pResponse, err = s.HttpClient.Get(targetUrl); err != nil {
-		return nil, fmt.Errorf("Error getting countries: %v\n", err)
+// InvalidateCache discards everything the service has cached so the next calls go upstream.
+func (s *GlobalService) InvalidateCache() {
+	if s.Cache != nil {
+		s.Cache.Purge()
 	}
-	defer pResponse.Body.Close()
-
-	if pResponse.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("Invalid status code %d: ", pResponse.StatusCode)
-	}
-
-	if data, err = io.ReadAll(pResponse.Body); err != nil {
-		return nil, fmt.Errorf("Error reading response body: %v\n", err)
-	}
-
-	if err = json.Unmarshal(data, &output); err != nil {
-		return nil, fmt.Errorf("Error unmarshalling response body: %v\n", err)
-	}
-
-	return output.States, nil
 }
 
-func (s *GlobalService) Countries() ([]models.Country, error) {
+// get issues a GET request for the target url that is bound to the given context.
+func (s *GlobalService) get(ctx context.Context, targetUrl string) (*http.Response, error) {
 	var (
-		err       error
-		data      []byte
-		targetUrl string
-		pResponse *http.Response
-		output    struct {
-			Result    string           `json:"result"`
-			Countries []models.Country `json:"data"`
-		}
+		err error
+		req *http.Request
 	)
 
-	if targetUrl, err = url.JoinPath(s.Url(), "/api/Association", "/get-all-countries"); err != nil {
+	if req, err = http.NewRequestWithContext(ctx, http.MethodGet, targetUrl, nil); err != nil {
 		return nil, err
 	}
 
-	if pResponse, err = s.HttpClient.Get(targetUrl); err != nil {
-		return nil, fmt.Errorf("Error getting countries: %v\n", err)
-	}
-
-	defer pResponse.Body.Close()
-
-	if pResponse.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("Invalid status code %d: ", pResponse.StatusCode)
-	}
-
-	if data, err = io.ReadAll(pResponse.Body); err != nil {
-		return nil, fmt.Errorf("Error reading response body: %v\n", err)
-	}
-
-	if err = json.Unmarshal(data, &output); err != nil {
-		return nil, fmt.Errorf("Error unmarshalling response body: %v\n", err)
-	}
+	return s.HttpClient.Do(req)
+}
 
-	return output.Countries, nil
+func (s *GlobalService) States(ctx context.Context) ([]models.State, error) {
+	return getEnvelope[[]models.State](ctx, s, "/api/Association/get-all-states")
 }
 
-var organizationCache []models.Organization
+func (s *GlobalService) Countries(ctx context.Context) ([]models.Country, error) {
+	return getEnvelope[[]models.Country](ctx, s, "/api/Association/get-all-countries")
+}
 
 // Organizations returns the list of organizations for the current season.
 // If ecnlOnly is true, then only ECNL organizations are returned.
 // If ecnlOnly is false, then all organizations are returned.
 // This function accesses the /api/Association/get-current-orgs-list endpoint.
 // Each organization has a name and id, a season id, and a season group id.
-func (s *GlobalService) Organizations(ecnlOnly bool) ([]models.Organization, error) {
+func (s *GlobalService) Organizations(ctx context.Context, ecnlOnly bool) ([]models.Organization, error) {
 	var (
-		err                  error
-		data                 []byte
-		targetUrl            string
-		pResponse            *http.Response
-		currentOrganizations []models.Organization
-		output               struct {
-			Result        string                `json:"result"`
-			Organizations []models.Organization `json:"data"`
-		}
+		err           error
+		organizations []models.Organization
 	)
 
-	if organizationCache != nil {
-		return organizationCache, nil
+	if organizations, ok := cache.Get[[]models.Organization](s.Cache, organizationsKey); ok {
+		return filterOrganizations(organizations, ecnlOnly), nil
 	}
 
-	if targetUrl, err = url.JoinPath(s.Url(), "/api/Association", "/get-current-orgs-list"); err != nil {
+	if organizations, err = getEnvelope[[]models.Organization](ctx, s, "/api/Association/get-current-orgs-list"); err != nil {
 		return nil, err
 	}
 
-	if pResponse, err = s.HttpClient.Get(targetUrl); err != nil {
-		return nil, fmt.Errorf("Error getting current organizations: %v\n", err)
-	}
+	// The unfiltered list is cached so that both kinds of callers can be served from it.
+	cache.Set(s.Cache, organizationsKey, organizations)
 
-	defer pResponse.Body.Close()
-
-	if pResponse.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("Invalid status code %d: ", pResponse.StatusCode)
-	}
-
-	if data, err = io.ReadAll(pResponse.Body); err != nil {
-		return nil, fmt.Errorf("Error reading response body: %v\n", err)
-	}
-
-	if err = json.Unmarshal(data, &output); err != nil {
-		return nil, fmt.Errorf("Error unmarshalling response body: %v\n", err)
-	}
+	return filterOrganizations(organizations, ecnlOnly), nil
+}
 
+// filterOrganizations returns a copy of the organizations, optionally restricted to ECNL organizations.
+func filterOrganizations(organizations []models.Organization, ecnlOnly bool) []models.Organization {
 	ecnlOrganizations := make([]models.Organization, 0)
 
-	for _, org := range output.Organizations {
+	for _, org := range organizations {
 		if ecnlOnly {
 			if strings.Contains(org.Name, "ECNL") {
 				ecnlOrganizations = append(ecnlOrganizations, org)
@@ -180,21 +163,17 @@ func (s *GlobalService) Organizations(ecnlOnly bool) ([]models.Organization, err
 		}
 	}
 
-	currentOrganizations = ecnlOrganizations
-
-	organizationCache = currentOrganizations
-
-	return currentOrganizations, nil
+	return ecnlOrganizations
 }
 
-func (s *GlobalService) OrganizationByName(name string) (*models.Organization, error) {
+func (s *GlobalService) OrganizationByName(ctx context.Context, name string) (*models.Organization, error) {
 	var (
 		err                  error
 		currentOrganizations []models.Organization
 	)
 
 	if currentOrganizations == nil {
-		if currentOrganizations, err = s.Organizations(false); err != nil {
+		if currentOrganizations, err = s.Organizations(ctx, false); err != nil {
 			return nil, err
 		}
 	}
@@ -205,17 +184,17 @@ func (s *GlobalService) OrganizationByName(name string) (*models.Organization, e
 		}
 	}
 
-	return nil, fmt.Errorf("organization name %svc not found", name)
+	return nil, fmt.Errorf("organization name '%s': %w", name, pkg.ErrNotFound)
 }
 
-func (s *GlobalService) OrganizationById(id int) (*models.Organization, error) {
+func (s *GlobalService) OrganizationById(ctx context.Context, id int) (*models.Organization, error) {
 	var (
 		err                  error
 		currentOrganizations []models.Organization
 	)
 
 	if currentOrganizations == nil {
-		if currentOrganizations, err = s.Organizations(false); err != nil {
+		if currentOrganizations, err = s.Organizations(ctx, false); err != nil {
 			return nil, err
 		}
 	}
@@ -226,93 +205,61 @@ func (s *GlobalService) OrganizationById(id int) (*models.Organization, error) {
 		}
 	}
 
-	return nil, fmt.Errorf("organization id %d not found", id)
+	return nil, fmt.Errorf("organization id %d: %w", id, pkg.ErrNotFound)
 }
 
-func (s *GlobalService) ClubsByOrganization(org models.Organization) ([]models.Club, error) {
-	return s.ClubsByOrganizationId(org.Id)
+func (s *GlobalService) ClubsByOrganization(ctx context.Context, org models.Organization) ([]models.Club, error) {
+	return s.ClubsByOrganizationId(ctx, org.Id)
 }
 
-func (s *GlobalService) ClubsByOrganizationId(orgId int) ([]models.Club, error) {
+func (s *GlobalService) ClubsByOrganizationId(ctx context.Context, orgId int) ([]models.Club, error) {
 	var (
-		err       error
-		data      []byte
-		targetUrl string
-		pResponse *http.Response
-		output    struct {
-			Result string        `json:"result"`
-			Clubs  []models.Club `json:"data"`
-		}
+		err   error
+		clubs []models.Club
 	)
 
-	if organizationClubMap == nil {
-		organizationClubMap = make(map[int][]models.Club)
-	}
-
-	if clubs, ok := organizationClubMap[orgId]; ok {
+	key := fmt.Sprintf(clubsByOrgKeyFormat, orgId)
+	if clubs, ok := cache.Get[[]models.Club](s.Cache, key); ok {
 		return clubs, nil
 	}
 
-	suffix := fmt.Sprintf("get-org-club-list-by-orgID-improved/%d", orgId)
-	if targetUrl, err = url.JoinPath(s.Url(), "/api/Event", suffix); err != nil {
+	path := fmt.Sprintf("/api/Event/get-org-club-list-by-orgID-improved/%d", orgId)
+	if clubs, err = getEnvelope[[]models.Club](ctx, s, path); err != nil {
 		return nil, err
 	}
 
-	if pResponse, err = s.Client().Get(targetUrl); err != nil {
-		return nil, fmt.Errorf("error getting clubs for org %d: %v", orgId, err)
-	}
-
-	defer func(Body io.ReadCloser) {
-		err := Body.Close()
-		if err != nil {
-			fmt.Println(err.Error())
-		}
-	}(pResponse.Body)
-
-	if pResponse.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("error getting clubs for org %d: invalid status code %d", orgId, pResponse.StatusCode)
-	}
-
-	if data, err = io.ReadAll(pResponse.Body); err != nil {
-		return nil, fmt.Errorf("error reading response body: %v", err)
-	}
-
-	if err = json.Unmarshal(data, &output); err != nil {
-		return nil, fmt.Errorf("error unmarshalling response body: %v", err)
-	}
-
-	organizationClubMap[orgId] = output.Clubs
+	cache.Set(s.Cache, key, clubs)
 
-	return output.Clubs, nil
+	return clubs, nil
 }
 
-func (s *GlobalService) ClubsByOrgName(orgName string) ([]models.Club, error) {
+func (s *GlobalService) ClubsByOrgName(ctx context.Context, orgName string) ([]models.Club, error) {
 	var (
 		err error
 		org *models.Organization
 	)
 
-	if org, err = s.OrganizationByName(orgName); err != nil {
+	if org, err = s.OrganizationByName(ctx, orgName); err != nil {
 		return nil, err
 	}
 
-	return s.ClubsByOrganization(*org)
+	return s.ClubsByOrganization(ctx, *org)
 }
 
-func (s *GlobalService) OrganizationForClubName(clubName string) (*models.Organization, error) {
+func (s *GlobalService) OrganizationForClubName(ctx context.Context, clubName string) (*models.Organization, error) {
 	var (
 		err  error
 		orgs []models.Organization
 	)
 
-	if orgs, err = s.Organizations(false); err != nil {
+	if orgs, err = s.Organizations(ctx, false); err != nil {
 		return nil, err
 	}
 
 	for _, org := range orgs {
 		var clubs []models.Club
 
-		if clubs, err = s.ClubsByOrganization(org); err != nil {
+		if clubs, err = s.ClubsByOrganization(ctx, org); err != nil {
 			return nil, err
 		}
 
@@ -323,58 +270,51 @@ func (s *GlobalService) OrganizationForClubName(clubName string) (*models.Organi
 		}
 	}
 
-	return nil, fmt.Errorf("organization for club '%svc' not found", clubName)
+	return nil, fmt.Errorf("organization for club '%s': %w", clubName, pkg.ErrNotFound)
 }
 
-var clubsByNameCache map[string]*models.Club
-
-func (s *GlobalService) ClubByName(clubName string) (*models.Club, error) {
+func (s *GlobalService) ClubByName(ctx context.Context, clubName string) (*models.Club, error) {
 	var (
 		err  error
 		orgs []models.Organization
 	)
 
-	if clubsByNameCache != nil {
-		if club, ok := clubsByNameCache[clubName]; ok {
-			return club, nil
-		}
+	key := fmt.Sprintf(clubByNameKeyFormat, clubName)
+	if club, ok := cache.Get[*models.Club](s.Cache, key); ok {
+		return club, nil
 	}
 
-	if orgs, err = s.Organizations(false); err != nil {
+	if orgs, err = s.Organizations(ctx, false); err != nil {
 		return nil, err
 	}
 
 	for _, org := range orgs {
 		var clubs []models.Club
 
-		if clubs, err = s.ClubsByOrganization(org); err != nil {
+		if clubs, err = s.ClubsByOrganization(ctx, org); err != nil {
 			return nil, err
 		}
 
 		for _, club := range clubs {
 			if club.Name == clubName {
-				if clubsByNameCache == nil {
-					clubsByNameCache = make(map[string]*models.Club)
-
-					clubsByNameCache[clubName] = &club
-				}
+				cache.Set(s.Cache, key, &club)
 
 				return &club, nil
 			}
 		}
 	}
 
-	return nil, fmt.Errorf("club '%svc' not found", clubName)
+	return nil, fmt.Errorf("club '%s': %w", clubName, pkg.ErrNotFound)
 }
 
-func (s *GlobalService) EventIdsByOrgId(orgId int) ([]int, error) {
+func (s *GlobalService) EventIdsByOrgId(ctx context.Context, orgId int) ([]int, error) {
 	var (
 		err      error
 		clubs    []models.Club
 		eventIds []int
 	)
 
-	if clubs, err = s.ClubsByOrganizationId(orgId); err != nil {
+	if clubs, err = s.ClubsByOrganizationId(ctx, orgId); err != nil {
 		return nil, err
 	}
 
@@ -389,14 +329,14 @@ func (s *GlobalService) EventIdsByOrgId(orgId int) ([]int, error) {
 	return eventIds, nil
 }
 
-func (s *GlobalService) EventIdsByOrgName(orgName string) ([]int, error) {
+func (s *GlobalService) EventIdsByOrgName(ctx context.Context, orgName string) ([]int, error) {
 	var (
 		err      error
 		clubs    []models.Club
 		eventIds []int
 	)
 
-	if clubs, err = s.ClubsByOrgName(orgName); err != nil {
+	if clubs, err = s.ClubsByOrgName(ctx, orgName); err != nil {
 		return nil, err
 	}
 
@@ -415,70 +355,34 @@ func (s *GlobalService) EventIdsByOrgName(orgName string) ([]int, error) {
 	return eventIds, nil
 }
 
-var eventCache map[int]*models.Event
-
-func (s *GlobalService) EventById(eventId int) (*models.Event, error) {
+func (s *GlobalService) EventById(ctx context.Context, eventId int) (*models.Event, error) {
 	var (
-		err       error
-		data      []byte
-		targetUrl string
-		pResponse *http.Response
-		output    struct {
-			Result string       `json:"result"`
-			Event  models.Event `json:"data"`
-		}
+		err   error
+		event models.Event
 	)
 
-	if eventCache != nil {
-		if event, ok := eventCache[eventId]; ok {
-			return event, nil
-		}
+	key := fmt.Sprintf(eventKeyFormat, eventId)
+	if event, ok := cache.Get[*models.Event](s.Cache, key); ok {
+		return event, nil
 	}
 
-	suffix := fmt.Sprintf("/get-org-event-by-eventID/%d", eventId)
-	if targetUrl, err = url.JoinPath(s.Url(), "/api/Event", suffix); err != nil {
+	path := fmt.Sprintf("/api/Event/get-org-event-by-eventID/%d", eventId)
+	if event, err = getEnvelope[models.Event](ctx, s, path); err != nil {
 		return nil, err
 	}
 
-	if pResponse, err = s.Client().Get(targetUrl); err != nil {
-		return nil, fmt.Errorf("error getting event %d: %v", eventId, err)
-	}
-
-	defer func(Body io.ReadCloser) {
-		err := Body.Close()
-		if err != nil {
-			fmt.Println(err.Error())
-		}
-	}(pResponse.Body)
-
-	if pResponse.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("error getting event %d: invalid status code %d", eventId, pResponse.StatusCode)
-	}
-
-	if data, err = io.ReadAll(pResponse.Body); err != nil {
-		return nil, fmt.Errorf("error reading response body: %v", err)
-	}
-
-	if err = json.Unmarshal(data, &output); err != nil {
-		return nil, fmt.Errorf("error unmarshalling response body: %v\n%svc", err, string(data))
-	}
-
-	if eventCache == nil {
-		eventCache = make(map[int]*models.Event)
-	}
+	cache.Set(s.Cache, key, &event)
 
-	eventCache[eventId] = &output.Event
-
-	return &output.Event, nil
+	return &event, nil
 }
 
-func (s *GlobalService) EventByName(eventName string) (*models.Event, error) {
+func (s *GlobalService) EventByName(ctx context.Context, eventName string) (*models.Event, error) {
 	var (
 		err    error
 		events []models.Event
 	)
 
-	if events, err = s.Events(); err != nil {
+	if events, err = s.Events(ctx); err != nil {
 		return nil, err
 	}
 
@@ -488,22 +392,22 @@ func (s *GlobalService) EventByName(eventName string) (*models.Event, error) {
 		}
 	}
 
-	return nil, fmt.Errorf("event name %s not found", eventName)
+	return nil, fmt.Errorf("event name '%s': %w", eventName, pkg.ErrNotFound)
 }
 
-func (s *GlobalService) Events() ([]models.Event, error) {
+func (s *GlobalService) Events(ctx context.Context) ([]models.Event, error) {
 	var err error
 	var orgs []models.Organization
 	var events []models.Event
 
-	if orgs, err = s.Organizations(false); err != nil {
+	if orgs, err = s.Organizations(ctx, false); err != nil {
 		return nil, err
 	}
 
 	for _, org := range orgs {
 		var orgEvents []models.Event
 
-		if orgEvents, err = s.EventsByOrgId(org.Id); err != nil {
+		if orgEvents, err = s.EventsByOrgId(ctx, org.Id); err != nil {
 			return nil, err
 		}
 
@@ -517,32 +421,28 @@ func (s *GlobalService) Events() ([]models.Event, error) {
 	return events, nil
 }
 
-func (s *GlobalService) EventsByOrganization(org models.Organization) ([]models.Event, error) {
-	return s.EventsByOrgId(org.Id)
+func (s *GlobalService) EventsByOrganization(ctx context.Context, org models.Organization) ([]models.Event, error) {
+	return s.EventsByOrgId(ctx, org.Id)
 }
 
-var orgEventsCache map[int][]models.Event
-
-func (s *GlobalService) EventsByOrgId(orgId int) ([]models.Event, error) {
+func (s *GlobalService) EventsByOrgId(ctx context.Context, orgId int) ([]models.Event, error) {
 	var err error
 	var eventIds []int
 	var events []models.Event
 
-	if orgEventsCache != nil {
-		val, ok := orgEventsCache[orgId]
-		if ok {
-			return val, nil
-		}
+	key := fmt.Sprintf(eventsByOrgKeyFormat, orgId)
+	if events, ok := cache.Get[[]models.Event](s.Cache, key); ok {
+		return events, nil
 	}
 
-	if eventIds, err = s.EventIdsByOrgId(orgId); err != nil {
+	if eventIds, err = s.EventIdsByOrgId(ctx, orgId); err != nil {
 		return nil, err
 	}
 
 	for _, eventId := range eventIds {
 		var pEvent *models.Event
 
-		if pEvent, err = s.EventById(eventId); err != nil {
+		if pEvent, err = s.EventById(ctx, eventId); err != nil {
 			return nil, err
 		}
 
@@ -557,28 +457,24 @@ func (s *GlobalService) EventsByOrgId(orgId int) ([]models.Event, error) {
 		return events[i].Name < events[j].Name
 	})
 
-	if orgEventsCache == nil {
-		orgEventsCache = make(map[int][]models.Event)
-	}
-
-	orgEventsCache[orgId] = events
+	cache.Set(s.Cache, key, events)
 
 	return events, nil
 }
 
-func (s *GlobalService) EventsByOrgName(orgName string) ([]models.Event, error) {
+func (s *GlobalService) EventsByOrgName(ctx context.Context, orgName string) ([]models.Event, error) {
 	var err error
 	var eventIds []int
 	var events []models.Event
 
-	if eventIds, err = s.EventIdsByOrgName(orgName); err != nil {
+	if eventIds, err = s.EventIdsByOrgName(ctx, orgName); err != nil {
 		return nil, err
 	}
 
 	for _, eventId := range eventIds {
 		var pEvent *models.Event
 
-		if pEvent, err = s.EventById(eventId); err != nil {
+		if pEvent, err = s.EventById(ctx, eventId); err != nil {
 			return nil, err
 		}
 
@@ -596,47 +492,17 @@ func (s *GlobalService) EventsByOrgName(orgName string) ([]models.Event, error)
 	return events, nil
 }
 
-func (s *GlobalService) EventTypes() ([]models.EventType, error) {
-	var (
-		err       error
-		targetUrl string
-		pResponse *http.Response
-		output    struct {
-			Result     string             `json:"result"`
-			EventTypes []models.EventType `json:"data"`
-		}
-	)
-
-	if targetUrl, err = url.JoinPath(s.Url(), "/api/Mobile", "get-event-types"); err != nil {
-		return nil, err
-	}
-
-	fmt.Printf("GET %svc\n", targetUrl)
-
-	if pResponse, err = s.HttpClient.Get(targetUrl); err != nil {
-		return nil, err
-	}
-	defer func(Body io.ReadCloser) {
-		err := Body.Close()
-		if err != nil {
-			fmt.Println(err.Error())
-		}
-	}(pResponse.Body)
-
-	if err = json.NewDecoder(pResponse.Body).Decode(&output); err != nil {
-		return nil, err
-	}
-
-	return output.EventTypes, nil
+func (s *GlobalService) EventTypes(ctx context.Context) ([]models.EventType, error) {
+	return getEnvelope[[]models.EventType](ctx, s, "/api/Mobile/get-event-types")
 }
 
-func (s *GlobalService) EventTypeByName(name string) (*models.EventType, error) {
+func (s *GlobalService) EventTypeByName(ctx context.Context, name string) (*models.EventType, error) {
 	var (
 		err        error
 		eventTypes []models.EventType
 	)
 
-	if eventTypes, err = s.EventTypes(); err != nil {
+	if eventTypes, err = s.EventTypes(ctx); err != nil {
 		return nil, err
 	}
 
@@ -646,16 +512,16 @@ func (s *GlobalService) EventTypeByName(name string) (*models.EventType, error)
 		}
 	}
 
-	return nil, fmt.Errorf("event type name %svc not found", name)
+	return nil, fmt.Errorf("event type name '%s': %w", name, pkg.ErrNotFound)
 }
 
-func (s *GlobalService) EventTypeById(eventTypeId int) (*models.EventType, error) {
+func (s *GlobalService) EventTypeById(ctx context.Context, eventTypeId int) (*models.EventType, error) {
 	var (
 		err        error
 		eventTypes []models.EventType
 	)
 
-	if eventTypes, err = s.EventTypes(); err != nil {
+	if eventTypes, err = s.EventTypes(ctx); err != nil {
 		return nil, err
 	}
 
@@ -665,7 +531,7 @@ func (s *GlobalService) EventTypeById(eventTypeId int) (*models.EventType, error
 		}
 	}
 
-	return nil, fmt.Errorf("event type eventTypeId %d not found", eventTypeId)
+	return nil, fmt.Errorf("event type id %d: %w", eventTypeId, pkg.ErrNotFound)
 }
 
 /*
@@ -673,134 +539,75 @@ func (s *GlobalService) EventTypeById(eventTypeId int) (*models.EventType, error
 /api/Event/get-org-events-team-List-by-id/{orgID}/{orgSeasonGroupID}/{eventID}/{divisionID}
 */
 
-func (s *GlobalService) OrganizationDivisionsByOrgId(orgId, eventId int) ([]models.OrganiationDivision, error) {
+func (s *GlobalService) OrganizationDivisionsByOrgId(ctx context.Context, orgId, eventId int) ([]models.OrganiationDivision, error) {
 	var (
-		err       error
-		data      []byte
-		targetUrl string
-		pResponse *http.Response
-		output    struct {
-			Result string `json:"result"`
-			Data   struct {
-				OrgId        int                          `json:"orgID"`
-				OrgSeasonId  int                          `json:"orgSeasonID"`
-				DivisionList []models.OrganiationDivision `json:"divisionList"`
-			} `json:"data"`
-		}
+		err    error
+		output organizationDivisionList
 	)
 
-	suffix := fmt.Sprintf("/get-org-division-list/%d/%d", orgId, eventId)
-	if targetUrl, err = url.JoinPath(s.Url(), "/api/Event", suffix); err != nil {
+	path := fmt.Sprintf("/api/Event/get-org-division-list/%d/%d", orgId, eventId)
+	if output, err = getEnvelope[organizationDivisionList](ctx, s, path); err != nil {
 		return nil, err
 	}
 
-	fmt.Printf("GET %svc\n", targetUrl)
-
-	if pResponse, err = s.Client().Get(targetUrl); err != nil {
-		return nil, fmt.Errorf("error getting organization divisoins for orgId %d eventId %d: %v", orgId, eventId, err)
-	}
-
-	defer func(Body io.ReadCloser) {
-		err := Body.Close()
-		if err != nil {
-			fmt.Println(err.Error())
-		}
-	}(pResponse.Body)
-
-	if pResponse.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("error getting organization divisoins for orgId %d eventId %d: invalid status code %d", orgId, eventId, pResponse.StatusCode)
-	}
-
-	if data, err = io.ReadAll(pResponse.Body); err != nil {
-		return nil, fmt.Errorf("error reading response body: %v", err)
-	}
-
-	if err = json.Unmarshal(data, &output); err != nil {
-		return nil, fmt.Errorf("error unmarshalling response body: %v\n%svc", err, string(data))
-	}
-
-	return output.Data.DivisionList, nil
+	return output.DivisionList, nil
 }
 
 // MatchResults returns the match results by club name and event name. (e.g. "Concorde Fire Premier" and "ECNL Girls")
 // Keep in mind the results are across all age groups so they still need to be filtered.
-func (s *GlobalService) MatchEventsByClubNameAndEventName(clubName string, eventName string) ([]models.MatchEvent, error) {
+func (s *GlobalService) MatchEventsByClubNameAndEventName(ctx context.Context, clubName string, eventName string) ([]models.MatchEvent, error) {
 	var (
-		err       error
-		data      []byte
-		targetUrl string
-		pResponse *http.Response
-		event     *models.Event
-		club      *models.Club
-		output    struct {
-			Result string `json:"result"`
-			Data   struct {
-				ReportedScore   int                 `json:"reportedScore"`
-				UnReportedScore int                 `json:"unReportedScore"`
-				MatchEvents     []models.MatchEvent `json:"eventPastScheduleList"`
-			} `json:"data"`
-		}
+		err   error
+		event *models.Event
+		club  *models.Club
 	)
 
-	if club, err = s.ClubByName(clubName); err != nil {
+	if club, err = s.ClubByName(ctx, clubName); err != nil {
 		return nil, err
 	}
 
-	if event, err = s.EventByName(eventName); err != nil {
+	if event, err = s.EventByName(ctx, eventName); err != nil {
 		return nil, err
 	}
 
-	suffix := fmt.Sprintf("/get-score-reporting-schedule-list/%d/%d", club.ClubId, event.Id)
-	if targetUrl, err = url.JoinPath(s.Url(), "/api/Club", suffix); err != nil {
-		return nil, err
-	}
-
-	// fmt.Printf("GET %s\n", targetUrl)
-
-	if pResponse, err = s.Client().Get(targetUrl); err != nil {
-		return nil, fmt.Errorf("error getting schedule for %s club %s: %v", eventName, clubName, err)
-	}
-
-	defer func(Body io.ReadCloser) {
-		err := Body.Close()
-		if err != nil {
-			fmt.Println(err.Error())
-		}
-	}(pResponse.Body)
-
-	if pResponse.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("error getting schedule for %s club %s: invalid status code %d", eventName, clubName, pResponse.StatusCode)
-	}
+	return s.MatchEventsByClubIdAndEventId(ctx, club.ClubId, event.Id)
+}
 
-	if data, err = io.ReadAll(pResponse.Body); err != nil {
-		return nil, fmt.Errorf("error reading response body: %v", err)
-	}
+// MatchEventsByClubIdAndEventId returns the match results by club id and event id.
+// Like MatchEventsByClubNameAndEventName the results span all age groups.
+func (s *GlobalService) MatchEventsByClubIdAndEventId(ctx context.Context, clubId, eventId int) ([]models.MatchEvent, error) {
+	var (
+		err    error
+		output scheduleList
+	)
 
-	if err = json.Unmarshal(data, &output); err != nil {
-		return nil, fmt.Errorf("error unmarshalling response body: %v\n%svc", err, string(data))
+	path := fmt.Sprintf("/api/Club/get-score-reporting-schedule-list/%d/%d", clubId, eventId)
+	if output, err = getEnvelope[scheduleList](ctx, s, path); err != nil {
+		return nil, err
 	}
 
-	return output.Data.MatchEvents, nil
+	return output.MatchEvents, nil
 }
 
 // ClubsByEvent returns all of the clubs for the given event.
-func (s *GlobalService) ClubsByEvent(event models.Event) ([]models.Club, error) {
+func (s *GlobalService) ClubsByEvent(ctx context.Context, event models.Event) ([]models.Club, error) {
 	var (
-		err   error
-		clubs []models.Club
-		orgs  []models.Organization
+		err      error
+		clubs    []models.Club
+		orgClubs []models.Club
+		orgs     []models.Organization
 	)
 
-	if orgs, err = s.Organizations(false); err != nil {
+	if orgs, err = s.Organizations(ctx, false); err != nil {
 		return nil, err
 	}
 
 	for _, org := range orgs {
-		if clubs, err = s.ClubsByOrganization(org); err != nil {
+		if orgClubs, err = s.ClubsByOrganization(ctx, org); err != nil {
 			return nil, err
 		}
 
-		for _, club := range clubs {
+		for _, club := range orgClubs {
 			if club.EventId != event.Id {
 				continue
 			}
@@ -812,18 +619,8 @@ func (s *GlobalService) ClubsByEvent(event models.Event) ([]models.Club, error)
 	return clubs, nil
 }
 
-func (s *GlobalService) DivisionsByEvent(event models.Event) ([]models.Division, error) {
-	var (
-		err       error
-		data      []byte
-		gender    string
-		targetUrl string
-		pResponse *http.Response
-		output    struct {
-			Result    string            `json:"result"`
-			Divisions []models.Division `json:"data"`
-		}
-	)
+func (s *GlobalService) DivisionsByEvent(ctx context.Context, event models.Event) ([]models.Division, error) {
+	var gender string
 
 	if strings.Contains(event.Name, "Boys") {
 		gender = "M"
@@ -836,67 +633,41 @@ func (s *GlobalService) DivisionsByEvent(event models.Event) ([]models.Division,
 	}
 
 	// /api/Club/get-event-divisions-by-event-and-gender/{eventID}/{gender}
-	suffix := fmt.Sprintf("/get-event-divisions-by-event-and-gender/%d/%s", event.Id, gender)
-	if targetUrl, err = url.JoinPath(s.Url(), "/api/Club", suffix); err != nil {
-		return nil, err
-	}
-
-	if pResponse, err = s.Client().Get(targetUrl); err != nil {
-		return nil, fmt.Errorf("error getting divisions for event %s: %v", event.Name, err)
-	}
-
-	defer func(Body io.ReadCloser) {
-		err := Body.Close()
-		if err != nil {
-			fmt.Println(err.Error())
-		}
-	}(pResponse.Body)
+	path := fmt.Sprintf("/api/Club/get-event-divisions-by-event-and-gender/%d/%s", event.Id, gender)
 
-	if pResponse.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("error getting divisions for event %s: invalid status code %d", event.Name, pResponse.StatusCode)
-	}
-
-	if data, err = io.ReadAll(pResponse.Body); err != nil {
-		return nil, fmt.Errorf("error reading response body: %v", err)
-	}
-
-	if err = json.Unmarshal(data, &output); err != nil {
-		return nil, fmt.Errorf("error unmarshalling response body: %v\n%svc", err, string(data))
-	}
-
-	return output.Divisions, nil
+	return getEnvelope[[]models.Division](ctx, s, path)
 }
 
-func (s *GlobalService) DivisionsByEventId(id int) ([]models.Division, error) {
+func (s *GlobalService) DivisionsByEventId(ctx context.Context, id int) ([]models.Division, error) {
 	var (
 		err       error
 		event     *models.Event
 		divisions []models.Division
 	)
 
-	if event, err = s.EventById(id); err != nil {
+	if event, err = s.EventById(ctx, id); err != nil {
 		return nil, err
 	}
 
-	if divisions, err = s.DivisionsByEvent(*event); err != nil {
+	if divisions, err = s.DivisionsByEvent(ctx, *event); err != nil {
 		return nil, err
 	}
 
 	return divisions, nil
 }
 
-func (s *GlobalService) DivisionsByEventName(name string) ([]models.Division, error) {
+func (s *GlobalService) DivisionsByEventName(ctx context.Context, name string) ([]models.Division, error) {
 	var (
 		err       error
 		event     *models.Event
 		divisions []models.Division
 	)
 
-	if event, err = s.EventByName(name); err != nil {
+	if event, err = s.EventByName(ctx, name); err != nil {
 		return nil, err
 	}
 
-	if divisions, err = s.DivisionsByEvent(*event); err != nil {
+	if divisions, err = s.DivisionsByEvent(ctx, *event); err != nil {
 		return nil, err
 	}
 
@@ -907,14 +678,14 @@ func (s *GlobalService) DivisionsByEventName(name string) ([]models.Division, er
 
 // MatchResultsBayAgeGroup returns all of the match results filtered by age group.
 // Note: ageGroup takes the form "G2009" for example. (it maps to the Division property)
-func (s *GlobalService) MatchEventsByAgeGroup(clubName, eventName, ageGroup string) ([]models.MatchEvent, error) {
+func (s *GlobalService) MatchEventsByAgeGroup(ctx context.Context, clubName, eventName, ageGroup string) ([]models.MatchEvent, error) {
 	var (
 		err                  error
 		matchResults         []models.MatchEvent
 		filteredMatchResults []models.MatchEvent
 	)
 
-	if matchResults, err = s.MatchEventsByClubNameAndEventName(clubName, eventName); err != nil {
+	if matchResults, err = s.MatchEventsByClubNameAndEventName(ctx, clubName, eventName); err != nil {
 		return nil, err
 	}
 
@@ -936,7 +707,7 @@ func MatchEventsByClub(club models.Club) []models.MatchEvent {
 // RPISchedule returns all of the match results for the given organization name, event name, and age group.
 // Example: MatchResults("ECNL Girls", "G2009
 // Warning: This is going to make a lot of API calls!
-func (s *GlobalService) RPISchedule(orgName, ageGrouop string) (*schedule.Schedule, []string, error) {
+func (s *GlobalService) RPISchedule(ctx context.Context, orgName, ageGrouop string) (*schedule.Schedule, []string, error) {
 	var (
 		err         error
 		events      []models.Event
@@ -945,11 +716,11 @@ func (s *GlobalService) RPISchedule(orgName, ageGrouop string) (*schedule.Schedu
 		teamNames   []string
 	)
 
-	if events, err = s.EventsByOrgName(orgName); err != nil {
+	if events, err = s.EventsByOrgName(ctx, orgName); err != nil {
 		return nil, nil, err
 	}
 
-	if clubs, err = s.ClubsByOrgName(orgName); err != nil {
+	if clubs, err = s.ClubsByOrgName(ctx, orgName); err != nil {
 		return nil, nil, err
 	}
 
@@ -965,7 +736,7 @@ func (s *GlobalService) RPISchedule(orgName, ageGrouop string) (*schedule.Schedu
 
 			var matchResults []models.MatchEvent
 
-			if matchResults, err = s.MatchEventsByAgeGroup(club.Name, event.Name, ageGrouop); err != nil {
+			if matchResults, err = s.MatchEventsByAgeGroup(ctx, club.Name, event.Name, ageGrouop); err != nil {
 				return nil, nil, err
 			}
 
@@ -1008,7 +779,7 @@ func (s *GlobalService) RPISchedule(orgName, ageGrouop string) (*schedule.Schedu
 }
 
 // TeamsByEventId returns all of the teams for the given event.
-func (s *GlobalService) TeamsByEventId(eventId int) ([]*models.Team, error) {
+func (s *GlobalService) TeamsByEventId(ctx context.Context, eventId int) ([]*models.Team, error) {
 	var (
 		err           error
 		divisions     []models.Division
@@ -1016,12 +787,12 @@ func (s *GlobalService) TeamsByEventId(eventId int) ([]*models.Team, error) {
 		divisionTeams []*models.Team
 	)
 
-	if divisions, err = s.DivisionsByEventId(eventId); err != nil {
+	if divisions, err = s.DivisionsByEventId(ctx, eventId); err != nil {
 		return nil, err
 	}
 
 	for _, division := range divisions {
-		if divisionTeams, err = s.TeamsByEventIdAndDivisionId(eventId, division.Id); err != nil {
+		if divisionTeams, err = s.TeamsByEventIdAndDivisionId(ctx, eventId, division.Id); err != nil {
 			return nil, err
 		}
 
@@ -1032,7 +803,7 @@ func (s *GlobalService) TeamsByEventId(eventId int) ([]*models.Team, error) {
 }
 
 // TeamsByEvent returns all of the teams for the given event.
-func (s *GlobalService) TeamsByEvent(event models.Event) ([]*models.Team, error) {
+func (s *GlobalService) TeamsByEvent(ctx context.Context, event models.Event) ([]*models.Team, error) {
 	var (
 		err           error
 		divisions     []models.Division
@@ -1040,12 +811,12 @@ func (s *GlobalService) TeamsByEvent(event models.Event) ([]*models.Team, error)
 		divisionTeams []*models.Team
 	)
 
-	if divisions, err = s.DivisionsByEvent(event); err != nil {
+	if divisions, err = s.DivisionsByEvent(ctx, event); err != nil {
 		return nil, err
 	}
 
 	for _, division := range divisions {
-		if divisionTeams, err = s.TeamsByEventIdAndDivisionId(event.Id, division.Id); err != nil {
+		if divisionTeams, err = s.TeamsByEventIdAndDivisionId(ctx, event.Id, division.Id); err != nil {
 			return nil, err
 		}
 
@@ -1060,7 +831,7 @@ func (s *GlobalService) TeamsByEvent(event models.Event) ([]*models.Team, error)
 }
 
 // TeamsByEventName returns all of the teams for the given event name.
-func (s *GlobalService) TeamsByEventName(eventName string) ([]*models.Team, error) {
+func (s *GlobalService) TeamsByEventName(ctx context.Context, eventName string) ([]*models.Team, error) {
 	var (
 		err           error
 		divisions     []models.Division
@@ -1069,16 +840,16 @@ func (s *GlobalService) TeamsByEventName(eventName string) ([]*models.Team, erro
 		event         *models.Event
 	)
 
-	if event, err = s.EventByName(eventName); err != nil {
+	if event, err = s.EventByName(ctx, eventName); err != nil {
 		return nil, err
 	}
 
-	if divisions, err = s.DivisionsByEventName(eventName); err != nil {
+	if divisions, err = s.DivisionsByEventName(ctx, eventName); err != nil {
 		return nil, err
 	}
 
 	for _, division := range divisions {
-		if divisionTeams, err = s.TeamsByEventIdAndDivisionId(event.Id, division.Id); err != nil {
+		if divisionTeams, err = s.TeamsByEventIdAndDivisionId(ctx, event.Id, division.Id); err != nil {
 			return nil, err
 		}
 
@@ -1116,58 +887,27 @@ func (s *GlobalService) TeamsByEventName(eventName string) ([]*models.Team, erro
 */
 
 // TeamsByEventIdAndDivisionId returns all of the teams for the given event and division.
-func (s *GlobalService) TeamsByEventIdAndDivisionId(eventId, divisionId int) ([]*models.Team, error) {
+func (s *GlobalService) TeamsByEventIdAndDivisionId(ctx context.Context, eventId, divisionId int) ([]*models.Team, error) {
 	var (
-		err       error
-		data      []byte
-		targetUrl string
-		pResponse *http.Response
-		output    struct {
-			Result string `json:"result"`
-			Data   struct {
-				TeamList []models.Team `json:"teamList"`
-			} `json:"data"`
-		}
+		err    error
+		output teamList
 	)
 
-	suffix := fmt.Sprintf("/get-event-division-teams/%d/%d", eventId, divisionId)
-	if targetUrl, err = url.JoinPath(s.Url(), "/api/Event", suffix); err != nil {
+	path := fmt.Sprintf("/api/Event/get-event-division-teams/%d/%d", eventId, divisionId)
+	if output, err = getEnvelope[teamList](ctx, s, path); err != nil {
 		return nil, err
 	}
 
-	if pResponse, err = s.Client().Get(targetUrl); err != nil {
-		return nil, fmt.Errorf("error getting teams for event %d division %d: %v", eventId, divisionId, err)
-	}
-
-	defer func(Body io.ReadCloser) {
-		err := Body.Close()
-		if err != nil {
-			fmt.Println(err.Error())
-		}
-	}(pResponse.Body)
-
-	if pResponse.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("error getting teams for event %d division %d: invalid status code %d", eventId, divisionId, pResponse.StatusCode)
-	}
-
-	if data, err = io.ReadAll(pResponse.Body); err != nil {
-		return nil, fmt.Errorf("error reading response body: %v", err)
-	}
-
-	if err = json.Unmarshal(data, &output); err != nil {
-		return nil, fmt.Errorf("error unmarshalling response body: %v\n%svc", err, string(data))
-	}
-
 	var teams []*models.Team
 
-	for _, team := range output.Data.TeamList {
-		teams = append(teams, &team)
+	for i := range output.TeamList {
+		teams = append(teams, &output.TeamList[i])
 	}
 
 	return teams, nil
 }
 
 // TeamsByEventAndDivision returns all of the teams for the given event and division.
-func (s *GlobalService) TeamsByEventAndDivision(event models.Event, division models.Division) ([]*models.Team, error) {
-	return s.TeamsByEventIdAndDivisionId(event.Id, division.Id)
+func (s *GlobalService) TeamsByEventAndDivision(ctx context.Context, event models.Event, division models.Division) ([]*models.Team, error) {
+	return s.TeamsByEventIdAndDivisionId(ctx, event.Id, division.Id)
 }
@@ -1,12 +1,383 @@
 package services_test
 
 import (
+	"context"
+	"errors"
+	"github.com/jedi-knights/ecnl/pkg"
+	"github.com/jedi-knights/ecnl/pkg/models"
+	"github.com/jedi-knights/ecnl/pkg/services"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	"net/http"
 )
 
+const cassettePath = "testdata/tgs.cassette"
+
+const eventName = "ECNL Girls Southeast 2023-24"
+
 var _ = Describe("Total Global Sports", func() {
-	It("blue sky sunny day", func() {
-		Expect(true).To(BeTrue())
+	var (
+		ctx context.Context
+		svc *services.GlobalService
+	)
+
+	BeforeEach(func() {
+		replayer, err := services.NewReplayer(cassettePath)
+		Expect(err).NotTo(HaveOccurred())
+
+		ctx = context.Background()
+		svc = services.NewTGSServiceWithClient(&http.Client{Transport: replayer})
+	})
+
+	Describe("States", func() {
+		It("should return all states", func() {
+			// Act
+			states, err := svc.States(ctx)
+
+			// Assert
+			Expect(err).NotTo(HaveOccurred())
+			Expect(states).To(HaveLen(2))
+			Expect(states[0].Name).To(Equal("Alabama"))
+		})
+	})
+
+	Describe("Countries", func() {
+		It("should return all countries", func() {
+			// Act
+			countries, err := svc.Countries(ctx)
+
+			// Assert
+			Expect(err).NotTo(HaveOccurred())
+			Expect(countries).To(HaveLen(2))
+			Expect(countries[0].Name).To(Equal("United States"))
+		})
+	})
+
+	Describe("Organizations", func() {
+		It("should return all organizations", func() {
+			// Act
+			orgs, err := svc.Organizations(ctx, false)
+
+			// Assert
+			Expect(err).NotTo(HaveOccurred())
+			Expect(orgs).To(HaveLen(2))
+		})
+
+		It("should only return ECNL organizations when asked to", func() {
+			// Arrange
+			_, err := svc.Organizations(ctx, false)
+			Expect(err).NotTo(HaveOccurred())
+
+			// Act
+			orgs, err := svc.Organizations(ctx, true)
+
+			// Assert
+			Expect(err).NotTo(HaveOccurred())
+			Expect(orgs).To(HaveLen(1))
+			Expect(orgs[0].Name).To(Equal("ECNL Girls"))
+		})
+	})
+
+	Describe("OrganizationByName", func() {
+		It("should find the organization", func() {
+			// Act
+			org, err := svc.OrganizationByName(ctx, "ECNL Girls")
+
+			// Assert
+			Expect(err).NotTo(HaveOccurred())
+			Expect(org.Id).To(Equal(12))
+		})
+
+		It("should fail for an unknown organization", func() {
+			// Act
+			_, err := svc.OrganizationByName(ctx, "Unknown")
+
+			// Assert
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Describe("OrganizationById", func() {
+		It("should find the organization", func() {
+			// Act
+			org, err := svc.OrganizationById(ctx, 20)
+
+			// Assert
+			Expect(err).NotTo(HaveOccurred())
+			Expect(org.Name).To(Equal("Elite 64 Girls"))
+		})
+
+		It("should fail for an unknown organization", func() {
+			// Act
+			_, err := svc.OrganizationById(ctx, 99)
+
+			// Assert
+			Expect(err).To(MatchError(pkg.ErrNotFound))
+		})
+	})
+
+	Describe("Clubs", func() {
+		It("should return the clubs for an organization", func() {
+			// Act
+			clubs, err := svc.ClubsByOrganization(ctx, models.Organization{Id: 12})
+
+			// Assert
+			Expect(err).NotTo(HaveOccurred())
+			Expect(clubs).To(HaveLen(3))
+		})
+
+		It("should return the clubs for an organization id", func() {
+			// Act
+			clubs, err := svc.ClubsByOrganizationId(ctx, 20)
+
+			// Assert
+			Expect(err).NotTo(HaveOccurred())
+			Expect(clubs).To(BeEmpty())
+		})
+
+		It("should return the clubs for an organization name", func() {
+			// Act
+			clubs, err := svc.ClubsByOrgName(ctx, "ECNL Girls")
+
+			// Assert
+			Expect(err).NotTo(HaveOccurred())
+			Expect(clubs).To(HaveLen(3))
+		})
+
+		It("should find the organization for a club", func() {
+			// Act
+			org, err := svc.OrganizationForClubName(ctx, "Alabama FC")
+
+			// Assert
+			Expect(err).NotTo(HaveOccurred())
+			Expect(org.Id).To(Equal(12))
+		})
+
+		It("should find every club by name", func() {
+			// Act
+			first, err := svc.ClubByName(ctx, "Concorde Fire Premier")
+			Expect(err).NotTo(HaveOccurred())
+			second, err := svc.ClubByName(ctx, "Alabama FC")
+			Expect(err).NotTo(HaveOccurred())
+
+			// Assert
+			Expect(first.ClubId).To(Equal(100))
+			Expect(second.ClubId).To(Equal(18))
+		})
+
+		It("should fail for an unknown club", func() {
+			// Act
+			_, err := svc.ClubByName(ctx, "Unknown")
+
+			// Assert
+			Expect(err).To(MatchError(pkg.ErrNotFound))
+		})
+
+		It("should return the clubs taking part in an event", func() {
+			// Act
+			clubs, err := svc.ClubsByEvent(ctx, models.Event{Id: 2776})
+
+			// Assert
+			Expect(err).NotTo(HaveOccurred())
+			Expect(clubs).To(HaveLen(2))
+		})
+	})
+
+	Describe("Events", func() {
+		It("should return the distinct event ids for an organization id", func() {
+			// Act
+			ids, err := svc.EventIdsByOrgId(ctx, 12)
+
+			// Assert
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ids).To(ConsistOf(2776, 0))
+		})
+
+		It("should return the sorted event ids for an organization name", func() {
+			// Act
+			ids, err := svc.EventIdsByOrgName(ctx, "ECNL Girls")
+
+			// Assert
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ids).To(Equal([]int{0, 2776}))
+		})
+
+		It("should return an event by id", func() {
+			// Act
+			event, err := svc.EventById(ctx, 2776)
+
+			// Assert
+			Expect(err).NotTo(HaveOccurred())
+			Expect(event.Name).To(Equal(eventName))
+		})
+
+		It("should fail when the event is missing upstream", func() {
+			// Act
+			_, err := svc.EventById(ctx, 404)
+
+			// Assert
+			var statusErr *pkg.UpstreamStatusError
+			Expect(err).To(MatchError(pkg.ErrNotFound))
+			Expect(errors.As(err, &statusErr)).To(BeTrue())
+			Expect(statusErr.Code).To(Equal(http.StatusNotFound))
+		})
+
+		It("should return an event by name", func() {
+			// Act
+			event, err := svc.EventByName(ctx, eventName)
+
+			// Assert
+			Expect(err).NotTo(HaveOccurred())
+			Expect(event.Id).To(Equal(2776))
+		})
+
+		It("should return the named events across all organizations", func() {
+			// Act
+			events, err := svc.Events(ctx)
+
+			// Assert
+			Expect(err).NotTo(HaveOccurred())
+			Expect(events).To(HaveLen(1))
+		})
+
+		It("should return the events for an organization", func() {
+			// Act
+			byOrg, err := svc.EventsByOrganization(ctx, models.Organization{Id: 12})
+			Expect(err).NotTo(HaveOccurred())
+			byId, err := svc.EventsByOrgId(ctx, 12)
+			Expect(err).NotTo(HaveOccurred())
+			byName, err := svc.EventsByOrgName(ctx, "ECNL Girls")
+			Expect(err).NotTo(HaveOccurred())
+
+			// Assert
+			Expect(byOrg).To(HaveLen(1))
+			Expect(byId).To(Equal(byOrg))
+			Expect(byName).To(Equal(byOrg))
+		})
+	})
+
+	Describe("EventTypes", func() {
+		It("should return all event types", func() {
+			// Act
+			eventTypes, err := svc.EventTypes(ctx)
+
+			// Assert
+			Expect(err).NotTo(HaveOccurred())
+			Expect(eventTypes).To(HaveLen(2))
+		})
+
+		It("should return an event type by name", func() {
+			// Act
+			eventType, err := svc.EventTypeByName(ctx, "Tournament")
+
+			// Assert
+			Expect(err).NotTo(HaveOccurred())
+			Expect(eventType.Id).To(Equal(2))
+		})
+
+		It("should return an event type by id", func() {
+			// Act
+			eventType, err := svc.EventTypeById(ctx, 1)
+
+			// Assert
+			Expect(err).NotTo(HaveOccurred())
+			Expect(eventType.Name).To(Equal("League"))
+		})
+	})
+
+	Describe("Divisions", func() {
+		It("should return the divisions of an organization event", func() {
+			// Act
+			divisions, err := svc.OrganizationDivisionsByOrgId(ctx, 12, 2776)
+
+			// Assert
+			Expect(err).NotTo(HaveOccurred())
+			Expect(divisions).To(HaveLen(2))
+			Expect(divisions[0].Text).To(Equal("Girls 2009"))
+		})
+
+		It("should return the divisions of an event", func() {
+			// Act
+			byEvent, err := svc.DivisionsByEvent(ctx, models.Event{Id: 2776, Name: eventName})
+			Expect(err).NotTo(HaveOccurred())
+			byId, err := svc.DivisionsByEventId(ctx, 2776)
+			Expect(err).NotTo(HaveOccurred())
+			byName, err := svc.DivisionsByEventName(ctx, eventName)
+			Expect(err).NotTo(HaveOccurred())
+
+			// Assert
+			Expect(byEvent).To(HaveLen(2))
+			Expect(byId).To(Equal(byEvent))
+			Expect(byName).To(Equal(byEvent))
+		})
+	})
+
+	Describe("Matches", func() {
+		It("should return the match events for a club and event", func() {
+			// Act
+			matches, err := svc.MatchEventsByClubNameAndEventName(ctx, "Concorde Fire Premier", eventName)
+
+			// Assert
+			Expect(err).NotTo(HaveOccurred())
+			Expect(matches).To(HaveLen(3))
+		})
+
+		It("should filter the match events by age group", func() {
+			// Act
+			matches, err := svc.MatchEventsByAgeGroup(ctx, "Alabama FC", eventName, "G2010")
+
+			// Assert
+			Expect(err).NotTo(HaveOccurred())
+			Expect(matches).To(HaveLen(1))
+			Expect(matches[0].MatchId).To(Equal(9003))
+		})
+
+		It("should build an RPI schedule for an age group", func() {
+			// Act
+			_, teamNames, err := svc.RPISchedule(ctx, "ECNL Girls", "G2009")
+
+			// Assert
+			Expect(err).NotTo(HaveOccurred())
+			Expect(teamNames).To(Equal([]string{"Alabama FC ECNL G09", "Concorde Fire Premier ECNL G09"}))
+		})
+	})
+
+	Describe("Teams", func() {
+		It("should return distinct teams for an event and division", func() {
+			// Act
+			byIds, err := svc.TeamsByEventIdAndDivisionId(ctx, 2776, 5001)
+			Expect(err).NotTo(HaveOccurred())
+			byModels, err := svc.TeamsByEventAndDivision(ctx, models.Event{Id: 2776}, models.Division{Id: 5001})
+			Expect(err).NotTo(HaveOccurred())
+
+			// Assert
+			Expect(byIds).To(HaveLen(2))
+			Expect(byIds[0].Id).To(Equal(1001))
+			Expect(byIds[1].Id).To(Equal(1002))
+			Expect(byModels).To(Equal(byIds))
+		})
+
+		It("should return the teams of an event with their age group", func() {
+			// Act
+			teams, err := svc.TeamsByEvent(ctx, models.Event{Id: 2776, Name: eventName})
+
+			// Assert
+			Expect(err).NotTo(HaveOccurred())
+			Expect(teams).To(HaveLen(4))
+			Expect(teams[0].AgeGroup).To(Equal("G2009"))
+			Expect(teams[3].AgeGroup).To(Equal("G2010"))
+		})
+
+		It("should return the teams of an event by id and by name", func() {
+			// Act
+			byId, err := svc.TeamsByEventId(ctx, 2776)
+			Expect(err).NotTo(HaveOccurred())
+			byName, err := svc.TeamsByEventName(ctx, eventName)
+			Expect(err).NotTo(HaveOccurred())
+
+			// Assert
+			Expect(byId).To(HaveLen(4))
+			Expect(byName).To(HaveLen(4))
+		})
 	})
 })
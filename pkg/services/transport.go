@@ -0,0 +1,182 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"golang.org/x/time/rate"
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"time"
+)
+
+// TransportOptions configures the retry, backoff and rate limiting behavior of a Transport.
+type TransportOptions struct {
+	// Timeout bounds a single attempt. Zero means no per attempt timeout.
+	Timeout time.Duration
+	// MaxRetries is the number of times a failed request is retried.
+	MaxRetries int
+	// MinBackoff is the base delay used before the first retry.
+	MinBackoff time.Duration
+	// MaxBackoff caps the delay between retries.
+	MaxBackoff time.Duration
+	// RateLimit is the sustained number of requests per second. Zero disables rate limiting.
+	RateLimit float64
+	// Burst is the number of requests allowed to exceed the rate limit at once.
+	Burst int
+}
+
+// DefaultTransportOptions returns the options used when nothing has been configured.
+func DefaultTransportOptions() TransportOptions {
+	return TransportOptions{
+		Timeout:    30 * time.Second,
+		MaxRetries: 4,
+		MinBackoff: 500 * time.Millisecond,
+		MaxBackoff: 30 * time.Second,
+		RateLimit:  5,
+		Burst:      5,
+	}
+}
+
+// Transport is an http.RoundTripper that rate limits outgoing requests and retries
+// them with exponential backoff and jitter on timeouts and 5xx responses.
+type Transport struct {
+	base    http.RoundTripper
+	opts    TransportOptions
+	limiter *rate.Limiter
+}
+
+// NewTransport wraps the base round tripper. If base is nil http.DefaultTransport is used.
+func NewTransport(base http.RoundTripper, opts TransportOptions) *Transport {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	limiter := rate.NewLimiter(rate.Inf, 0)
+	if opts.RateLimit > 0 {
+		burst := opts.Burst
+		if burst < 1 {
+			burst = 1
+		}
+
+		limiter = rate.NewLimiter(rate.Limit(opts.RateLimit), burst)
+	}
+
+	return &Transport{base: base, opts: opts, limiter: limiter}
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var (
+		err error
+		res *http.Response
+	)
+
+	ctx := req.Context()
+
+	for attempt := 0; ; attempt++ {
+		if err = t.limiter.Wait(ctx); err != nil {
+			return nil, err
+		}
+
+		if res, err = t.attempt(req); !t.shouldRetry(req, res, err) || attempt >= t.opts.MaxRetries {
+			return res, err
+		}
+
+		if res != nil {
+			_, _ = io.Copy(io.Discard, res.Body)
+			_ = res.Body.Close()
+		}
+
+		timer := time.NewTimer(t.backoff(attempt))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+
+		if req.Body != nil {
+			var body io.ReadCloser
+
+			if body, err = req.GetBody(); err != nil {
+				return nil, err
+			}
+
+			req = req.Clone(ctx)
+			req.Body = body
+		}
+	}
+}
+
+// attempt performs a single round trip bounded by the per attempt timeout.
+func (t *Transport) attempt(req *http.Request) (*http.Response, error) {
+	if t.opts.Timeout <= 0 {
+		return t.base.RoundTrip(req)
+	}
+
+	ctx, cancel := context.WithTimeout(req.Context(), t.opts.Timeout)
+
+	res, err := t.base.RoundTrip(req.WithContext(ctx))
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	// The attempt context has to outlive RoundTrip so the caller can read the body.
+	res.Body = &cancelOnClose{ReadCloser: res.Body, cancel: cancel}
+
+	return res, nil
+}
+
+// shouldRetry reports whether the outcome of an attempt is worth retrying.
+func (t *Transport) shouldRetry(req *http.Request, res *http.Response, err error) bool {
+	if req.Context().Err() != nil {
+		return false
+	}
+
+	if req.Body != nil && req.GetBody == nil {
+		return false
+	}
+
+	if err != nil {
+		var netErr net.Error
+
+		if errors.Is(err, context.DeadlineExceeded) {
+			return true
+		}
+
+		return errors.As(err, &netErr) && netErr.Timeout()
+	}
+
+	return res.StatusCode >= http.StatusInternalServerError || res.StatusCode == http.StatusTooManyRequests
+}
+
+// backoff returns the delay before the given retry using exponential backoff with equal jitter.
+func (t *Transport) backoff(attempt int) time.Duration {
+	delay := t.opts.MinBackoff << attempt
+	if delay <= 0 || (t.opts.MaxBackoff > 0 && delay > t.opts.MaxBackoff) {
+		delay = t.opts.MaxBackoff
+	}
+
+	if delay <= 0 {
+		return 0
+	}
+
+	half := delay / 2
+
+	return half + time.Duration(rand.Int63n(int64(half)+1))
+}
+
+// cancelOnClose releases the attempt context once the response body is closed.
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (c *cancelOnClose) Close() error {
+	defer c.cancel()
+
+	return c.ReadCloser.Close()
+}
@@ -0,0 +1,106 @@
+package services_test
+
+import (
+	"context"
+	"github.com/jedi-knights/ecnl/pkg/services"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"time"
+)
+
+var _ = Describe("Transport", func() {
+	var (
+		calls  atomic.Int32
+		fail   int32
+		status int
+		server *httptest.Server
+		client *http.Client
+	)
+
+	BeforeEach(func() {
+		calls.Store(0)
+		fail = 0
+		status = http.StatusBadGateway
+
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if calls.Add(1) <= fail {
+				w.WriteHeader(status)
+				return
+			}
+
+			_, _ = w.Write([]byte(`{"result":"success"}`))
+		}))
+
+		opts := services.DefaultTransportOptions()
+		opts.MaxRetries = 3
+		opts.MinBackoff = time.Millisecond
+		opts.MaxBackoff = 5 * time.Millisecond
+		opts.RateLimit = 0
+
+		client = &http.Client{Transport: services.NewTransport(nil, opts)}
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("should retry 5xx responses until the request succeeds", func() {
+		// Arrange
+		fail = 2
+
+		// Act
+		res, err := client.Get(server.URL)
+
+		// Assert
+		Expect(err).NotTo(HaveOccurred())
+		Expect(res.StatusCode).To(Equal(http.StatusOK))
+		Expect(calls.Load()).To(Equal(int32(3)))
+		Expect(res.Body.Close()).To(Succeed())
+	})
+
+	It("should return the last response once the retries are exhausted", func() {
+		// Arrange
+		fail = 10
+
+		// Act
+		res, err := client.Get(server.URL)
+
+		// Assert
+		Expect(err).NotTo(HaveOccurred())
+		Expect(res.StatusCode).To(Equal(http.StatusBadGateway))
+		Expect(calls.Load()).To(Equal(int32(4)))
+		Expect(res.Body.Close()).To(Succeed())
+	})
+
+	It("should not retry client errors", func() {
+		// Arrange
+		fail = 10
+		status = http.StatusNotFound
+
+		// Act
+		res, err := client.Get(server.URL)
+
+		// Assert
+		Expect(err).NotTo(HaveOccurred())
+		Expect(res.StatusCode).To(Equal(http.StatusNotFound))
+		Expect(calls.Load()).To(Equal(int32(1)))
+		Expect(res.Body.Close()).To(Succeed())
+	})
+
+	It("should stop retrying when the context is cancelled", func() {
+		// Arrange
+		fail = 10
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		req, _ := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+
+		// Act
+		_, err := client.Do(req)
+
+		// Assert
+		Expect(err).To(MatchError(context.Canceled))
+	})
+})
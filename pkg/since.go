@@ -0,0 +1,42 @@
+package pkg
+
+import (
+	"fmt"
+	"time"
+)
+
+// ParseSince parses a point in time given as a duration before now (e.g. 72h), a date (e.g. 2023-10-01)
+// or an RFC3339 time. An empty value returns the zero time.
+func ParseSince(value string) (time.Time, error) {
+	var (
+		err      error
+		duration time.Duration
+		since    time.Time
+	)
+
+	if value == "" {
+		return time.Time{}, nil
+	}
+
+	if duration, err = time.ParseDuration(value); err == nil {
+		return time.Now().Add(-duration), nil
+	}
+
+	for _, layout := range []string{time.RFC3339, time.DateOnly} {
+		if since, err = time.ParseInLocation(layout, value, time.Local); err == nil {
+			return since, nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("invalid time '%s' expected a duration, a date or an RFC3339 time", value)
+}
+
+// ParseAsOf parses a point in time like ParseSince, except that a date stands for the whole day
+// so that as of 2023-10-01 includes what happened on Oct 1.
+func ParseAsOf(value string) (time.Time, error) {
+	if day, err := time.ParseInLocation(time.DateOnly, value, time.Local); err == nil {
+		return day.AddDate(0, 0, 1).Add(-time.Nanosecond), nil
+	}
+
+	return ParseSince(value)
+}
@@ -0,0 +1,68 @@
+package pkg_test
+
+import (
+	"github.com/jedi-knights/ecnl/pkg"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"time"
+)
+
+var _ = Describe("ParseSince", func() {
+	It("should return the zero time for an empty value", func() {
+		// Act
+		since, err := pkg.ParseSince("")
+
+		// Assert
+		Expect(err).NotTo(HaveOccurred())
+		Expect(since.IsZero()).To(BeTrue())
+	})
+
+	It("should parse a duration before now", func() {
+		// Act
+		since, err := pkg.ParseSince("24h")
+
+		// Assert
+		Expect(err).NotTo(HaveOccurred())
+		Expect(since).To(BeTemporally("~", time.Now().Add(-24*time.Hour), time.Second))
+	})
+
+	It("should parse a date and an RFC3339 time", func() {
+		// Act
+		date, err := pkg.ParseSince("2023-10-01")
+		Expect(err).NotTo(HaveOccurred())
+		moment, err := pkg.ParseSince("2023-10-01T12:00:00Z")
+		Expect(err).NotTo(HaveOccurred())
+
+		// Assert
+		Expect(date).To(Equal(time.Date(2023, 10, 1, 0, 0, 0, 0, time.Local)))
+		Expect(moment.UTC()).To(Equal(time.Date(2023, 10, 1, 12, 0, 0, 0, time.UTC)))
+	})
+
+	It("should reject anything else", func() {
+		// Act
+		_, err := pkg.ParseSince("yesterday")
+
+		// Assert
+		Expect(err).To(HaveOccurred())
+	})
+})
+
+var _ = Describe("ParseAsOf", func() {
+	It("should include the whole of a date", func() {
+		// Act
+		asOf, err := pkg.ParseAsOf("2023-10-01")
+
+		// Assert
+		Expect(err).NotTo(HaveOccurred())
+		Expect(asOf).To(Equal(time.Date(2023, 10, 1, 23, 59, 59, 999999999, time.Local)))
+	})
+
+	It("should parse anything else like ParseSince", func() {
+		// Act
+		moment, err := pkg.ParseAsOf("2023-10-01T12:00:00Z")
+
+		// Assert
+		Expect(err).NotTo(HaveOccurred())
+		Expect(moment.UTC()).To(Equal(time.Date(2023, 10, 1, 12, 0, 0, 0, time.UTC)))
+	})
+})
@@ -0,0 +1,347 @@
+package snapshot
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/jedi-knights/ecnl/pkg/dal"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"time"
+)
+
+// SchemaVersion is the version of the archive layout and of the documents in it.
+// It is raised whenever a change to the models changes what an archive holds.
+// Version 2 added the rpi_snapshots collection, the rpi_events became the rows of a snapshot.
+const SchemaVersion = 2
+
+// MinSchemaVersion is the oldest version that is still imported.
+// The rpi_events of a version 1 archive are not the rows of any RPI snapshot, they only show up in the history of their team.
+const MinSchemaVersion = 1
+
+// ManifestName is the name of the manifest in the archive, it is always the first entry.
+const ManifestName = "manifest.json"
+
+// batchSize is the number of documents imported at a time.
+const batchSize = 1000
+
+// Manifest describes a snapshot.
+type Manifest struct {
+	SchemaVersion int            `json:"schemaVersion"`
+	ExportedAt    time.Time      `json:"exportedAt"`
+	Counts        map[string]int `json:"counts"`
+}
+
+// Collection is a collection that can be dumped to and restored from NDJSON, one document per line.
+type Collection interface {
+	Name() string
+	Export(ctx context.Context, w io.Writer) (int, error)
+	Import(ctx context.Context, r io.Reader) (int, error)
+}
+
+type collection[T any] struct {
+	name    string
+	each    func(ctx context.Context, fn func(T) error) error
+	syncAll func(ctx context.Context, items []T) (dal.SyncSummary, error)
+}
+
+// NewCollection creates a collection that streams its documents with each and writes them with syncAll,
+// the Each and SyncAll methods of the DAOs in the dal package.
+// Restoring upserts the documents, so importing the same snapshot twice does not duplicate anything.
+func NewCollection[T any](name string, each func(ctx context.Context, fn func(T) error) error, syncAll func(ctx context.Context, items []T) (dal.SyncSummary, error)) Collection {
+	return &collection[T]{name: name, each: each, syncAll: syncAll}
+}
+
+func (c *collection[T]) Name() string {
+	return c.name
+}
+
+func (c *collection[T]) Export(ctx context.Context, w io.Writer) (int, error) {
+	var (
+		count    int
+		writeErr error
+	)
+
+	encoder := json.NewEncoder(w)
+
+	err := c.each(ctx, func(item T) error {
+		if writeErr = encoder.Encode(item); writeErr != nil {
+			return writeErr
+		}
+
+		count++
+
+		return nil
+	})
+
+	switch {
+	case writeErr != nil:
+		return 0, fmt.Errorf("error writing %s: %w", c.name, writeErr)
+	case err != nil:
+		return 0, fmt.Errorf("error reading %s: %w", c.name, err)
+	}
+
+	return count, nil
+}
+
+func (c *collection[T]) Import(ctx context.Context, r io.Reader) (int, error) {
+	var (
+		count int
+		batch []T
+	)
+
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+
+		if _, err := c.syncAll(ctx, batch); err != nil {
+			return fmt.Errorf("error restoring %s: %w", c.name, err)
+		}
+
+		count += len(batch)
+		batch = batch[:0]
+
+		return nil
+	}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+
+	for line := 1; scanner.Scan(); line++ {
+		var item T
+
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+
+		if err := json.Unmarshal(scanner.Bytes(), &item); err != nil {
+			return count, fmt.Errorf("error reading line %d of %s: %w", line, c.name, err)
+		}
+
+		if batch = append(batch, item); len(batch) == batchSize {
+			if err := flush(); err != nil {
+				return count, err
+			}
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return count, fmt.Errorf("error reading %s: %w", c.name, err)
+	}
+
+	return count, flush()
+}
+
+// Export writes a gzipped tar archive of the collections to w, a manifest followed by one NDJSON file per collection.
+func Export(ctx context.Context, w io.Writer, collections []Collection) (*Manifest, error) {
+	var (
+		err  error
+		data []byte
+	)
+
+	manifest := &Manifest{SchemaVersion: SchemaVersion, ExportedAt: time.Now().UTC(), Counts: make(map[string]int)}
+
+	// The size of a tar entry goes in its header and the manifest with the counts comes first,
+	// so every collection is spooled to a temporary file before anything is written.
+	files := make([]*os.File, 0, len(collections))
+	defer func() {
+		for _, file := range files {
+			_ = file.Close()
+			_ = os.Remove(file.Name())
+		}
+	}()
+
+	for _, col := range collections {
+		var file *os.File
+
+		if file, err = os.CreateTemp("", "ecnl-"+col.Name()+"-*.ndjson"); err != nil {
+			return nil, fmt.Errorf("error spooling %s: %w", col.Name(), err)
+		}
+
+		files = append(files, file)
+
+		if manifest.Counts[col.Name()], err = spool(ctx, col, file); err != nil {
+			return nil, err
+		}
+	}
+
+	if data, err = json.MarshalIndent(manifest, "", "  "); err != nil {
+		return nil, err
+	}
+
+	gz := gzip.NewWriter(w)
+	archive := tar.NewWriter(gz)
+
+	if err = writeFile(archive, ManifestName, bytes.NewReader(data), int64(len(data)), manifest.ExportedAt); err != nil {
+		return nil, err
+	}
+
+	for i, col := range collections {
+		if err = copyFile(archive, fileName(col.Name()), files[i], manifest.ExportedAt); err != nil {
+			return nil, err
+		}
+	}
+
+	if err = archive.Close(); err != nil {
+		return nil, err
+	}
+
+	if err = gz.Close(); err != nil {
+		return nil, err
+	}
+
+	return manifest, nil
+}
+
+// Import restores the collections from an archive written by Export.
+// It fails on an archive of a schema version it does not know, when a collection does not hold as many documents as the manifest says
+// and when a collection listed in the manifest is missing from the archive.
+// Files of collections that are not given are skipped.
+func Import(ctx context.Context, r io.Reader, collections []Collection) (*Manifest, error) {
+	var (
+		err      error
+		gz       *gzip.Reader
+		header   *tar.Header
+		manifest *Manifest
+	)
+
+	if gz, err = gzip.NewReader(r); err != nil {
+		return nil, fmt.Errorf("error reading the snapshot: %w", err)
+	}
+	defer gz.Close()
+
+	byName := make(map[string]Collection)
+	for _, col := range collections {
+		byName[fileName(col.Name())] = col
+	}
+
+	archive := tar.NewReader(gz)
+	seen := make(map[string]bool)
+
+	for {
+		if header, err = archive.Next(); errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return manifest, fmt.Errorf("error reading the snapshot: %w", err)
+		}
+
+		if manifest == nil {
+			if manifest, err = readManifest(header, archive); err != nil {
+				return nil, err
+			}
+
+			continue
+		}
+
+		seen[header.Name] = true
+
+		col, ok := byName[header.Name]
+		if !ok {
+			continue
+		}
+
+		count, err := col.Import(ctx, archive)
+		if err != nil {
+			return manifest, err
+		}
+
+		if expected := manifest.Counts[col.Name()]; count != expected {
+			return manifest, fmt.Errorf("the snapshot holds %d %s but the manifest lists %d", count, col.Name(), expected)
+		}
+	}
+
+	if manifest == nil {
+		return nil, errors.New("the snapshot is empty")
+	}
+
+	// A truncated archive ends early, without the collections after the last complete one.
+	var missing []string
+
+	for name := range manifest.Counts {
+		if !seen[fileName(name)] {
+			missing = append(missing, name)
+		}
+	}
+
+	if len(missing) > 0 {
+		slices.Sort(missing)
+
+		return manifest, fmt.Errorf("the snapshot is missing %s listed in its manifest", strings.Join(missing, ", "))
+	}
+
+	return manifest, nil
+}
+
+func readManifest(header *tar.Header, r io.Reader) (*Manifest, error) {
+	var manifest Manifest
+
+	if header.Name != ManifestName {
+		return nil, fmt.Errorf("the snapshot starts with '%s' instead of its manifest", header.Name)
+	}
+
+	if err := json.NewDecoder(r).Decode(&manifest); err != nil {
+		return nil, fmt.Errorf("error reading the manifest: %w", err)
+	}
+
+	if manifest.SchemaVersion < MinSchemaVersion || manifest.SchemaVersion > SchemaVersion {
+		return nil, fmt.Errorf("the snapshot has schema version %d, this version of ecnl imports versions %d to %d", manifest.SchemaVersion, MinSchemaVersion, SchemaVersion)
+	}
+
+	return &manifest, nil
+}
+
+// spool exports the collection to file.
+func spool(ctx context.Context, col Collection, file *os.File) (int, error) {
+	buffered := bufio.NewWriter(file)
+
+	count, err := col.Export(ctx, buffered)
+	if err != nil {
+		return 0, err
+	}
+
+	if err = buffered.Flush(); err != nil {
+		return 0, fmt.Errorf("error spooling %s: %w", col.Name(), err)
+	}
+
+	return count, nil
+}
+
+// copyFile writes a spooled file to the archive from its start.
+func copyFile(archive *tar.Writer, name string, file *os.File, modTime time.Time) error {
+	info, err := file.Stat()
+	if err != nil {
+		return fmt.Errorf("error reading the spooled %s: %w", name, err)
+	}
+
+	if _, err = file.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf("error reading the spooled %s: %w", name, err)
+	}
+
+	return writeFile(archive, name, file, info.Size(), modTime)
+}
+
+func writeFile(archive *tar.Writer, name string, r io.Reader, size int64, modTime time.Time) error {
+	header := &tar.Header{Name: name, Mode: 0o644, Size: size, ModTime: modTime}
+
+	if err := archive.WriteHeader(header); err != nil {
+		return fmt.Errorf("error writing %s to the snapshot: %w", name, err)
+	}
+
+	if _, err := io.Copy(archive, r); err != nil {
+		return fmt.Errorf("error writing %s to the snapshot: %w", name, err)
+	}
+
+	return nil
+}
+
+func fileName(collection string) string {
+	return strings.ToLower(collection) + ".ndjson"
+}
@@ -0,0 +1,189 @@
+package snapshot_test
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"github.com/jedi-knights/ecnl/pkg/dal"
+	"github.com/jedi-knights/ecnl/pkg/models"
+	"github.com/jedi-knights/ecnl/pkg/snapshot"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"time"
+)
+
+// table is an in memory collection keyed like the DAOs, syncing the same item twice replaces it.
+type table[T any] struct {
+	items map[int]T
+	key   func(T) int
+}
+
+func newTable[T any](key func(T) int, items ...T) *table[T] {
+	t := &table[T]{items: make(map[int]T), key: key}
+	_, _ = t.SyncAll(context.Background(), items)
+
+	return t
+}
+
+func (t *table[T]) Each(_ context.Context, fn func(T) error) error {
+	for _, item := range t.items {
+		if err := fn(item); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (t *table[T]) SyncAll(_ context.Context, items []T) (dal.SyncSummary, error) {
+	for _, item := range items {
+		t.items[t.key(item)] = item
+	}
+
+	return dal.SyncSummary{Inserted: len(items)}, nil
+}
+
+var _ = Describe("Snapshot", func() {
+	ctx := context.Background()
+
+	var (
+		removedAt time.Time
+		clubs     *table[models.Club]
+		teams     *table[models.Team]
+	)
+
+	clubKey := func(club models.Club) int { return club.ClubId }
+	teamKey := func(team models.Team) int { return team.Id }
+
+	collections := func(clubs *table[models.Club], teams *table[models.Team]) []snapshot.Collection {
+		return []snapshot.Collection{
+			snapshot.NewCollection[models.Club]("clubs", clubs.Each, clubs.SyncAll),
+			snapshot.NewCollection[models.Team]("teams", teams.Each, teams.SyncAll),
+		}
+	}
+
+	BeforeEach(func() {
+		removedAt = time.Date(2023, 10, 1, 0, 0, 0, 0, time.UTC)
+		clubs = newTable(clubKey, models.Club{ClubId: 18, Name: "Alabama FC"}, models.Club{ClubId: 100, Name: "Concorde Fire Premier", RemovedAt: &removedAt})
+		teams = newTable(teamKey, models.Team{Id: 1001, Name: "Alabama FC ECNL G09"})
+	})
+
+	It("should restore what it exported", func() {
+		// Arrange
+		var archive bytes.Buffer
+		exported, err := snapshot.Export(ctx, &archive, collections(clubs, teams))
+		Expect(err).NotTo(HaveOccurred())
+		restoredClubs, restoredTeams := newTable(clubKey), newTable(teamKey)
+
+		// Act
+		manifest, err := snapshot.Import(ctx, &archive, collections(restoredClubs, restoredTeams))
+
+		// Assert
+		Expect(err).NotTo(HaveOccurred())
+		Expect(manifest.SchemaVersion).To(Equal(snapshot.SchemaVersion))
+		Expect(manifest.Counts).To(Equal(map[string]int{"clubs": 2, "teams": 1}))
+		Expect(manifest.ExportedAt).To(BeTemporally("~", exported.ExportedAt, time.Second))
+		Expect(restoredClubs.items).To(Equal(clubs.items))
+		Expect(restoredTeams.items).To(Equal(teams.items))
+	})
+
+	It("should not duplicate anything when imported twice", func() {
+		// Arrange
+		var archive bytes.Buffer
+		_, err := snapshot.Export(ctx, &archive, collections(clubs, teams))
+		Expect(err).NotTo(HaveOccurred())
+		data := archive.Bytes()
+
+		// Act
+		_, err = snapshot.Import(ctx, bytes.NewReader(data), collections(clubs, teams))
+		Expect(err).NotTo(HaveOccurred())
+		_, err = snapshot.Import(ctx, bytes.NewReader(data), collections(clubs, teams))
+
+		// Assert
+		Expect(err).NotTo(HaveOccurred())
+		Expect(clubs.items).To(HaveLen(2))
+	})
+
+	It("should refuse a snapshot of another schema version", func() {
+		// Arrange
+		archive := tarball(map[string]string{snapshot.ManifestName: `{"schemaVersion": 99, "counts": {}}`})
+
+		// Act
+		_, err := snapshot.Import(ctx, archive, collections(clubs, teams))
+
+		// Assert
+		Expect(err).To(MatchError(ContainSubstring("schema version 99")))
+	})
+
+	It("should import a snapshot of schema version 1", func() {
+		// Arrange
+		archive := tarball(map[string]string{
+			snapshot.ManifestName: `{"schemaVersion": 1, "counts": {"clubs": 1}}`,
+			"clubs.ndjson":        `{"clubID": 7, "clubName": "Lonely FC"}` + "\n",
+		})
+		restoredClubs := newTable(clubKey)
+
+		// Act
+		manifest, err := snapshot.Import(ctx, archive, collections(restoredClubs, newTable(teamKey)))
+
+		// Assert
+		Expect(err).NotTo(HaveOccurred())
+		Expect(manifest.SchemaVersion).To(Equal(1))
+		Expect(restoredClubs.items).To(HaveKey(7))
+	})
+
+	It("should fail when a collection does not match the manifest", func() {
+		// Arrange
+		archive := tarball(map[string]string{
+			snapshot.ManifestName: `{"schemaVersion": 1, "counts": {"clubs": 2}}`,
+			"clubs.ndjson":        `{"clubID": 7, "clubName": "Lonely FC"}` + "\n",
+		})
+
+		// Act
+		_, err := snapshot.Import(ctx, archive, collections(clubs, teams))
+
+		// Assert
+		Expect(err).To(MatchError(ContainSubstring("holds 1 clubs but the manifest lists 2")))
+	})
+
+	It("should fail when a collection listed in the manifest is missing", func() {
+		// Arrange
+		archive := tarball(map[string]string{
+			snapshot.ManifestName: `{"schemaVersion": 2, "counts": {"clubs": 1, "teams": 1}}`,
+			"clubs.ndjson":        `{"clubID": 7, "clubName": "Lonely FC"}` + "\n",
+		})
+
+		// Act
+		_, err := snapshot.Import(ctx, archive, collections(clubs, teams))
+
+		// Assert
+		Expect(err).To(MatchError(ContainSubstring("missing teams")))
+	})
+})
+
+// tarball builds a snapshot by hand, the manifest first.
+func tarball(files map[string]string) *bytes.Buffer {
+	var buf bytes.Buffer
+
+	gz := gzip.NewWriter(&buf)
+	archive := tar.NewWriter(gz)
+
+	names := []string{snapshot.ManifestName}
+	for name := range files {
+		if name != snapshot.ManifestName {
+			names = append(names, name)
+		}
+	}
+
+	for _, name := range names {
+		Expect(archive.WriteHeader(&tar.Header{Name: name, Mode: 0o644, Size: int64(len(files[name]))})).To(Succeed())
+		_, err := archive.Write([]byte(files[name]))
+		Expect(err).NotTo(HaveOccurred())
+	}
+
+	Expect(archive.Close()).To(Succeed())
+	Expect(gz.Close()).To(Succeed())
+
+	return &buf
+}
@@ -0,0 +1,69 @@
+package stub
+
+import (
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// Prefixes are the TGS API areas served by the stub.
+var Prefixes = []string{"/api/Association/", "/api/Event/", "/api/Club/", "/api/Mobile/"}
+
+// Server serves TGS responses from a directory of fixture files.
+// A request for /api/Event/get-org-event-by-eventID/2776 is answered with the contents of
+// <dir>/api/Event/get-org-event-by-eventID/2776.json.
+type Server struct {
+	dir string
+}
+
+// NewServer creates a stub server that reads its fixtures from dir.
+func NewServer(dir string) *Server {
+	return &Server{dir: dir}
+}
+
+// ServeHTTP implements http.Handler.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var (
+		err  error
+		data []byte
+	)
+
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	requestPath := path.Clean(r.URL.Path)
+	if !served(requestPath) {
+		http.NotFound(w, r)
+		return
+	}
+
+	fixture := filepath.Join(s.dir, filepath.FromSlash(requestPath)+".json")
+
+	if data, err = os.ReadFile(fixture); err != nil {
+		if os.IsNotExist(err) {
+			http.NotFound(w, r)
+			return
+		}
+
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	_, _ = w.Write(data)
+}
+
+// served reports whether the path belongs to one of the stubbed API areas.
+func served(requestPath string) bool {
+	for _, prefix := range Prefixes {
+		if strings.HasPrefix(requestPath, prefix) {
+			return true
+		}
+	}
+
+	return false
+}
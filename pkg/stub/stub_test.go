@@ -0,0 +1,59 @@
+package stub_test
+
+import (
+	"context"
+	"github.com/jedi-knights/ecnl/pkg/services"
+	"github.com/jedi-knights/ecnl/pkg/stub"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"net/http"
+	"net/http/httptest"
+)
+
+var _ = Describe("Server", func() {
+	var (
+		server *httptest.Server
+		svc    *services.GlobalService
+	)
+
+	BeforeEach(func() {
+		server = httptest.NewServer(stub.NewServer("../../testdata/tgs"))
+
+		svc = services.NewTGSServiceWithClient(server.Client())
+		svc.BaseUrl = server.URL
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("should serve fixtures to the TGS service", func() {
+		// Act
+		orgs, err := svc.Organizations(context.Background(), true)
+
+		// Assert
+		Expect(err).NotTo(HaveOccurred())
+		Expect(orgs).To(HaveLen(1))
+		Expect(orgs[0].Name).To(Equal("ECNL Girls"))
+	})
+
+	It("should return not found for missing fixtures", func() {
+		// Act
+		res, err := http.Get(server.URL + "/api/Event/get-org-event-by-eventID/404")
+
+		// Assert
+		Expect(err).NotTo(HaveOccurred())
+		Expect(res.StatusCode).To(Equal(http.StatusNotFound))
+		Expect(res.Body.Close()).To(Succeed())
+	})
+
+	It("should not serve files outside of the API areas", func() {
+		// Act
+		res, err := http.Get(server.URL + "/api/../../go.mod")
+
+		// Assert
+		Expect(err).NotTo(HaveOccurred())
+		Expect(res.StatusCode).To(Equal(http.StatusNotFound))
+		Expect(res.Body.Close()).To(Succeed())
+	})
+})